@@ -0,0 +1,217 @@
+// Copyright 2021, Honeycomb, Hound Technology, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command hcagent receives spans from the processes on a host and exports them
+// to Honeycomb through one shared, buffering exporter. It's a lightweight
+// alternative to running an OpenTelemetry Collector on each host, especially
+// for many short-lived processes that can't wait for their own exports to
+// finish.
+//
+// Usage:
+//
+//	hcagent -apikey=<key> [-dataset=<name>] [-listen=localhost:4318|unix:<path>]
+//
+// Processes send spans with HTTP POST requests to the path /v1/traces, as they
+// would to an OTLP/HTTP receiver, with bodies holding either a single OTLP/JSON
+// export request (Content-Type application/json) or one such request per line
+// (Content-Type application/x-ndjson), optionally compressed with gzip. The
+// agent doesn't accept the OTLP protobuf encoding.
+//
+// GET requests to the path /stats return a JSON object describing the
+// agent's queue of spans awaiting export and the number and total size of
+// the events it has sent to each dataset.
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/honeycombio/opentelemetry-exporter-go/honeycomb"
+	"github.com/honeycombio/opentelemetry-exporter-go/internal/otlpjson"
+
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// maxRequestSize is the largest request body the agent accepts, after
+// decompression.
+const maxRequestSize = 32 << 20
+
+// tracesHandler accepts spans posted by local processes and passes them to
+// an exporter.
+type tracesHandler struct {
+	exporter exporttrace.SpanExporter
+}
+
+func (h *tracesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body io.Reader = r.Body
+	switch strings.ToLower(r.Header.Get("Content-Encoding")) {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid gzip body: %v", err), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	default:
+		http.Error(w, "unsupported content encoding", http.StatusUnsupportedMediaType)
+		return
+	}
+	body = http.MaxBytesReader(w, ioutil.NopCloser(body), maxRequestSize)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var read func(io.Reader) ([]*exporttrace.SpanSnapshot, error)
+	switch mediaType {
+	case "application/json", "":
+		read = otlpjson.ReadJSON
+	case "application/x-ndjson", "application/jsonl":
+		read = otlpjson.ReadJSONLines
+	default:
+		http.Error(w, "unsupported content type; send OTLP/JSON", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	snapshots, err := read(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.exporter.ExportSpans(r.Context(), snapshots); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, "{}")
+}
+
+// agentStats is the body of responses to requests for the agent's stats.
+type agentStats struct {
+	Queue    honeycomb.ExportQueueStats         `json:"queue"`
+	Datasets map[string]honeycomb.DatasetVolume `json:"datasets"`
+}
+
+// statsHandler reports the state of an exporter.
+type statsHandler struct {
+	exporter *honeycomb.Exporter
+}
+
+func (h *statsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(agentStats{
+		Queue:    h.exporter.QueueStats(),
+		Datasets: h.exporter.DatasetVolumes(),
+	})
+}
+
+// listen opens the listener for an address, which names either a TCP address
+// or, with the prefix "unix:", the path of a Unix socket.
+func listen(address string) (net.Listener, error) {
+	if path := strings.TrimPrefix(address, "unix:"); path != address {
+		// Remove a socket left behind by a previous run.
+		if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			os.Remove(path)
+		}
+		return net.Listen("unix", path)
+	}
+	return net.Listen("tcp", address)
+}
+
+func main() {
+	apikey := flag.String("apikey", os.Getenv("HONEYCOMB_API_KEY"), "Your Honeycomb API Key")
+	dataset := flag.String("dataset", "opentelemetry", "Your Honeycomb dataset")
+	apiURL := flag.String("api-url", "", "Honeycomb API URL (default https://api.honeycomb.io/)")
+	address := flag.String("listen", "localhost:4318", `Address on which to receive spans: a TCP address, or "unix:" followed by a socket path`)
+	queueSize := flag.Int("queue-size", 10000, "Number of spans to buffer awaiting export")
+	workers := flag.Int("workers", 2, "Number of goroutines converting spans into events")
+	debug := flag.Bool("debug", false, "Emit verbose exporter logging")
+	flag.Parse()
+
+	opts := []honeycomb.ExporterOption{
+		honeycomb.TargetingDataset(*dataset),
+		honeycomb.WithAsyncExport(*queueSize, *workers),
+		honeycomb.WithDebug(*debug),
+		honeycomb.WithVolumeAccounting(),
+	}
+	if len(*apiURL) > 0 {
+		opts = append(opts, honeycomb.WithAPIURL(*apiURL))
+	}
+	exporter, err := honeycomb.NewExporter(
+		honeycomb.Config{
+			APIKey: *apikey,
+		},
+		opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := exporter.Start(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	l, err := listen(*address)
+	if err != nil {
+		log.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/v1/traces", &tracesHandler{exporter: exporter})
+	mux.Handle("/stats", &statsHandler{exporter: exporter})
+	server := &http.Server{Handler: mux}
+
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancelShutdown()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Failed to stop receiving spans: %v", err)
+		}
+	}()
+
+	log.Printf("Receiving spans on %s", l.Addr())
+	if err := server.Serve(l); err != http.ErrServerClosed {
+		log.Print(err)
+	}
+	if err := exporter.Shutdown(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
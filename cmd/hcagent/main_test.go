@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/honeycombio/opentelemetry-exporter-go/honeycomb"
+
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+type recordingExporter struct {
+	spans []*exporttrace.SpanSnapshot
+}
+
+func (e *recordingExporter) ExportSpans(_ context.Context, sds []*exporttrace.SpanSnapshot) error {
+	e.spans = append(e.spans, sds...)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+const otlpRequest = `{"resourceSpans": [{"scopeSpans": [{"spans": [{
+  "traceId": "0102030405060708090a0b0c0d0e0f10",
+  "spanId": "0102030405060708",
+  "name": "job",
+  "startTimeUnixNano": "1600000000000000000",
+  "endTimeUnixNano": "1600000000500000000"
+}]}]}]}`
+
+// otlpLine is otlpRequest on a single line, as in a JSON lines stream.
+var otlpLine = strings.ReplaceAll(otlpRequest, "\n", "")
+
+func TestTracesHandler(t *testing.T) {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write([]byte(otlpRequest))
+	gz.Close()
+
+	tests := []struct {
+		description string
+		method      string
+		contentType string
+		encoding    string
+		body        string
+		wantStatus  int
+		wantSpans   int
+	}{
+		{"json", http.MethodPost, "application/json", "", otlpRequest, http.StatusOK, 1},
+		{"json lines", http.MethodPost, "application/x-ndjson", "", otlpLine + "\n" + otlpLine + "\n", http.StatusOK, 2},
+		{"gzip", http.MethodPost, "application/json", "gzip", gzipped.String(), http.StatusOK, 1},
+		{"protobuf", http.MethodPost, "application/x-protobuf", "", "", http.StatusUnsupportedMediaType, 0},
+		{"malformed", http.MethodPost, "application/json", "", "{", http.StatusBadRequest, 0},
+		{"get", http.MethodGet, "", "", "", http.StatusMethodNotAllowed, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			exporter := &recordingExporter{}
+			req := httptest.NewRequest(test.method, "/v1/traces", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", test.contentType)
+			if len(test.encoding) != 0 {
+				req.Header.Set("Content-Encoding", test.encoding)
+			}
+			rec := httptest.NewRecorder()
+			(&tracesHandler{exporter: exporter}).ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantStatus, rec.Code)
+			assert.Len(t, exporter.spans, test.wantSpans)
+		})
+	}
+}
+
+func TestStatsHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter, err := honeycomb.NewExporter(honeycomb.Config{APIKey: "test"}, honeycomb.WithVolumeAccounting())
+	assert.Nil(err)
+	defer exporter.Shutdown(context.Background())
+
+	rec := httptest.NewRecorder()
+	(&statsHandler{exporter: exporter}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	assert.Equal(http.StatusOK, rec.Code)
+	var stats agentStats
+	assert.Nil(json.NewDecoder(rec.Body).Decode(&stats))
+	assert.Empty(stats.Datasets)
+
+	rec = httptest.NewRecorder()
+	(&statsHandler{exporter: exporter}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/stats", nil))
+	assert.Equal(http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,77 @@
+// Copyright 2021, Honeycomb, Hound Technology, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command hcdoctor checks whether the exporter can deliver events to Honeycomb
+// with a given API key, API URL, and dataset, printing what it finds and how
+// to fix any problems. The API key and dataset default to the values of the
+// HONEYCOMB_API_KEY and HONEYCOMB_DATASET environment variables.
+//
+// It exits with status 1 if any check fails.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/honeycombio/opentelemetry-exporter-go/honeycomb"
+)
+
+func main() {
+	apikey := flag.String("apikey", os.Getenv("HONEYCOMB_API_KEY"), "Your Honeycomb API Key")
+	defaultDataset := os.Getenv("HONEYCOMB_DATASET")
+	if len(defaultDataset) == 0 {
+		defaultDataset = "opentelemetry"
+	}
+	dataset := flag.String("dataset", defaultDataset, "Your Honeycomb dataset")
+	apiURL := flag.String("api-url", "", "Honeycomb API URL (default https://api.honeycomb.io/)")
+	timeout := flag.Duration("timeout", 10*time.Second, "Time limit for all checks")
+	flag.Parse()
+
+	opts := []honeycomb.ExporterOption{
+		honeycomb.TargetingDataset(*dataset),
+	}
+	if len(*apiURL) > 0 {
+		opts = append(opts, honeycomb.WithAPIURL(*apiURL))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	d, err := honeycomb.Diagnose(ctx, honeycomb.Config{APIKey: *apikey}, opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(2)
+	}
+
+	target := d.Dataset
+	if len(target) == 0 {
+		target = "(named after each service)"
+	}
+	fmt.Printf("API URL: %s\nDataset: %s\n\n", d.APIURL, target)
+	for _, c := range d.Checks {
+		if c.Err == nil {
+			fmt.Printf("[ OK ] %s: %s\n", c.Name, c.Detail)
+			continue
+		}
+		fmt.Printf("[FAIL] %s: %v\n", c.Name, c.Err)
+		if len(c.Hint) > 0 {
+			fmt.Printf("       %s\n", c.Hint)
+		}
+	}
+	if !d.OK() {
+		os.Exit(1)
+	}
+}
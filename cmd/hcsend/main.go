@@ -0,0 +1,184 @@
+// Copyright 2021, Honeycomb, Hound Technology, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command hcsend reads spans from files and exports them to Honeycomb, which
+// is handy for backfills, demonstrations, and reproducing support cases.
+//
+// Usage:
+//
+//	hcsend -apikey=<key> [-dataset=<name>] [-format=auto|otlp|jsonl|<translator>] <file>...
+//
+// Files may contain a single OTLP/JSON export request ("otlp") or one such
+// request per line ("jsonl"), as written by the OpenTelemetry Collector's file
+// exporter. Either way they hold OTLP spans, not Honeycomb events, so files of
+// events, such as those exported from Honeycomb, can't be sent this way.
+// Alternatively, the format may name a translator registered with
+// honeycomb.RegisterTranslator, such as "opencensus" for OpenCensus spans in
+// the protobuf JSON encoding, which is passed one span per line. A
+// file name of "-" reads from standard input. The API key and dataset default
+// to the values of the HONEYCOMB_API_KEY and HONEYCOMB_DATASET environment
+// variables.
+//
+// It exits with status 1 if any batch of spans fails to export.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/honeycombio/opentelemetry-exporter-go/honeycomb"
+	"github.com/honeycombio/opentelemetry-exporter-go/internal/otlpjson"
+
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+const batchSize = 512
+
+// readTranslatedLines converts each line read from r with a registered
+// translator.
+func readTranslatedLines(r io.Reader, t honeycomb.Translator) ([]*exporttrace.SpanSnapshot, error) {
+	var snapshots []*exporttrace.SpanSnapshot
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		snapshot, err := t.Convert(append([]byte(nil), line...))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+func readSpans(name, format string) ([]*exporttrace.SpanSnapshot, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	if format == "auto" {
+		switch filepath.Ext(name) {
+		case ".jsonl", ".ndjson":
+			format = "jsonl"
+		default:
+			format = "otlp"
+		}
+	}
+	switch format {
+	case "otlp":
+		return otlpjson.ReadJSON(r)
+	case "jsonl":
+		return otlpjson.ReadJSONLines(r)
+	default:
+		t, ok := honeycomb.LookupTranslator(format)
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q", format)
+		}
+		return readTranslatedLines(r, t)
+	}
+}
+
+func main() {
+	apikey := flag.String("apikey", os.Getenv("HONEYCOMB_API_KEY"), "Your Honeycomb API Key")
+	defaultDataset := os.Getenv("HONEYCOMB_DATASET")
+	if len(defaultDataset) == 0 {
+		defaultDataset = "opentelemetry"
+	}
+	dataset := flag.String("dataset", defaultDataset, "Your Honeycomb dataset")
+	serviceName := flag.String("service-name", "", "Service name to attach to every event")
+	apiURL := flag.String("api-url", "", "Honeycomb API URL (default https://api.honeycomb.io/)")
+	format := flag.String("format", "auto", `Input format: "otlp" for an OTLP/JSON export request, "jsonl" for one such request per line, "auto" to choose by file extension, or the name of a registered translator, such as "opencensus", for one span per line`)
+	debug := flag.Bool("debug", false, "Emit verbose exporter logging")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <file>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts := []honeycomb.ExporterOption{
+		honeycomb.TargetingDataset(*dataset),
+		honeycomb.WithServiceName(*serviceName),
+		honeycomb.WithDebug(*debug),
+	}
+	if len(*apiURL) > 0 {
+		opts = append(opts, honeycomb.WithAPIURL(*apiURL))
+	}
+	exporter, err := honeycomb.NewExporter(
+		honeycomb.Config{
+			APIKey: *apikey,
+		},
+		opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := exporter.Start(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	sent, failed := 0, 0
+	for _, name := range flag.Args() {
+		snapshots, err := readSpans(name, *format)
+		if err != nil {
+			exporter.Shutdown(ctx)
+			log.Fatalf("Failed to read spans from %s: %v", name, err)
+		}
+		for len(snapshots) > 0 {
+			n := batchSize
+			if n > len(snapshots) {
+				n = len(snapshots)
+			}
+			if err := exporter.ExportSpans(ctx, snapshots[:n]); err != nil {
+				log.Printf("Failed to export spans from %s: %v", name, err)
+				failed += n
+			} else {
+				sent += n
+			}
+			snapshots = snapshots[n:]
+		}
+	}
+
+	if err := exporter.Shutdown(ctx); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Sent %d spans.", sent)
+	if failed > 0 {
+		log.Printf("Failed to send %d spans.", failed)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build windows
+// +build windows
+
+package main
+
+import "time"
+
+// cpuTime returns zero, since measuring CPU time isn't supported here.
+func cpuTime() time.Duration {
+	return 0
+}
@@ -0,0 +1,220 @@
+// Copyright 2021, Honeycomb, Hound Technology, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command loadgen measures the throughput and cost of the exporter by
+// exporting synthetic spans to a stub sender that counts and discards the
+// resulting events, without contacting Honeycomb.
+//
+// Usage:
+//
+//	loadgen [-rate=<spans/s>] [-duration=10s] [-generators=4] [-batch=512]
+//	        [-attributes=10] [-events=1] [-links=0] [-async-queue=0]
+//
+// With a rate of 0, the default, the generators export spans as fast as the
+// exporter accepts them, so the reported throughput is the most the exporter
+// can sustain on this machine. Otherwise, they aim for the given rate, and
+// the report shows whether the exporter kept up. The report includes the
+// spans and events exported per second, the memory allocated per span, and
+// the CPU time used per span.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+
+	"github.com/honeycombio/opentelemetry-exporter-go/honeycomb"
+
+	"go.opentelemetry.io/otel/label"
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/semconv"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// countingSender is a transmission.Sender that counts and discards events.
+type countingSender struct {
+	events    uint64
+	responses chan transmission.Response
+}
+
+func (s *countingSender) Add(*transmission.Event)                 { atomic.AddUint64(&s.events, 1) }
+func (s *countingSender) Start() error                            { return nil }
+func (s *countingSender) Stop() error                             { return nil }
+func (s *countingSender) TxResponses() chan transmission.Response { return s.responses }
+func (s *countingSender) SendResponse(transmission.Response) bool { return false }
+func (s *countingSender) count() uint64                           { return atomic.LoadUint64(&s.events) }
+
+// spanShape describes the synthetic spans to generate.
+type spanShape struct {
+	attributes int
+	events     int
+	links      int
+}
+
+// generator produces synthetic spans of one shape.
+type generator struct {
+	shape    spanShape
+	resource *resource.Resource
+	next     uint64
+}
+
+func (g *generator) span() *exporttrace.SpanSnapshot {
+	n := atomic.AddUint64(&g.next, 1)
+	var traceID apitrace.TraceID
+	var spanID apitrace.SpanID
+	for i := uint(0); i < 8; i++ {
+		traceID[i] = byte(n >> (8 * i))
+		spanID[i] = byte(n >> (8 * i))
+	}
+	now := time.Now()
+	s := &exporttrace.SpanSnapshot{
+		SpanContext: apitrace.SpanContext{TraceID: traceID, SpanID: spanID},
+		SpanKind:    apitrace.SpanKindServer,
+		Name:        "/checkout",
+		StartTime:   now.Add(-time.Millisecond),
+		EndTime:     now,
+		Resource:    g.resource,
+	}
+	for i := 0; i < g.shape.attributes; i++ {
+		s.Attributes = append(s.Attributes, label.String("attr."+strconv.Itoa(i), "value "+strconv.Itoa(i)))
+	}
+	for i := 0; i < g.shape.events; i++ {
+		s.MessageEvents = append(s.MessageEvents, exporttrace.Event{
+			Name:       "event",
+			Time:       now,
+			Attributes: []label.KeyValue{label.Int("index", i)},
+		})
+	}
+	for i := 0; i < g.shape.links; i++ {
+		s.Links = append(s.Links, apitrace.Link{SpanContext: apitrace.SpanContext{TraceID: traceID, SpanID: spanID}})
+	}
+	return s
+}
+
+// run exports batches of spans from the given number of goroutines until
+// the context is done, pacing them to the given total rate of spans per
+// second, if positive, and returns the number of spans exported.
+func run(ctx context.Context, exporter exporttrace.SpanExporter, g *generator, generators, batchSize int, rate float64) uint64 {
+	var exported uint64
+	var wg sync.WaitGroup
+	for i := 0; i < generators; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var ticker *time.Ticker
+			if rate > 0 {
+				interval := time.Duration(float64(time.Second) * float64(batchSize*generators) / rate)
+				ticker = time.NewTicker(interval)
+				defer ticker.Stop()
+			}
+			batch := make([]*exporttrace.SpanSnapshot, batchSize)
+			for {
+				if ticker != nil {
+					select {
+					case <-ticker.C:
+					case <-ctx.Done():
+						return
+					}
+				} else if ctx.Err() != nil {
+					return
+				}
+				for j := range batch {
+					batch[j] = g.span()
+				}
+				if err := exporter.ExportSpans(ctx, batch); err == nil {
+					atomic.AddUint64(&exported, uint64(batchSize))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return exported
+}
+
+func main() {
+	rate := flag.Float64("rate", 0, "Spans per second to export, or 0 for as many as possible")
+	duration := flag.Duration("duration", 10*time.Second, "How long to generate spans")
+	generators := flag.Int("generators", runtime.GOMAXPROCS(0), "Number of goroutines exporting spans")
+	batchSize := flag.Int("batch", 512, "Number of spans per call to ExportSpans")
+	attributes := flag.Int("attributes", 10, "Number of attributes per span")
+	events := flag.Int("events", 1, "Number of span events per span")
+	links := flag.Int("links", 0, "Number of links per span")
+	asyncQueue := flag.Int("async-queue", 0, "Size of the queue for asynchronous export, or 0 to export synchronously")
+	flag.Parse()
+	if *generators <= 0 || *batchSize <= 0 {
+		log.Fatal("generators and batch must be positive")
+	}
+
+	sender := &countingSender{responses: make(chan transmission.Response)}
+	opts := []honeycomb.ExporterOption{honeycomb.WithSender(sender)}
+	if *asyncQueue > 0 {
+		opts = append(opts, honeycomb.WithAsyncExport(*asyncQueue, runtime.GOMAXPROCS(0)))
+	}
+	exporter, err := honeycomb.NewExporter(honeycomb.Config{APIKey: "loadgen"}, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g := &generator{
+		shape:    spanShape{attributes: *attributes, events: *events, links: *links},
+		resource: resource.NewWithAttributes(semconv.ServiceNameKey.String("loadgen")),
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	cpuBefore := cpuTime()
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	exported := run(ctx, exporter, g, *generators, *batchSize, *rate)
+	cancel()
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+
+	elapsed := time.Since(start)
+	cpu := cpuTime() - cpuBefore
+	runtime.ReadMemStats(&after)
+	if exported == 0 {
+		log.Fatal("no spans exported")
+	}
+	perSpan := func(n uint64) float64 { return float64(n) / float64(exported) }
+
+	fmt.Printf("spans exported:   %d in %v\n", exported, elapsed.Round(time.Millisecond))
+	fmt.Printf("spans per second: %.0f", float64(exported)/elapsed.Seconds())
+	if *rate > 0 {
+		fmt.Printf(" (target %.0f)", *rate)
+	}
+	fmt.Println()
+	fmt.Printf("events per second: %.0f\n", float64(sender.count())/elapsed.Seconds())
+	if stats := exporter.QueueStats(); stats.Dropped > 0 {
+		fmt.Printf("spans dropped:    %d (queue full)\n", stats.Dropped)
+	}
+	fmt.Printf("allocations/span: %.1f (%.0f bytes)\n",
+		perSpan(after.Mallocs-before.Mallocs), perSpan(after.TotalAlloc-before.TotalAlloc))
+	if cpu > 0 {
+		fmt.Printf("CPU time/span:    %v (%.1f cores)\n",
+			time.Duration(float64(cpu)/float64(exported)), cpu.Seconds()/elapsed.Seconds())
+	}
+	fmt.Printf("GC cycles:        %d\n", after.NumGC-before.NumGC)
+}
@@ -16,49 +16,31 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/label"
-	"go.opentelemetry.io/otel/propagation"
-	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/semconv"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/honeycombio/opentelemetry-exporter-go/honeycomb"
 )
 
-func initTracer(exporter *honeycomb.Exporter) func(context.Context) error {
-	bsp := sdktrace.NewBatchSpanProcessor(exporter)
-	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(bsp))
-	tp.ApplyConfig(
-		// The default takes parent sampler hints into account, which we don't need here.
-		sdktrace.Config{
-			// For the demonstration, use sdktrace.AlwaysSample sampler to sample all traces.
-			// In a production application, use sdktrace.ProbabilitySampler with a desired
-			// probability.
-			DefaultSampler: sdktrace.AlwaysSample(),
-		})
-	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{}))
-	return bsp.Shutdown
-}
-
 func main() {
 	apikey := flag.String("apikey", "", "Your Honeycomb API Key")
 	dataset := flag.String("dataset", "opentelemetry", "Your Honeycomb dataset")
 	flag.Parse()
 
-	exporter, err := honeycomb.NewExporter(
-		honeycomb.Config{
+	_, shutdown, err := honeycomb.NewTracerProvider(context.Background(), honeycomb.TracerProviderConfig{
+		Config: honeycomb.Config{
 			APIKey: *apikey,
 		},
-		honeycomb.TargetingDataset(*dataset),
-		honeycomb.WithServiceName("opentelemetry-client"),
-		honeycomb.WithDebugEnabled())
+		ExporterOptions: []honeycomb.ExporterOption{
+			honeycomb.TargetingDataset(*dataset),
+			honeycomb.WithServiceName("opentelemetry-client"),
+			honeycomb.WithDebugEnabled(),
+		},
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer exporter.Shutdown(context.Background())
-	defer initTracer(exporter)(context.Background())
+	defer shutdown(context.Background())
 	tr := otel.Tracer("honeycomb/example/client")
 
 	url := flag.String("server", "http://localhost:7777/hello", "server URL")
@@ -0,0 +1,147 @@
+// Copyright 2021, Honeycomb, Hound Technology, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command ocbridge demonstrates exporting OpenCensus proto spans to Honeycomb
+// by way of honeycomb.OCProtoSpanToOTelSpanSnapshot.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"flag"
+	"log"
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
+
+	"github.com/honeycombio/opentelemetry-exporter-go/honeycomb"
+
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func randomID(n int) []byte {
+	id := make([]byte, n)
+	if _, err := rand.Read(id); err != nil {
+		log.Fatalf("failed to generate ID: %v", err)
+	}
+	return id
+}
+
+func truncatable(s string) *tracepb.TruncatableString {
+	return &tracepb.TruncatableString{Value: s}
+}
+
+func protoTimestamp(t time.Time) *timestamp.Timestamp {
+	ts, err := ptypes.TimestampProto(t)
+	if err != nil {
+		log.Fatalf("failed to convert time to timestamp: %v", err)
+	}
+	return ts
+}
+
+func stringAttribute(s string) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{StringValue: truncatable(s)},
+	}
+}
+
+// makeOCSpans fabricates a small trace as an OpenCensus agent would deliver
+// it: a server span with one client child span.
+func makeOCSpans() []*tracepb.Span {
+	traceID := randomID(16)
+	rootID := randomID(8)
+	childID := randomID(8)
+
+	start := time.Now().Add(-50 * time.Millisecond)
+	childStart := start.Add(10 * time.Millisecond)
+	childEnd := childStart.Add(25 * time.Millisecond)
+	end := start.Add(50 * time.Millisecond)
+
+	annotation := &tracepb.Span_TimeEvent{
+		Time: protoTimestamp(childStart.Add(5 * time.Millisecond)),
+		Value: &tracepb.Span_TimeEvent_Annotation_{
+			Annotation: &tracepb.Span_TimeEvent_Annotation{
+				Description: truncatable("cache miss"),
+			},
+		},
+	}
+
+	return []*tracepb.Span{
+		{
+			TraceId:   traceID,
+			SpanId:    rootID,
+			Name:      truncatable("/checkout"),
+			Kind:      tracepb.Span_SERVER,
+			StartTime: protoTimestamp(start),
+			EndTime:   protoTimestamp(end),
+			Attributes: &tracepb.Span_Attributes{
+				AttributeMap: map[string]*tracepb.AttributeValue{
+					"http.method": stringAttribute("POST"),
+				},
+			},
+			SameProcessAsParentSpan: &wrappers.BoolValue{Value: false},
+			ChildSpanCount:          &wrappers.UInt32Value{Value: 1},
+		},
+		{
+			TraceId:      traceID,
+			SpanId:       childID,
+			ParentSpanId: rootID,
+			Name:         truncatable("inventory.Reserve"),
+			Kind:         tracepb.Span_CLIENT,
+			StartTime:    protoTimestamp(childStart),
+			EndTime:      protoTimestamp(childEnd),
+			TimeEvents: &tracepb.Span_TimeEvents{
+				TimeEvent: []*tracepb.Span_TimeEvent{annotation},
+			},
+			SameProcessAsParentSpan: &wrappers.BoolValue{Value: true},
+		},
+	}
+}
+
+func main() {
+	apikey := flag.String("apikey", "", "Your Honeycomb API Key")
+	dataset := flag.String("dataset", "opentelemetry", "Your Honeycomb dataset")
+	flag.Parse()
+
+	exporter, err := honeycomb.NewExporter(
+		honeycomb.Config{
+			APIKey: *apikey,
+		},
+		honeycomb.TargetingDataset(*dataset),
+		honeycomb.WithServiceName("opentelemetry-ocbridge"),
+		honeycomb.WithDebugEnabled())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer exporter.Shutdown(context.Background())
+
+	ocSpans := makeOCSpans()
+	snapshots := make([]*exporttrace.SpanSnapshot, 0, len(ocSpans))
+	for _, ocSpan := range ocSpans {
+		snapshot, err := honeycomb.OCProtoSpanToOTelSpanSnapshot(ocSpan)
+		if err != nil {
+			log.Printf("Skipping OpenCensus span: %v", err)
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := exporter.ExportSpans(context.Background(), snapshots); err != nil {
+		log.Fatalf("Failed to export spans: %v", err)
+	}
+	log.Printf("Exported %d OpenCensus spans.", len(snapshots))
+}
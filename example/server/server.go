@@ -28,29 +28,8 @@ import (
 	"github.com/honeycombio/opentelemetry-exporter-go/honeycomb"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/propagation"
-	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
-func initTracer(exporter *honeycomb.Exporter) func(context.Context) error {
-	bsp := sdktrace.NewBatchSpanProcessor(exporter)
-	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(bsp))
-	tp.ApplyConfig(
-		// The default takes parent sampler hints into account, which we don't need here.
-		sdktrace.Config{
-			// For the demonstration, use sdktrace.AlwaysSample sampler to sample all traces.
-			// In a production application, use sdktrace.ProbabilitySampler with a desired
-			// probability.
-			DefaultSampler: sdktrace.AlwaysSample(),
-		})
-	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{}))
-	return bsp.Shutdown
-}
-
 func joinIPAddressAndPort(address net.IP, port string) string {
 	var host string
 	var empty net.IP
@@ -104,18 +83,20 @@ func main() {
 
 	ctx := context.Background()
 
-	exporter, err := honeycomb.NewExporter(
-		honeycomb.Config{
+	_, shutdown, err := honeycomb.NewTracerProvider(ctx, honeycomb.TracerProviderConfig{
+		Config: honeycomb.Config{
 			APIKey: *apikey,
 		},
-		honeycomb.TargetingDataset(*dataset),
-		honeycomb.WithServiceName("opentelemetry-server"),
-		honeycomb.WithDebugEnabled())
+		ExporterOptions: []honeycomb.ExporterOption{
+			honeycomb.TargetingDataset(*dataset),
+			honeycomb.WithServiceName("opentelemetry-server"),
+			honeycomb.WithDebugEnabled(),
+		},
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer exporter.Shutdown(context.Background())
-	defer initTracer(exporter)(context.Background())
+	defer shutdown(context.Background())
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
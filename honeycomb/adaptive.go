@@ -0,0 +1,85 @@
+package honeycomb
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// WithThroughputTarget causes the exporter to sample traces at a rate it
+// adjusts continuously, aiming to send about eventsPerSecond events per
+// second. At the end of each window, it sets the rate to apply during the
+// next one from the number of events the spans it saw during the last one
+// would have produced: when traffic spikes, it keeps fewer traces, and when
+// traffic is light, it keeps them all. The sample rate of each event it
+// sends reflects the rate applied to its span. Whether it keeps a span
+// depends only on its trace ID and the current rate, independently of any
+// sampler's decision, so traces are kept or dropped whole unless the rate
+// changes while they're in progress.
+func WithThroughputTarget(eventsPerSecond float64, window time.Duration) ExporterOption {
+	return func(c *exporterConfig) error {
+		if !(eventsPerSecond > 0) {
+			return errors.New("throughput target must be positive")
+		}
+		if window <= 0 {
+			return errors.New("throughput target window must be positive")
+		}
+		c.throughputTarget = eventsPerSecond
+		c.throughputWindow = window
+		return nil
+	}
+}
+
+// adaptiveSampler chooses sample rates that keep the rate of events sent
+// near a target.
+type adaptiveSampler struct {
+	target float64 // events per second
+	window time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	start time.Time
+	seen  uint64
+	rate  uint
+}
+
+func newAdaptiveSampler(eventsPerSecond float64, window time.Duration) *adaptiveSampler {
+	return &adaptiveSampler{
+		target: eventsPerSecond,
+		window: window,
+		now:    time.Now,
+		rate:   1,
+	}
+}
+
+// admit counts the events for a span of the trace with the given ID,
+// reporting whether to send them, along with the rate at which they were
+// sampled.
+func (s *adaptiveSampler) admit(traceID apitrace.TraceID, events int) (uint, bool) {
+	s.mu.Lock()
+	if now := s.now(); now.Sub(s.start) >= s.window {
+		if !s.start.IsZero() {
+			// The window ends with the first span seen after it should
+			// have, so measure the events seen over its actual length.
+			s.rate = adaptedRate(s.seen, s.target*now.Sub(s.start).Seconds())
+		}
+		s.start = now
+		s.seen = 0
+	}
+	s.seen += uint64(events)
+	rate := s.rate
+	s.mu.Unlock()
+	return rate, exporterSampled(traceID, "adaptive", rate)
+}
+
+// adaptedRate returns the sample rate bringing the given number of events
+// down to the target.
+func adaptedRate(seen uint64, target float64) uint {
+	if float64(seen) <= target {
+		return 1
+	}
+	return uint(math.Ceil(float64(seen) / target))
+}
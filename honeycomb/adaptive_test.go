@@ -0,0 +1,67 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestAdaptedRate(t *testing.T) {
+	assert.Equal(t, uint(1), adaptedRate(0, 100))
+	assert.Equal(t, uint(1), adaptedRate(100, 100))
+	assert.Equal(t, uint(2), adaptedRate(101, 100))
+	assert.Equal(t, uint(10), adaptedRate(1000, 100))
+}
+
+func TestAdaptiveSamplerAdjustsRate(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newAdaptiveSampler(10, time.Second)
+	now := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	low, high := exporterSampledIDs("adaptive", 4)
+
+	// Quiet traffic is kept in full.
+	rate, ok := s.admit(high, 5)
+	assert.Equal(uint(1), rate)
+	assert.True(ok)
+
+	// A spike raises the rate for the next window.
+	s.admit(high, 35)
+	now = now.Add(time.Second)
+	rate, ok = s.admit(low, 1)
+	assert.Equal(uint(4), rate)
+	assert.True(ok)
+	_, ok = s.admit(high, 1)
+	assert.False(ok)
+
+	// After a quiet window, the rate falls back.
+	now = now.Add(time.Second)
+	rate, _ = s.admit(high, 1)
+	assert.Equal(uint(1), rate)
+}
+
+func TestHoneycombThroughputTarget(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb, WithThroughputTarget(1, time.Millisecond))
+	assert.Nil(err)
+
+	sds := []*trace.SpanSnapshot{{Name: "a", SpanContext: apitrace.SpanContext{TraceID: apitrace.TraceID{0x01}}}}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.Len(mockHoneycomb.Events(), 1)
+
+	_, err = makeTestExporter(&transmission.MockSender{}, WithThroughputTarget(0, time.Second))
+	assert.Error(err)
+	_, err = makeTestExporter(&transmission.MockSender{}, WithThroughputTarget(1, 0))
+	assert.Error(err)
+}
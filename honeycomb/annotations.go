@@ -0,0 +1,48 @@
+package honeycomb
+
+import (
+	"encoding/binary"
+
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// annotationSampling holds the rates at which the exporter sends the events
+// for span events and links.
+type annotationSampling struct {
+	spanEvents uint
+	links      uint
+}
+
+// WithAnnotationSampling causes the exporter to send the events for span
+// events in only one in spanEventRate traces and those for links in only one
+// in linkRate traces, multiplying their sample rates accordingly. A rate of 1
+// keeps them all, and a rate of 0 suppresses them entirely. This reduces the
+// volume of traces whose spans record many span events or links, such as
+// those for each row a query returns.
+//
+// Whether the exporter keeps a span's span events or links depends only on
+// its trace ID, so a trace has either all its span events or none of them,
+// rather than missing some at random. The decision is independent of that
+// made by RuleSampler and TraceIDRatioBased samplers, which use a different
+// part of the trace ID.
+func WithAnnotationSampling(spanEventRate, linkRate uint) ExporterOption {
+	return func(c *exporterConfig) error {
+		c.annotationSampling = &annotationSampling{
+			spanEvents: spanEventRate,
+			links:      linkRate,
+		}
+		return nil
+	}
+}
+
+// annotationsSampled reports whether to keep the annotations of the trace
+// with the given ID when keeping those of one trace in rate. Samplers choose
+// traces by the first half of their IDs, so basing the decision on the
+// second half keeps it independent of theirs.
+func annotationsSampled(traceID apitrace.TraceID, rate uint) bool {
+	if rate == 0 {
+		return false
+	}
+	bound := uint64(1<<63) / uint64(rate)
+	return binary.BigEndian.Uint64(traceID[8:16])>>1 < bound
+}
@@ -0,0 +1,62 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestAnnotationsSampledIndependently(t *testing.T) {
+	// The first half of this ID would be kept by a sampler at any rate; the
+	// second half decides whether to keep its annotations.
+	id := apitrace.TraceID{8: 0xff}
+	assert.True(t, sampledAtRate(id, 100))
+	assert.False(t, annotationsSampled(id, 2))
+	assert.True(t, annotationsSampled(id, 1))
+	assert.False(t, annotationsSampled(apitrace.TraceID{}, 0))
+}
+
+func TestHoneycombAnnotationSampling(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb, WithAnnotationSampling(2, 0))
+	assert.Nil(err)
+
+	annotated := func(name string, id apitrace.TraceID) *trace.SpanSnapshot {
+		return &trace.SpanSnapshot{
+			Name:          name,
+			SpanContext:   apitrace.SpanContext{TraceID: id},
+			MessageEvents: []trace.Event{{Name: "a"}, {Name: "b"}},
+			Links:         []apitrace.Link{{}},
+		}
+	}
+	sds := []*trace.SpanSnapshot{
+		annotated("kept", apitrace.TraceID{8: 0x01}),
+		annotated("trimmed", apitrace.TraceID{8: 0xff}),
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	var spans, spanEvents, links int
+	for _, ev := range mockHoneycomb.Events() {
+		switch ev.Data[annotationTypeField] {
+		case "span_event":
+			spanEvents++
+			assert.Equal("kept", ev.Data["trace.parent_name"])
+			assert.Equal(uint(2), ev.SampleRate)
+		case "link":
+			links++
+		default:
+			spans++
+		}
+	}
+	assert.Equal(2, spans)
+	assert.Equal(2, spanEvents)
+	assert.Zero(links)
+}
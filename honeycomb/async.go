@@ -0,0 +1,188 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// ExportQueueStats describes the state of the queue used by an exporter
+// configured with WithAsyncExport.
+type ExportQueueStats struct {
+	// Depth is the number of spans waiting in the queue.
+	Depth int
+	// Capacity is the maximum number of spans the queue can hold.
+	Capacity int
+	// Dropped is the number of spans discarded since the exporter was
+	// created because the queue was full.
+	Dropped uint64
+}
+
+// WithAsyncExport causes ExportSpans to return as soon as it has queued the
+// spans for export, leaving the given number of worker goroutines to convert
+// them into events and hand them to libhoney. This bounds the time the SDK's
+// span processor spends in the exporter. If the queue, which holds up to
+// queueSize spans, is full, ExportSpans discards the spans that don't fit.
+//
+// Use the QueueStats method to monitor the queue.
+func WithAsyncExport(queueSize, workers int) ExporterOption {
+	return func(c *exporterConfig) error {
+		if queueSize <= 0 {
+			return errors.New("async export queue size must be positive")
+		}
+		if workers <= 0 {
+			return errors.New("async export worker count must be positive")
+		}
+		c.asyncQueueSize = queueSize
+		c.asyncWorkers = workers
+		return nil
+	}
+}
+
+// exportQueue feeds spans to a pool of workers that export them.
+type exportQueue struct {
+	// dropped and busy come first so that they are 64-bit aligned, as the
+	// atomic operations on them require on 32-bit platforms.
+	dropped uint64
+	// busy is the number of spans queued or being exported.
+	busy int64
+
+	spans  chan *trace.SpanSnapshot
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func newExportQueue(size, workers int, export func(*trace.SpanSnapshot)) *exportQueue {
+	q := &exportQueue{
+		spans: make(chan *trace.SpanSnapshot, size),
+	}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer q.wg.Done()
+			for s := range q.spans {
+				export(s)
+				atomic.AddInt64(&q.busy, -1)
+			}
+		}()
+	}
+	return q
+}
+
+// enqueue queues the spans without blocking, returning the number of spans
+// that didn't fit.
+func (q *exportQueue) enqueue(sds []*trace.SpanSnapshot) int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return len(sds)
+	}
+	for i, s := range sds {
+		atomic.AddInt64(&q.busy, 1)
+		select {
+		case q.spans <- s:
+		default:
+			atomic.AddInt64(&q.busy, -1)
+			dropped := len(sds) - i
+			atomic.AddUint64(&q.dropped, uint64(dropped))
+			return dropped
+		}
+	}
+	return 0
+}
+
+// flush waits until the queue is empty and the workers are idle, or until
+// ctx is done.
+func (q *exportQueue) flush(ctx context.Context) error {
+	ticker := time.NewTicker(queueFlushPollInterval)
+	defer ticker.Stop()
+	for atomic.LoadInt64(&q.busy) > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// close stops accepting spans and waits for the workers to export those
+// already queued.
+func (q *exportQueue) close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.spans)
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+func (q *exportQueue) stats() ExportQueueStats {
+	return ExportQueueStats{
+		Depth:    len(q.spans),
+		Capacity: cap(q.spans),
+		Dropped:  atomic.LoadUint64(&q.dropped),
+	}
+}
+
+// QueueStats reports the state of the queue used when the exporter is
+// configured with WithAsyncExport. It returns the zero value otherwise.
+func (e *Exporter) QueueStats() ExportQueueStats {
+	if e.queue == nil {
+		return ExportQueueStats{}
+	}
+	return e.queue.stats()
+}
+
+// pendingSpans returns the number of spans the exporter holds that it has
+// yet to convert into events, whether waiting in the queue used for
+// asynchronous export or for a tail sampling decision.
+func (e *Exporter) pendingSpans() int {
+	n := 0
+	if e.queue != nil {
+		n += len(e.queue.spans)
+	}
+	if e.tail != nil {
+		n += e.tail.pendingCount()
+	}
+	return n
+}
+
+// WithQueueDepthField adds a dynamic field with the given name, such as
+// "meta.queue_depth," to every event the exporter sends, holding the number
+// of spans the exporter held at the time that it had yet to convert into
+// events, whether waiting in the queue used by WithAsyncExport or for a
+// decision by WithTailSampling. A growing queue depth explains events whose
+// timestamps lag behind their arrival in Honeycomb.
+//
+// The option requires WithAsyncExport or WithTailSampling, without which the
+// exporter holds no spans. It doesn't count the events libhoney holds
+// awaiting transmission, which TransmissionStats reports.
+func WithQueueDepthField(name string) ExporterOption {
+	return func(c *exporterConfig) error {
+		if err := validateField(name); err != nil {
+			return err
+		}
+		c.queueDepthField = name
+		return nil
+	}
+}
+
+// exportQueued exports a span taken from the queue, passing it through the
+// tail sampler if there is one. Any failure has already been reported to the
+// error hook.
+func (e *Exporter) exportQueued(s *trace.SpanSnapshot) {
+	if e.tail != nil {
+		e.tail.add([]*trace.SpanSnapshot{s})
+		return
+	}
+	_ = e.exportSpan(context.Background(), s, nil)
+}
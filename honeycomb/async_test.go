@@ -0,0 +1,94 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestHoneycombAsyncExport(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb, WithAsyncExport(16, 2))
+	assert.Nil(err)
+
+	sds := []*trace.SpanSnapshot{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Equal(16, exporter.QueueStats().Capacity)
+
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.Len(mockHoneycomb.Events(), 3)
+	assert.Zero(exporter.QueueStats().Dropped)
+}
+
+func TestExportQueueDropsWhenFull(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	q := newExportQueue(2, 1, func(*trace.SpanSnapshot) {
+		started <- struct{}{}
+		<-release
+	})
+
+	// Occupy the only worker, then fill the queue.
+	assert.Zero(q.enqueue([]*trace.SpanSnapshot{{}}))
+	<-started
+	assert.Equal(1, q.enqueue([]*trace.SpanSnapshot{{}, {}, {}}))
+
+	stats := q.stats()
+	assert.Equal(2, stats.Depth)
+	assert.Equal(uint64(1), stats.Dropped)
+
+	go func() {
+		for range started {
+		}
+	}()
+	close(release)
+	q.close()
+	assert.Equal(2, q.enqueue([]*trace.SpanSnapshot{{}, {}}))
+}
+
+func TestHoneycombAsyncExportValidation(t *testing.T) {
+	_, err := makeTestExporter(&transmission.MockSender{}, WithAsyncExport(0, 1))
+	assert.Error(t, err)
+	_, err = makeTestExporter(&transmission.MockSender{}, WithAsyncExport(1, 0))
+	assert.Error(t, err)
+}
+
+func TestHoneycombQueueDepthField(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithTailSampling(1, time.Hour),
+		WithQueueDepthField("meta.queue_depth"))
+	assert.Nil(err)
+
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{
+		tailTestSpan(1, 2, 1, "child a", time.Millisecond),
+		tailTestSpan(2, 2, 1, "child b", time.Millisecond),
+	}))
+	assert.Equal(2, exporter.pendingSpans())
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{
+		tailTestSpan(1, 1, 0, "root a", time.Millisecond),
+	}))
+
+	events := mockHoneycomb.Events()
+	assert.Len(events, 2)
+	for _, ev := range events {
+		assert.Equal(1, ev.Data["meta.queue_depth"])
+	}
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	_, err = makeTestExporter(mockHoneycomb, WithQueueDepthField(""))
+	assert.Error(err)
+	_, err = makeTestExporter(mockHoneycomb, WithQueueDepthField("meta.queue_depth"))
+	assert.Error(err)
+}
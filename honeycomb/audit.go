@@ -0,0 +1,83 @@
+package honeycomb
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fieldAuditor accumulates the names of fields sent to Honeycomb and reports
+// them periodically.
+type fieldAuditor struct {
+	interval time.Duration
+	report   func([]string)
+
+	mu    sync.Mutex
+	names map[string]struct{}
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newFieldAuditor(interval time.Duration, report func([]string)) *fieldAuditor {
+	if report == nil {
+		report = func(names []string) {
+			log.Printf("Honeycomb exporter sent fields: %s", strings.Join(names, ", "))
+		}
+	}
+	return &fieldAuditor{
+		interval: interval,
+		report:   report,
+		names:    make(map[string]struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (a *fieldAuditor) record(fields map[string]interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for name := range fields {
+		a.names[name] = struct{}{}
+	}
+}
+
+// flush reports the field names recorded since the last report, if any.
+func (a *fieldAuditor) flush() {
+	a.mu.Lock()
+	if len(a.names) == 0 {
+		a.mu.Unlock()
+		return
+	}
+	names := make([]string, 0, len(a.names))
+	for name := range a.names {
+		names = append(names, name)
+	}
+	a.names = make(map[string]struct{}, len(names))
+	a.mu.Unlock()
+
+	sort.Strings(names)
+	a.report(names)
+}
+
+func (a *fieldAuditor) run() {
+	defer close(a.done)
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.flush()
+		case <-a.stop:
+			a.flush()
+			return
+		}
+	}
+}
+
+func (a *fieldAuditor) close() {
+	close(a.stop)
+	<-a.done
+}
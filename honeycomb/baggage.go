@@ -0,0 +1,117 @@
+package honeycomb
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/label"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// BaggageSpanProcessor is a span processor that copies members of the baggage
+// in effect when a span starts onto that span as attributes, so that values
+// propagated from upstream services become queryable fields in Honeycomb.
+//
+// By default every baggage member is copied. Use WithBaggageKeyPrefixes and
+// WithBaggageKeys to copy only intentionally propagated members, and
+// WithMaxBaggageAttributes to bound how many are copied onto each span.
+type BaggageSpanProcessor struct {
+	prefixes []string
+	keys     map[label.Key]struct{}
+	max      int
+}
+
+var _ sdktrace.SpanProcessor = (*BaggageSpanProcessor)(nil)
+
+// BaggageSpanProcessorOption is an optional change to the configuration used
+// by the NewBaggageSpanProcessor function.
+type BaggageSpanProcessorOption func(*BaggageSpanProcessor)
+
+// WithBaggageKeyPrefixes restricts the processor to copying baggage members
+// whose keys start with one of the given prefixes, such as "app.". It may be
+// combined with WithBaggageKeys, in which case a member matching either is
+// copied.
+func WithBaggageKeyPrefixes(prefixes ...string) BaggageSpanProcessorOption {
+	return func(p *BaggageSpanProcessor) {
+		p.prefixes = append(p.prefixes, prefixes...)
+	}
+}
+
+// WithBaggageKeys restricts the processor to copying baggage members with the
+// given keys. It may be combined with WithBaggageKeyPrefixes, in which case a
+// member matching either is copied.
+func WithBaggageKeys(keys ...string) BaggageSpanProcessorOption {
+	return func(p *BaggageSpanProcessor) {
+		if p.keys == nil {
+			p.keys = make(map[label.Key]struct{}, len(keys))
+		}
+		for _, k := range keys {
+			p.keys[label.Key(k)] = struct{}{}
+		}
+	}
+}
+
+// WithMaxBaggageAttributes limits the number of baggage members copied onto
+// each span. Members are considered in order by key. A limit of zero or less
+// imposes no limit.
+func WithMaxBaggageAttributes(n int) BaggageSpanProcessorOption {
+	return func(p *BaggageSpanProcessor) {
+		p.max = n
+	}
+}
+
+// NewBaggageSpanProcessor returns a span processor that copies baggage
+// members onto spans. Register it with the tracer provider using
+// sdktrace.WithSpanProcessor.
+func NewBaggageSpanProcessor(opts ...BaggageSpanProcessorOption) *BaggageSpanProcessor {
+	p := &BaggageSpanProcessor{}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+func (p *BaggageSpanProcessor) accepts(key label.Key) bool {
+	if len(p.prefixes) == 0 && len(p.keys) == 0 {
+		return true
+	}
+	if _, ok := p.keys[key]; ok {
+		return true
+	}
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(string(key), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// OnStart copies the accepted baggage members onto the starting span.
+func (p *BaggageSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	set := baggage.Set(parent)
+	if set.Len() == 0 {
+		return
+	}
+	var attrs []label.KeyValue
+	for _, kv := range set.ToSlice() {
+		if p.max > 0 && len(attrs) == p.max {
+			break
+		}
+		if p.accepts(kv.Key) {
+			attrs = append(attrs, kv)
+		}
+	}
+	if len(attrs) > 0 {
+		s.SetAttributes(attrs...)
+	}
+}
+
+// OnEnd does nothing.
+func (p *BaggageSpanProcessor) OnEnd(sdktrace.ReadOnlySpan) {}
+
+// Shutdown does nothing.
+func (p *BaggageSpanProcessor) Shutdown(context.Context) error { return nil }
+
+// ForceFlush does nothing.
+func (p *BaggageSpanProcessor) ForceFlush() {}
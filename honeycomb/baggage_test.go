@@ -0,0 +1,78 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/label"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestBaggageSpanProcessor(t *testing.T) {
+	ctx := baggage.ContextWithValues(context.Background(),
+		label.String("app.tenant", "acme"),
+		label.String("app.tier", "gold"),
+		label.String("app.user", "alice"),
+		label.String("request.id", "r-1"),
+		label.String("x-forwarded-for", "10.0.0.1"),
+	)
+	tests := []struct {
+		description string
+		opts        []BaggageSpanProcessorOption
+		want        []string
+		unwanted    []string
+	}{
+		{
+			"unfiltered",
+			nil,
+			[]string{"app.tenant", "app.tier", "app.user", "request.id", "x-forwarded-for"},
+			nil,
+		},
+		{
+			"prefix and key",
+			[]BaggageSpanProcessorOption{
+				WithBaggageKeyPrefixes("app."),
+				WithBaggageKeys("request.id"),
+			},
+			[]string{"app.tenant", "app.tier", "app.user", "request.id"},
+			[]string{"x-forwarded-for"},
+		},
+		{
+			"capped",
+			[]BaggageSpanProcessorOption{
+				WithBaggageKeyPrefixes("app."),
+				WithMaxBaggageAttributes(2),
+			},
+			[]string{"app.tenant", "app.tier"},
+			[]string{"app.user", "request.id", "x-forwarded-for"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			mockHoneycomb := &transmission.MockSender{}
+			assert := assert.New(t)
+
+			exporter, err := makeTestExporter(mockHoneycomb)
+			assert.Nil(err)
+			tr, err := setUpTestProvider(exporter,
+				sdktrace.WithSpanProcessor(NewBaggageSpanProcessor(test.opts...)))
+			assert.Nil(err)
+
+			_, span := tr.Start(ctx, "myTestSpan")
+			span.End()
+
+			assert.Len(mockHoneycomb.Events(), 1)
+			fields := mockHoneycomb.Events()[0].Data
+			for _, key := range test.want {
+				assert.Contains(fields, key)
+			}
+			for _, key := range test.unwanted {
+				assert.NotContains(fields, key)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package honeycomb
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// WithMaxBatchBytes causes the exporter to flush the events it has queued
+// for transmission whenever the serialized size of the events it has queued
+// since the last flush reaches n bytes, in addition to libhoney's usual
+// flushing by event count and time. This keeps batches of very large events,
+// such as those carrying long SQL statements or stack traces, under the
+// limit on the size of a request to Honeycomb's batch API, which rejects
+// larger requests with HTTP status 413.
+//
+// Flushing waits for the queued events to be sent, during which no other
+// events can be queued, so n should be large relative to typical events; a
+// value of a few megabytes suits most workloads.
+func WithMaxBatchBytes(n int) ExporterOption {
+	return func(c *exporterConfig) error {
+		if n <= 0 {
+			return errors.New("maximum batch size in bytes must be positive")
+		}
+		c.maxBatchBytes = n
+		return nil
+	}
+}
+
+// byteFlusher flushes the exporter's events when their accumulated size
+// reaches a threshold.
+type byteFlusher struct {
+	max     int64
+	pending int64
+	flush   func()
+
+	// mu is held for reading while queuing an event and for writing while
+	// flushing, since libhoney can't accept events during a flush.
+	mu sync.RWMutex
+}
+
+// send queues an event of the given serialized size with the given
+// function, flushing afterward if the events queued since the last flush
+// have reached the threshold.
+func (f *byteFlusher) send(size int, send func() error) error {
+	f.mu.RLock()
+	err := send()
+	f.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if atomic.AddInt64(&f.pending, int64(size)) < f.max {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// Another goroutine may have flushed while this one awaited the lock.
+	if atomic.LoadInt64(&f.pending) >= f.max {
+		f.flush()
+		atomic.StoreInt64(&f.pending, 0)
+	}
+	return nil
+}
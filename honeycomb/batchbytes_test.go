@@ -0,0 +1,45 @@
+package honeycomb
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestByteFlusherFlushesAtThreshold(t *testing.T) {
+	assert := assert.New(t)
+
+	flushes := 0
+	f := &byteFlusher{max: 100, flush: func() { flushes++ }}
+	send := func() error { return nil }
+
+	assert.Nil(f.send(10, send))
+	assert.Zero(flushes)
+	assert.Nil(f.send(110, send))
+	assert.Equal(1, flushes)
+	assert.Zero(f.pending)
+	assert.Nil(f.send(10, send))
+	assert.Equal(1, flushes)
+}
+
+func TestHoneycombMaxBatchBytes(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb, WithMaxBatchBytes(64))
+	assert.Nil(err)
+	assert.NotNil(exporter.flusher)
+
+	sds := []*trace.SpanSnapshot{{Name: strings.Repeat("a", 64)}, {Name: "b"}}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.Len(mockHoneycomb.Events(), 2)
+
+	_, err = makeTestExporter(&transmission.MockSender{}, WithMaxBatchBytes(0))
+	assert.Error(err)
+}
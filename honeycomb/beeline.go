@@ -0,0 +1,83 @@
+package honeycomb
+
+import (
+	"os"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// Names of the fields the Beelines add to every span.
+const (
+	spanTypeField      = "meta.span_type"
+	beelineTypeField   = "meta.type"
+	localHostnameField = "meta.local_hostname"
+)
+
+// beelineSpanType returns the value the Beelines would give the
+// "meta.span_type" field of a span: "root" for spans without parents,
+// "subroot" for those whose parents belong to other processes, and "mid" or
+// "leaf" for the others, depending on whether they have children.
+func beelineSpanType(data *trace.SpanSnapshot) string {
+	switch {
+	case !data.ParentSpanID.IsValid():
+		return "root"
+	case data.HasRemoteParent:
+		return "subroot"
+	case data.ChildSpanCount > 0:
+		return "mid"
+	default:
+		return "leaf"
+	}
+}
+
+// beelineFieldAdder returns a transform that adds the fields the Beelines add
+// to the events for spans, reporting the given host name.
+func beelineFieldAdder(hostname string) func(*libhoney.Event, *trace.SpanSnapshot) {
+	return func(ev *libhoney.Event, data *trace.SpanSnapshot) {
+		if isAnnotationEvent(ev) {
+			return
+		}
+		ev.AddField(spanTypeField, beelineSpanType(data))
+		if len(hostname) != 0 {
+			ev.AddField(localHostnameField, hostname)
+		}
+		fields := ev.Fields()
+		if _, ok := fields[beelineTypeField]; ok {
+			return
+		}
+		if _, ok := fields["request.method"]; ok && data.SpanKind == apitrace.SpanKindServer {
+			ev.AddField(beelineTypeField, "http_request")
+		} else if _, ok := fields["db.query"]; ok {
+			ev.AddField(beelineTypeField, "sql")
+		}
+	}
+}
+
+// WithBeelineCompatibility causes the exporter to send spans with the fields
+// the Beelines use, so that a service migrating from a Beeline to
+// OpenTelemetry can keep sending to its existing dataset without breaking
+// the boards, queries, and triggers built on it. It applies
+// WithHTTPFieldNormalization and WithDatabaseFieldNormalization, adding
+// fields such as "request.path," "response.status_code," and "db.query," and
+// adds to the events for spans the "meta.span_type" field, holding "root,"
+// "subroot," "mid," or "leaf," the "meta.local_hostname" field, and, for
+// HTTP server and database spans, the "meta.type" field, holding
+// "http_request" or "sql."
+//
+// The fields the exporter always sends, such as "trace.trace_id,"
+// "trace.parent_id," "service_name," and "duration_ms," already match those
+// of the Beelines. Attributes are sent under their own names, without the
+// "app." prefix the Beelines add to custom fields.
+func WithBeelineCompatibility() ExporterOption {
+	return func(c *exporterConfig) error {
+		hostname, _ := os.Hostname()
+		c.processors = append(c.processors,
+			transformProcessor(normalizeHTTPFields),
+			transformProcessor(normalizeDatabaseFields),
+			transformProcessor(beelineFieldAdder(hostname)))
+		return nil
+	}
+}
@@ -0,0 +1,57 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestBeelineSpanType(t *testing.T) {
+	parent := apitrace.SpanID{1}
+	assert.Equal(t, "root", beelineSpanType(&trace.SpanSnapshot{}))
+	assert.Equal(t, "subroot", beelineSpanType(&trace.SpanSnapshot{ParentSpanID: parent, HasRemoteParent: true}))
+	assert.Equal(t, "mid", beelineSpanType(&trace.SpanSnapshot{ParentSpanID: parent, ChildSpanCount: 2}))
+	assert.Equal(t, "leaf", beelineSpanType(&trace.SpanSnapshot{ParentSpanID: parent}))
+}
+
+func TestHoneycombBeelineCompatibility(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb, WithBeelineCompatibility())
+	assert.Nil(err)
+
+	sds := []*trace.SpanSnapshot{
+		{
+			Name:       "GET /items",
+			SpanKind:   apitrace.SpanKindServer,
+			Attributes: []label.KeyValue{label.String("http.method", "GET"), label.String("http.target", "/items?page=2")},
+		},
+		{
+			Name:          "query",
+			ParentSpanID:  apitrace.SpanID{1},
+			Attributes:    []label.KeyValue{label.String("db.statement", "SELECT 1")},
+			MessageEvents: []trace.Event{{Name: "rows"}},
+		},
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	events := mockHoneycomb.Events()
+	assert.Len(events, 3)
+	server, spanEvent, query := events[0].Data, events[1].Data, events[2].Data
+	assert.Equal("root", server[spanTypeField])
+	assert.Equal("http_request", server[beelineTypeField])
+	assert.Equal("/items", server["request.path"])
+	assert.Equal("GET", server["request.method"])
+	assert.NotContains(spanEvent, spanTypeField)
+	assert.Equal("leaf", query[spanTypeField])
+	assert.Equal("sql", query[beelineTypeField])
+	assert.Equal("SELECT 1", query["db.query"])
+}
@@ -0,0 +1,87 @@
+package honeycomb
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/label"
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/semconv"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// benchmarkSpan returns a span with the given numbers of attributes, span
+// events, and links.
+func benchmarkSpan(attributes, events, links int) *exporttrace.SpanSnapshot {
+	traceID, _ := apitrace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := apitrace.SpanIDFromHex("0102030405060708")
+	now := time.Now()
+	s := &exporttrace.SpanSnapshot{
+		SpanContext: apitrace.SpanContext{TraceID: traceID, SpanID: spanID},
+		Name:        "/checkout",
+		StartTime:   now,
+		EndTime:     now.Add(time.Millisecond),
+		Resource:    resource.NewWithAttributes(semconv.ServiceNameKey.String("checkout")),
+	}
+	for i := 0; i < attributes; i++ {
+		s.Attributes = append(s.Attributes, label.String("attr."+strconv.Itoa(i), "value"))
+	}
+	for i := 0; i < events; i++ {
+		s.MessageEvents = append(s.MessageEvents, exporttrace.Event{Name: "event", Time: now})
+	}
+	for i := 0; i < links; i++ {
+		s.Links = append(s.Links, apitrace.Link{SpanContext: s.SpanContext})
+	}
+	return s
+}
+
+func BenchmarkExportSpanShapes(b *testing.B) {
+	shapes := []struct {
+		name                      string
+		attributes, events, links int
+	}{
+		{"bare", 0, 0, 0},
+		{"attributes", 50, 0, 0},
+		{"events", 5, 10, 0},
+		{"links", 5, 0, 10},
+	}
+	for _, shape := range shapes {
+		b.Run(shape.name, func(b *testing.B) {
+			batch := []*exporttrace.SpanSnapshot{benchmarkSpan(shape.attributes, shape.events, shape.links)}
+			exporter, err := NewExporter(Config{APIKey: "overridden"}, WithSender(discardSender{}))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer exporter.Shutdown(context.Background())
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				exporter.ExportSpans(context.Background(), batch)
+			}
+		})
+	}
+}
+
+func BenchmarkExportSpansParallel(b *testing.B) {
+	batch := make([]*exporttrace.SpanSnapshot, 64)
+	for i := range batch {
+		batch[i] = benchmarkSpan(10, 1, 0)
+	}
+	exporter, err := NewExporter(Config{APIKey: "overridden"}, WithSender(discardSender{}))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer exporter.Shutdown(context.Background())
+	b.ReportAllocs()
+	b.ResetTimer()
+	start := time.Now()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			exporter.ExportSpans(context.Background(), batch)
+		}
+	})
+	b.ReportMetric(float64(b.N*len(batch))/time.Since(start).Seconds(), "spans/s")
+}
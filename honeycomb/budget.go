@@ -0,0 +1,131 @@
+package honeycomb
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// EventBudgetExceeded describes an interval during which an exporter sent as
+// many events as its budget allows.
+type EventBudgetExceeded struct {
+	// Budget is the number of events the exporter may send per interval.
+	Budget uint64
+	// IntervalStart is the time at which the interval began.
+	IntervalStart time.Time
+}
+
+// WithEventBudget limits the exporter to sending n events per interval,
+// protecting against unexpected usage, such as that caused by a bug
+// producing a flood of spans. Once the exporter has sent n events during an
+// interval, it drops further spans, along with their span events and links,
+// until the next interval begins, unless configured by
+// WithOverBudgetSampleRate to sample them instead. The exporter calls
+// onExceeded, if not nil, the first time it exhausts the budget during each
+// interval; otherwise, it logs a warning. The function must not block.
+func WithEventBudget(n uint64, interval time.Duration, onExceeded func(EventBudgetExceeded)) ExporterOption {
+	return func(c *exporterConfig) error {
+		if n == 0 {
+			return errors.New("event budget must be positive")
+		}
+		if interval <= 0 {
+			return errors.New("event budget interval must be positive")
+		}
+		c.eventBudget = n
+		c.eventBudgetInterval = interval
+		c.eventBudgetExceeded = onExceeded
+		return nil
+	}
+}
+
+// WithOverBudgetSampleRate causes an exporter configured with
+// WithEventBudget to keep one in rate traces, rather than none, once it has
+// exhausted its budget for an interval, multiplying the sample rate of the
+// events for their spans accordingly. Whether it keeps a span depends only
+// on its trace ID, so traces are kept or dropped whole, independently of
+// any sampler's decision.
+func WithOverBudgetSampleRate(rate uint) ExporterOption {
+	return func(c *exporterConfig) error {
+		if rate < 2 {
+			return errors.New("over-budget sample rate must be at least 2")
+		}
+		c.overBudgetSampleRate = rate
+		return nil
+	}
+}
+
+// eventBudget counts the events sent during each interval against a limit.
+type eventBudget struct {
+	limit      uint64
+	interval   time.Duration
+	overRate   uint
+	onExceeded func(EventBudgetExceeded)
+	now        func() time.Time
+
+	mu       sync.Mutex
+	start    time.Time
+	sent     uint64
+	exceeded bool
+}
+
+func newEventBudget(limit uint64, interval time.Duration, overRate uint, onExceeded func(EventBudgetExceeded)) *eventBudget {
+	if onExceeded == nil {
+		onExceeded = func(x EventBudgetExceeded) {
+			log.Printf("Honeycomb exporter: sent the budgeted %d events in the interval beginning %s; limiting spans until the next interval",
+				x.Budget, x.IntervalStart.Format(time.RFC3339))
+		}
+	}
+	return &eventBudget{
+		limit:      limit,
+		interval:   interval,
+		overRate:   overRate,
+		onExceeded: onExceeded,
+		now:        time.Now,
+	}
+}
+
+// roll begins a new interval if the current one has ended. The caller must
+// hold b.mu.
+func (b *eventBudget) roll() {
+	if now := b.now(); now.Sub(b.start) >= b.interval {
+		b.start = now
+		b.sent = 0
+		b.exceeded = false
+	}
+}
+
+// admit reports whether to send the events for a span of the trace with the
+// given ID, along with the factor by which to multiply their sample rate.
+func (b *eventBudget) admit(traceID apitrace.TraceID) (uint, bool) {
+	b.mu.Lock()
+	b.roll()
+	exhausted := b.sent >= b.limit
+	b.mu.Unlock()
+	if !exhausted {
+		return 1, true
+	}
+	if b.overRate == 0 || !exporterSampled(traceID, "budget", b.overRate) {
+		return 0, false
+	}
+	return b.overRate, true
+}
+
+// record counts an event sent, notifying the callback if that exhausts the
+// budget.
+func (b *eventBudget) record() {
+	b.mu.Lock()
+	b.roll()
+	b.sent++
+	notify := b.sent >= b.limit && !b.exceeded
+	if notify {
+		b.exceeded = true
+	}
+	start := b.start
+	b.mu.Unlock()
+	if notify {
+		b.onExceeded(EventBudgetExceeded{Budget: b.limit, IntervalStart: start})
+	}
+}
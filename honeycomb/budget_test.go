@@ -0,0 +1,98 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestEventBudgetIntervals(t *testing.T) {
+	assert := assert.New(t)
+
+	var exceeded []EventBudgetExceeded
+	b := newEventBudget(2, time.Minute, 0, func(x EventBudgetExceeded) {
+		exceeded = append(exceeded, x)
+	})
+	now := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	var id apitrace.TraceID
+	for i := 0; i < 2; i++ {
+		_, ok := b.admit(id)
+		assert.True(ok)
+		b.record()
+	}
+	_, ok := b.admit(id)
+	assert.False(ok)
+	assert.Equal([]EventBudgetExceeded{{Budget: 2, IntervalStart: now}}, exceeded)
+
+	now = now.Add(time.Minute)
+	_, ok = b.admit(id)
+	assert.True(ok)
+}
+
+func TestHoneycombEventBudget(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exceeded := 0
+	exporter, err := makeTestExporter(mockHoneycomb, WithEventBudget(2, time.Hour, func(EventBudgetExceeded) {
+		exceeded++
+	}))
+	assert.Nil(err)
+
+	sds := []*trace.SpanSnapshot{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.Len(mockHoneycomb.Events(), 2)
+	assert.Equal(1, exceeded)
+}
+
+func TestHoneycombOverBudgetSampleRate(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithEventBudget(1, time.Hour, func(EventBudgetExceeded) {}),
+		WithOverBudgetSampleRate(2))
+	assert.Nil(err)
+
+	low, high := exporterSampledIDs("budget", 2)
+	sds := []*trace.SpanSnapshot{
+		{Name: "first", SpanContext: apitrace.SpanContext{TraceID: high}},
+		{Name: "kept", SpanContext: apitrace.SpanContext{TraceID: low}},
+		{Name: "dropped", SpanContext: apitrace.SpanContext{TraceID: high}},
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	events := mockHoneycomb.Events()
+	assert.Len(events, 2)
+	assert.Equal("kept", events[1].Data["name"])
+	assert.Equal(uint(2), events[1].SampleRate)
+
+	_, err = makeTestExporter(&transmission.MockSender{}, WithOverBudgetSampleRate(2))
+	assert.Error(err)
+}
+
+func TestOverBudgetSampleRateStackedOnHeadSampler(t *testing.T) {
+	b := newEventBudget(1, time.Hour, 20, func(EventBudgetExceeded) {})
+	b.record()
+	// Of the traces a head sampler keeps one in ten of, the budget must keep
+	// one in twenty, so that the events' rate of 200 counts them correctly.
+	survivors := headSampled(randomTraceIDs(50000), 10)
+	kept := 0
+	for _, id := range survivors {
+		if rate, ok := b.admit(id); ok {
+			assert.Equal(t, uint(20), rate)
+			kept++
+		}
+	}
+	assert.InDelta(t, 1.0/20, float64(kept)/float64(len(survivors)), 0.015)
+}
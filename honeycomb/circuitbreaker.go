@@ -0,0 +1,201 @@
+package honeycomb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of the fields of CircuitBreakerPolicy left zero.
+const (
+	defaultCircuitBreakerThreshold     = 5
+	defaultCircuitBreakerProbeInterval = time.Minute
+)
+
+// CircuitState is the state of the circuit breaker configured by
+// WithAuthCircuitBreaker.
+type CircuitState int32
+
+const (
+	// CircuitClosed is the state in which the exporter sends spans as usual.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen is the state in which the exporter drops spans, because
+	// Honeycomb has been rejecting its API key.
+	CircuitOpen
+	// CircuitHalfOpen is the state in which the exporter has sent the spans
+	// of one call to ExportSpans as a probe, and drops others until learning
+	// whether Honeycomb accepts its API key again.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int32(s))
+	}
+}
+
+// ErrCircuitOpen is the reason ExportSpans gives for the spans it drops while
+// the circuit breaker configured by WithAuthCircuitBreaker is open.
+var ErrCircuitOpen = errors.New("dropped span while Honeycomb rejects the API key")
+
+// CircuitBreakerPolicy configures WithAuthCircuitBreaker.
+type CircuitBreakerPolicy struct {
+	// Threshold is the number of consecutive requests Honeycomb must reject
+	// with HTTP status 401 for the breaker to open. If zero, it is five.
+	Threshold int
+	// ProbeInterval is the interval at which an open breaker lets the spans
+	// of one call to ExportSpans through, to learn whether Honeycomb accepts
+	// the API key again. If zero, it is a minute.
+	ProbeInterval time.Duration
+	// OnStateChange, if not nil, is called with each new state of the
+	// breaker. It must not block, as it holds up the exporter's requests.
+	OnStateChange func(CircuitState)
+}
+
+// WithAuthCircuitBreaker causes the exporter to stop sending spans to
+// Honeycomb once it has rejected the exporter's API key, with HTTP status
+// 401, for as many consecutive requests as policy's threshold, such as when
+// the key has been revoked. While the breaker is open, ExportSpans drops
+// spans before turning them into events, failing them with ErrCircuitOpen
+// without reporting them to the error hook. Once per probe interval, it
+// sends the spans of one call as a probe, closing the breaker if Honeycomb
+// accepts them and leaving it open if not. The exporter reports its state
+// from CircuitState and to policy's OnStateChange.
+//
+// WithAuthCircuitBreaker has no effect on a transmission given to
+// WithSender.
+func WithAuthCircuitBreaker(policy CircuitBreakerPolicy) ExporterOption {
+	return func(c *exporterConfig) error {
+		if policy.Threshold < 0 {
+			return errors.New("circuit breaker threshold must not be negative")
+		}
+		if policy.ProbeInterval < 0 {
+			return errors.New("circuit breaker probe interval must not be negative")
+		}
+		if policy.Threshold == 0 {
+			policy.Threshold = defaultCircuitBreakerThreshold
+		}
+		if policy.ProbeInterval == 0 {
+			policy.ProbeInterval = defaultCircuitBreakerProbeInterval
+		}
+		c.circuitBreaker = &policy
+		return nil
+	}
+}
+
+// circuitBreaker tracks Honeycomb's rejections of the API key.
+type circuitBreaker struct {
+	policy CircuitBreakerPolicy
+	// state holds the CircuitState, read without holding mu.
+	state int32
+
+	mu       sync.Mutex
+	failures int
+	// since is the time at which the breaker last opened or sent a probe.
+	since time.Time
+}
+
+func newCircuitBreaker(policy CircuitBreakerPolicy) *circuitBreaker {
+	return &circuitBreaker{policy: policy}
+}
+
+func (b *circuitBreaker) current() CircuitState {
+	return CircuitState(atomic.LoadInt32(&b.state))
+}
+
+// setState moves the breaker to state, which must differ from its current
+// one. mu must be held.
+func (b *circuitBreaker) setState(state CircuitState) {
+	atomic.StoreInt32(&b.state, int32(state))
+	if b.policy.OnStateChange != nil {
+		b.policy.OnStateChange(state)
+	}
+}
+
+// allow reports whether to send spans at now, letting a probe through an
+// open breaker once per probe interval.
+func (b *circuitBreaker) allow(now time.Time) bool {
+	if b.current() == CircuitClosed {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.current() {
+	case CircuitClosed:
+		return true
+	case CircuitOpen:
+		if now.Sub(b.since) < b.policy.ProbeInterval {
+			return false
+		}
+		b.setState(CircuitHalfOpen)
+	default:
+		// A probe sending no request, such as one whose spans were all
+		// sampled out, must not hold the breaker half-open forever.
+		if now.Sub(b.since) < b.policy.ProbeInterval {
+			return false
+		}
+	}
+	b.since = now
+	return true
+}
+
+// record accounts for a response with the given HTTP status received at now.
+// Server errors say nothing of the API key, and leave the breaker as it is.
+func (b *circuitBreaker) record(status int, now time.Time) {
+	if status >= 500 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status != http.StatusUnauthorized {
+		b.failures = 0
+		if b.current() != CircuitClosed {
+			b.setState(CircuitClosed)
+		}
+		return
+	}
+	b.failures++
+	switch b.current() {
+	case CircuitClosed:
+		if b.failures >= b.policy.Threshold {
+			b.since = now
+			b.setState(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		b.since = now
+		b.setState(CircuitOpen)
+	}
+}
+
+// breakerTransport reports the status of each response to a circuitBreaker.
+type breakerTransport struct {
+	base    http.RoundTripper
+	breaker *circuitBreaker
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		t.breaker.record(resp.StatusCode, time.Now())
+	}
+	return resp, err
+}
+
+// CircuitState returns the state of the circuit breaker configured by
+// WithAuthCircuitBreaker, or CircuitClosed if there is none.
+func (e *Exporter) CircuitState() CircuitState {
+	if e.breaker == nil {
+		return CircuitClosed
+	}
+	return e.breaker.current()
+}
@@ -0,0 +1,128 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestCircuitBreakerStates(t *testing.T) {
+	assert := assert.New(t)
+	var states []CircuitState
+	b := newCircuitBreaker(CircuitBreakerPolicy{
+		Threshold:     2,
+		ProbeInterval: time.Minute,
+		OnStateChange: func(s CircuitState) {
+			states = append(states, s)
+		},
+	})
+	now := time.Now()
+
+	b.record(http.StatusUnauthorized, now)
+	b.record(http.StatusOK, now)
+	b.record(http.StatusUnauthorized, now)
+	b.record(http.StatusServiceUnavailable, now)
+	assert.Equal(CircuitClosed, b.current())
+	b.record(http.StatusUnauthorized, now)
+	assert.Equal(CircuitOpen, b.current())
+	assert.False(b.allow(now.Add(time.Second)))
+
+	assert.True(b.allow(now.Add(time.Minute)))
+	assert.Equal(CircuitHalfOpen, b.current())
+	assert.False(b.allow(now.Add(time.Minute + time.Second)))
+	b.record(http.StatusUnauthorized, now.Add(time.Minute+time.Second))
+	assert.Equal(CircuitOpen, b.current())
+	assert.False(b.allow(now.Add(time.Minute + 2*time.Second)))
+
+	// A probe that sends nothing gives way to another.
+	assert.True(b.allow(now.Add(3 * time.Minute)))
+	assert.True(b.allow(now.Add(4 * time.Minute)))
+	b.record(http.StatusBadRequest, now.Add(4*time.Minute))
+	assert.Equal(CircuitClosed, b.current())
+	assert.True(b.allow(now.Add(4 * time.Minute)))
+
+	assert.Equal([]CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}, states)
+}
+
+func TestAuthCircuitBreaker(t *testing.T) {
+	assert := assert.New(t)
+	var requests, unauthorized int32 = 0, 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&unauthorized) != 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, `[{"status": 202}]`)
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var states []CircuitState
+	var errs []error
+	exporter, err := NewExporter(Config{APIKey: "revoked"},
+		TargetingDataset("test"),
+		WithAPIURL(server.URL),
+		WithAuthCircuitBreaker(CircuitBreakerPolicy{
+			Threshold:     2,
+			ProbeInterval: 50 * time.Millisecond,
+			OnStateChange: func(s CircuitState) {
+				mu.Lock()
+				states = append(states, s)
+				mu.Unlock()
+			},
+		}),
+		CallingOnError(func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Nil(exporter.Start(context.Background()))
+	ctx := context.Background()
+	export := func() error {
+		return exporter.ExportSpans(ctx, []*trace.SpanSnapshot{{Name: "span"}})
+	}
+
+	for i := 0; i < 2; i++ {
+		assert.Nil(export())
+		assert.Nil(exporter.ForceFlush(ctx))
+	}
+	assert.Equal(CircuitOpen, exporter.CircuitState())
+	assert.EqualValues(2, atomic.LoadInt32(&requests))
+
+	err = export()
+	assert.True(errors.Is(err, ErrCircuitOpen))
+	assert.Nil(exporter.ForceFlush(ctx))
+	assert.EqualValues(2, atomic.LoadInt32(&requests))
+
+	atomic.StoreInt32(&unauthorized, 0)
+	time.Sleep(50 * time.Millisecond)
+	assert.Nil(export())
+	assert.Nil(exporter.ForceFlush(ctx))
+	assert.Equal(CircuitClosed, exporter.CircuitState())
+	assert.EqualValues(3, atomic.LoadInt32(&requests))
+	assert.Nil(exporter.Shutdown(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal([]CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}, states)
+	assert.Len(errs, 2)
+}
+
+func TestWithAuthCircuitBreakerInvalid(t *testing.T) {
+	assert.Error(t, ValidateOptions(WithAuthCircuitBreaker(CircuitBreakerPolicy{Threshold: -1})))
+	assert.Error(t, ValidateOptions(WithAuthCircuitBreaker(CircuitBreakerPolicy{ProbeInterval: -time.Second})))
+}
@@ -0,0 +1,426 @@
+package honeycomb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	apitrace "go.opentelemetry.io/otel/trace"
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a time.Duration that can be unmarshaled from text, such as a
+// JSON string, in the form accepted by time.ParseDuration, such as "30s."
+type Duration time.Duration
+
+// UnmarshalText parses a duration such as "30s."
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText formats a duration as time.Duration's String method does.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// MirrorDataset holds the arguments to WithMirrorDataset.
+type MirrorDataset struct {
+	// Dataset is the name of the dataset.
+	Dataset string `json:"dataset"`
+	// SampleRate is the rate at which events are copied to it.
+	SampleRate uint `json:"sample_rate"`
+}
+
+// URLQueryScrubbing holds the arguments to WithURLQueryScrubbing.
+type URLQueryScrubbing struct {
+	// Mask replaces the values of query string parameters. If empty, the
+	// values are removed.
+	Mask string `json:"mask"`
+	// Allowed names the parameters whose values are sent intact.
+	Allowed []string `json:"allowed"`
+}
+
+// FullConfig holds the settings of an exporter as plain data: those of
+// Config, along with those of the exporter options that don't take
+// functions. Unlike a list of options, it can be unmarshaled from an
+// application's own configuration system. Fields left at their zero values
+// leave the corresponding options unapplied.
+type FullConfig struct {
+	// APIKey is your Honeycomb API key, as in Config.
+	APIKey string `json:"api_key"`
+	// APIURL corresponds to WithAPIURL.
+	APIURL string `json:"api_url"`
+	// RequiredRegion corresponds to WithRequiredRegion.
+	RequiredRegion string `json:"required_region"`
+	// Region corresponds to WithRegion, and applies instead of APIURL.
+	Region string `json:"region"`
+	// UserAgentAddendum corresponds to WithUserAgentAddendum.
+	UserAgentAddendum string `json:"user_agent_addendum"`
+	// ProxyURL corresponds to WithProxyURL.
+	ProxyURL string `json:"proxy_url"`
+	// CACertificates corresponds to WithCACertificates, naming the file
+	// holding the certificates.
+	CACertificates string `json:"ca_certificates"`
+	// ClientCertificate and ClientKey correspond to WithClientCertificate,
+	// naming the files holding the certificate and its private key.
+	ClientCertificate string `json:"client_certificate"`
+	ClientKey         string `json:"client_key"`
+	// RetryMaxAttempts, RetryInitialBackoff, RetryMaxBackoff, and RetryJitter
+	// correspond to WithRetry, which applies if any is set.
+	RetryMaxAttempts    int      `json:"retry_max_attempts"`
+	RetryInitialBackoff Duration `json:"retry_initial_backoff"`
+	RetryMaxBackoff     Duration `json:"retry_max_backoff"`
+	RetryJitter         float64  `json:"retry_jitter"`
+	// CircuitBreakerThreshold and CircuitBreakerProbeInterval correspond to
+	// WithAuthCircuitBreaker, which applies if either is set.
+	CircuitBreakerThreshold     int      `json:"circuit_breaker_threshold"`
+	CircuitBreakerProbeInterval Duration `json:"circuit_breaker_probe_interval"`
+	// DiskBufferDir and DiskBufferMaxBytes correspond to WithDiskBuffer.
+	DiskBufferDir      string `json:"disk_buffer_dir"`
+	DiskBufferMaxBytes int64  `json:"disk_buffer_max_bytes"`
+	// ErrorThrottleInterval corresponds to WithErrorThrottle.
+	ErrorThrottleInterval Duration `json:"error_throttle_interval"`
+	// DisableAutoStart corresponds to WithAutoStart(false).
+	DisableAutoStart bool `json:"disable_auto_start"`
+	// KeyVerification corresponds to WithKeyVerification.
+	KeyVerification bool `json:"key_verification"`
+	// DatasetTemplate corresponds to WithDatasetTemplate.
+	DatasetTemplate string `json:"dataset_template"`
+	// FailoverAPIURL, FailoverAfter, and FailoverProbeInterval correspond to
+	// WithFailover, which applies if FailoverAPIURL is set.
+	FailoverAPIURL        string   `json:"failover_api_url"`
+	FailoverAfter         Duration `json:"failover_after"`
+	FailoverProbeInterval Duration `json:"failover_probe_interval"`
+	// Dataset corresponds to TargetingDataset.
+	Dataset string `json:"dataset"`
+	// DatasetPreflight corresponds to WithDatasetPreflight.
+	DatasetPreflight bool `json:"dataset_preflight"`
+	// ServiceName corresponds to WithServiceName.
+	ServiceName string `json:"service_name"`
+	// ServiceNamePrecedence corresponds to WithServiceNamePrecedence, naming
+	// the precedence: "option," "resource," or "reject."
+	ServiceNamePrecedence string `json:"service_name_precedence"`
+	// Debug corresponds to WithDebug.
+	Debug bool `json:"debug"`
+	// Disabled corresponds to WithDisabled.
+	Disabled bool `json:"disabled"`
+
+	// Fields corresponds to WithFields.
+	Fields map[string]interface{} `json:"fields"`
+	// ServiceFields corresponds to WithServiceFields.
+	ServiceFields map[string]map[string]interface{} `json:"service_fields"`
+	// QueueDepthField corresponds to WithQueueDepthField.
+	QueueDepthField string `json:"queue_depth_field"`
+	// OmitResourceAttributes corresponds to WithoutResourceAttributes.
+	OmitResourceAttributes bool `json:"omit_resource_attributes"`
+	// TimestampAttribute corresponds to WithTimestampAttribute.
+	TimestampAttribute string `json:"timestamp_attribute"`
+	// FieldPolicy corresponds to WithFieldPolicy.
+	FieldPolicy *FieldPolicy `json:"field_policy"`
+	// ErrorsDataset and ErrorsDatasetIncludesErrorSpans correspond to
+	// WithErrorsDataset.
+	ErrorsDataset                   string `json:"errors_dataset"`
+	ErrorsDatasetIncludesErrorSpans bool   `json:"errors_dataset_includes_error_spans"`
+	// MirrorDatasets correspond to WithMirrorDataset, given once for each.
+	MirrorDatasets []MirrorDataset `json:"mirror_datasets"`
+
+	// The exporter passes events through the pipeline stages configured by
+	// the following fields in the order in which they appear.
+
+	// SchemaVersion corresponds to WithSchemaTransformation.
+	SchemaVersion string `json:"schema_version"`
+	// HTTPFieldNormalization corresponds to WithHTTPFieldNormalization.
+	HTTPFieldNormalization bool `json:"http_field_normalization"`
+	// DatabaseFieldNormalization corresponds to
+	// WithDatabaseFieldNormalization.
+	DatabaseFieldNormalization bool `json:"database_field_normalization"`
+	// MessagingFieldNormalization and MessagingProducerTimestampKeys
+	// correspond to WithMessagingFieldNormalization.
+	MessagingFieldNormalization    bool     `json:"messaging_field_normalization"`
+	MessagingProducerTimestampKeys []string `json:"messaging_producer_timestamp_keys"`
+	// GRPCStatusMapping corresponds to WithGRPCStatusMapping, naming its
+	// policy: "server_faults" for GRPCServerFaults or "all_errors" for
+	// GRPCAllErrors.
+	GRPCStatusMapping string `json:"grpc_status_mapping"`
+	// BeelineCompatibility corresponds to WithBeelineCompatibility.
+	BeelineCompatibility bool `json:"beeline_compatibility"`
+	// SQLObfuscation corresponds to WithSQLObfuscation.
+	SQLObfuscation bool `json:"sql_obfuscation"`
+	// URLQueryScrubbing corresponds to WithURLQueryScrubbing.
+	URLQueryScrubbing *URLQueryScrubbing `json:"url_query_scrubbing"`
+
+	// ExportedSpanKinds corresponds to WithExportedSpanKinds, naming the
+	// kinds, such as "server" and "consumer."
+	ExportedSpanKinds []string `json:"exported_span_kinds"`
+	// MinSpanDuration corresponds to WithMinSpanDuration.
+	MinSpanDuration Duration `json:"min_span_duration"`
+	// MaxSpansPerTrace corresponds to WithMaxSpansPerTrace.
+	MaxSpansPerTrace int `json:"max_spans_per_trace"`
+	// SampleRate corresponds to WithSampleRate.
+	SampleRate uint `json:"sample_rate"`
+	// SpanEventSampleRate and LinkSampleRate correspond to
+	// WithAnnotationSampling. If only one is set, the other defaults to 1.
+	SpanEventSampleRate uint `json:"span_event_sample_rate"`
+	LinkSampleRate      uint `json:"link_sample_rate"`
+	// ThroughputTarget and ThroughputWindow correspond to
+	// WithThroughputTarget.
+	ThroughputTarget float64  `json:"throughput_target"`
+	ThroughputWindow Duration `json:"throughput_window"`
+	// EventBudget and EventBudgetInterval correspond to WithEventBudget.
+	EventBudget         uint64   `json:"event_budget"`
+	EventBudgetInterval Duration `json:"event_budget_interval"`
+	// OverBudgetSampleRate corresponds to WithOverBudgetSampleRate.
+	OverBudgetSampleRate uint `json:"over_budget_sample_rate"`
+	// MaxEventAttributeCount corresponds to WithMaxEventAttributeCount.
+	MaxEventAttributeCount int `json:"max_event_attribute_count"`
+	// OversizedEventPolicy corresponds to WithOversizedEventPolicy, naming
+	// its policy: "truncate," "drop," or "split."
+	OversizedEventPolicy string `json:"oversized_event_policy"`
+
+	// AsyncQueueSize and AsyncWorkers correspond to WithAsyncExport.
+	AsyncQueueSize int `json:"async_queue_size"`
+	AsyncWorkers   int `json:"async_workers"`
+	// MaxBatchBytes corresponds to WithMaxBatchBytes.
+	MaxBatchBytes int `json:"max_batch_bytes"`
+	// VolumeAccounting corresponds to WithVolumeAccounting.
+	VolumeAccounting bool `json:"volume_accounting"`
+}
+
+var grpcErrorPolicies = map[string]GRPCErrorPolicy{
+	"server_faults": GRPCServerFaults,
+	"all_errors":    GRPCAllErrors,
+}
+
+var serviceNamePrecedences = map[string]ServiceNamePrecedence{
+	"option":   PreferServiceNameOption,
+	"resource": PreferResourceServiceName,
+	"reject":   RejectServiceNameConflicts,
+}
+
+var oversizedEventPolicies = map[string]OversizedEventPolicy{
+	"truncate": TruncateOversizedEvents,
+	"drop":     DropOversizedEvents,
+	"split":    SplitOversizedEvents,
+}
+
+// Options returns the exporter options equivalent to the configuration.
+// Options for invalid settings fail when passed to NewExporter.
+func (c FullConfig) Options() []ExporterOption {
+	var opts []ExporterOption
+	add := func(set bool, opt ExporterOption) {
+		if set {
+			opts = append(opts, opt)
+		}
+	}
+	add(len(c.APIURL) != 0, WithAPIURL(c.APIURL))
+	add(len(c.RequiredRegion) != 0, WithRequiredRegion(c.RequiredRegion))
+	add(len(c.Region) != 0, WithRegion(c.Region))
+	add(len(c.UserAgentAddendum) != 0, WithUserAgentAddendum(c.UserAgentAddendum))
+	add(len(c.ProxyURL) != 0, WithProxyURL(c.ProxyURL))
+	add(len(c.CACertificates) != 0, WithCACertificates(c.CACertificates))
+	add(len(c.ClientCertificate) != 0 || len(c.ClientKey) != 0, WithClientCertificate(c.ClientCertificate, c.ClientKey))
+	add(c.RetryMaxAttempts != 0 || c.RetryInitialBackoff != 0 || c.RetryMaxBackoff != 0 || c.RetryJitter != 0, WithRetry(RetryPolicy{
+		MaxAttempts:    c.RetryMaxAttempts,
+		InitialBackoff: time.Duration(c.RetryInitialBackoff),
+		MaxBackoff:     time.Duration(c.RetryMaxBackoff),
+		Jitter:         c.RetryJitter,
+	}))
+	add(c.CircuitBreakerThreshold != 0 || c.CircuitBreakerProbeInterval != 0, WithAuthCircuitBreaker(CircuitBreakerPolicy{
+		Threshold:     c.CircuitBreakerThreshold,
+		ProbeInterval: time.Duration(c.CircuitBreakerProbeInterval),
+	}))
+	add(len(c.DiskBufferDir) != 0 || c.DiskBufferMaxBytes != 0, WithDiskBuffer(c.DiskBufferDir, c.DiskBufferMaxBytes))
+	add(c.ErrorThrottleInterval != 0, WithErrorThrottle(time.Duration(c.ErrorThrottleInterval)))
+	add(c.DisableAutoStart, WithAutoStart(false))
+	add(c.KeyVerification, WithKeyVerification())
+	add(len(c.DatasetTemplate) != 0, WithDatasetTemplate(c.DatasetTemplate))
+	add(len(c.FailoverAPIURL) != 0, WithFailover(FailoverPolicy{
+		APIURL:        c.FailoverAPIURL,
+		After:         time.Duration(c.FailoverAfter),
+		ProbeInterval: time.Duration(c.FailoverProbeInterval),
+	}))
+	add(len(c.Dataset) != 0, TargetingDataset(c.Dataset))
+	add(c.DatasetPreflight, WithDatasetPreflight())
+	add(len(c.ServiceName) != 0, WithServiceName(c.ServiceName))
+	add(len(c.ServiceNamePrecedence) != 0, func(ec *exporterConfig) error {
+		p, ok := serviceNamePrecedences[c.ServiceNamePrecedence]
+		if !ok {
+			return fmt.Errorf("unknown service name precedence %q", c.ServiceNamePrecedence)
+		}
+		return WithServiceNamePrecedence(p)(ec)
+	})
+	add(c.Debug, WithDebugEnabled())
+	add(c.Disabled, WithDisabled(true))
+
+	add(len(c.Fields) != 0, WithFields(c.Fields))
+	add(len(c.ServiceFields) != 0, WithServiceFields(c.ServiceFields))
+	add(len(c.QueueDepthField) != 0, WithQueueDepthField(c.QueueDepthField))
+	add(c.OmitResourceAttributes, WithoutResourceAttributes())
+	add(len(c.TimestampAttribute) != 0, WithTimestampAttribute(c.TimestampAttribute))
+	if c.FieldPolicy != nil {
+		opts = append(opts, WithFieldPolicy(*c.FieldPolicy))
+	}
+	add(len(c.ErrorsDataset) != 0, WithErrorsDataset(c.ErrorsDataset, c.ErrorsDatasetIncludesErrorSpans))
+	for _, m := range c.MirrorDatasets {
+		opts = append(opts, WithMirrorDataset(m.Dataset, m.SampleRate))
+	}
+
+	add(len(c.SchemaVersion) != 0, WithSchemaTransformation(c.SchemaVersion))
+	add(c.HTTPFieldNormalization, WithHTTPFieldNormalization())
+	add(c.DatabaseFieldNormalization, WithDatabaseFieldNormalization())
+	add(c.MessagingFieldNormalization, WithMessagingFieldNormalization(c.MessagingProducerTimestampKeys...))
+	add(len(c.GRPCStatusMapping) != 0, func(ec *exporterConfig) error {
+		policy, ok := grpcErrorPolicies[c.GRPCStatusMapping]
+		if !ok {
+			return fmt.Errorf("unknown gRPC status mapping policy %q", c.GRPCStatusMapping)
+		}
+		return WithGRPCStatusMapping(policy)(ec)
+	})
+	add(c.BeelineCompatibility, WithBeelineCompatibility())
+	add(c.SQLObfuscation, WithSQLObfuscation())
+	if s := c.URLQueryScrubbing; s != nil {
+		opts = append(opts, WithURLQueryScrubbing(s.Mask, s.Allowed...))
+	}
+
+	add(len(c.ExportedSpanKinds) != 0, func(ec *exporterConfig) error {
+		kinds := make([]apitrace.SpanKind, len(c.ExportedSpanKinds))
+		for i, name := range c.ExportedSpanKinds {
+			kind, err := parseSpanKind(name)
+			if err != nil {
+				return err
+			}
+			kinds[i] = kind
+		}
+		return WithExportedSpanKinds(kinds...)(ec)
+	})
+	add(c.MinSpanDuration != 0, WithMinSpanDuration(time.Duration(c.MinSpanDuration)))
+	add(c.MaxSpansPerTrace != 0, WithMaxSpansPerTrace(c.MaxSpansPerTrace))
+	add(c.SampleRate != 0, WithSampleRate(c.SampleRate))
+	if c.SpanEventSampleRate != 0 || c.LinkSampleRate != 0 {
+		spanEventRate, linkRate := c.SpanEventSampleRate, c.LinkSampleRate
+		if spanEventRate == 0 {
+			spanEventRate = 1
+		}
+		if linkRate == 0 {
+			linkRate = 1
+		}
+		opts = append(opts, WithAnnotationSampling(spanEventRate, linkRate))
+	}
+	add(c.ThroughputTarget != 0, WithThroughputTarget(c.ThroughputTarget, time.Duration(c.ThroughputWindow)))
+	add(c.EventBudget != 0, WithEventBudget(c.EventBudget, time.Duration(c.EventBudgetInterval), nil))
+	add(c.OverBudgetSampleRate != 0, WithOverBudgetSampleRate(c.OverBudgetSampleRate))
+	add(c.MaxEventAttributeCount != 0, WithMaxEventAttributeCount(c.MaxEventAttributeCount))
+	add(len(c.OversizedEventPolicy) != 0, func(ec *exporterConfig) error {
+		policy, ok := oversizedEventPolicies[c.OversizedEventPolicy]
+		if !ok {
+			return fmt.Errorf("unknown oversized event policy %q", c.OversizedEventPolicy)
+		}
+		return WithOversizedEventPolicy(policy)(ec)
+	})
+
+	add(c.AsyncQueueSize != 0 || c.AsyncWorkers != 0, WithAsyncExport(c.AsyncQueueSize, c.AsyncWorkers))
+	add(c.MaxBatchBytes != 0, WithMaxBatchBytes(c.MaxBatchBytes))
+	add(c.VolumeAccounting, WithVolumeAccounting())
+	return opts
+}
+
+// NewExporterFromConfig returns a new exporter configured by cfg, to which
+// opts, such as those taking hook functions, are applied afterward.
+func NewExporterFromConfig(cfg FullConfig, opts ...ExporterOption) (*Exporter, error) {
+	return NewExporter(Config{APIKey: cfg.APIKey}, append(cfg.Options(), opts...)...)
+}
+
+// LoadConfig reads an exporter configuration from the file at path, in JSON,
+// YAML, or TOML format, according to its extension: ".json," ".yaml" or
+// ".yml," or ".toml." In every format, settings are named as in the JSON
+// encoding of FullConfig, such as this YAML:
+//
+//	dataset: checkout
+//	service_name: checkout-api
+//	fields:
+//	  team: payments
+//	sample_rate: 10
+//	max_batch_bytes: 5000000
+//
+// Settings that LoadConfig doesn't recognize are errors, so that misspelled
+// settings don't go unnoticed. Pass the configuration to
+// NewExporterFromConfig, or use its Options method.
+func LoadConfig(path string) (FullConfig, error) {
+	var cfg FullConfig
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	fail := func(err error) (FullConfig, error) {
+		return FullConfig{}, fmt.Errorf("loading exporter configuration from %s: %w", path, err)
+	}
+	// Other formats are converted to JSON, so that FullConfig's field tags
+	// and unmarshaling methods apply to every format.
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+	case ".yaml", ".yml", ".toml":
+		settings := make(map[string]interface{})
+		if ext == ".toml" {
+			_, err = toml.Decode(string(data), &settings)
+		} else {
+			err = yaml.Unmarshal(data, &settings)
+		}
+		if err != nil {
+			return fail(err)
+		}
+		if data, err = json.Marshal(settings); err != nil {
+			return fail(err)
+		}
+	default:
+		return fail(fmt.Errorf("unknown configuration file format %q", ext))
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	// Keep integer field values from becoming float64s.
+	dec.UseNumber()
+	if err := dec.Decode(&cfg); err != nil {
+		return fail(err)
+	}
+	convertNumbers(cfg.Fields)
+	for _, fields := range cfg.ServiceFields {
+		convertNumbers(fields)
+	}
+	return cfg, nil
+}
+
+// convertNumbers replaces the json.Number values in fields, including those
+// nested in maps and slices, with int64 values if they are integers, and
+// float64 values otherwise.
+func convertNumbers(fields map[string]interface{}) {
+	for k, v := range fields {
+		fields[k] = convertNumber(v)
+	}
+}
+
+func convertNumber(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		convertNumbers(v)
+	case []interface{}:
+		for i, e := range v {
+			v[i] = convertNumber(e)
+		}
+	}
+	return v
+}
@@ -0,0 +1,149 @@
+package honeycomb
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestNewExporterFromConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	var cfg FullConfig
+	err := json.Unmarshal([]byte(`{
+		"api_key": "overridden",
+		"dataset": "from-config",
+		"service_name": "config-service",
+		"fields": {"team": "storage"},
+		"sample_rate": 5,
+		"url_query_scrubbing": {"mask": "x", "allowed": ["page"]},
+		"event_budget": 1000,
+		"event_budget_interval": "1m"
+	}`), &cfg)
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Equal(Duration(time.Minute), cfg.EventBudgetInterval)
+
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := NewExporterFromConfig(cfg, WithSender(mockHoneycomb))
+	if !assert.Nil(err) {
+		return
+	}
+	defer exporter.Shutdown(context.Background())
+
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{
+		Name:       "configured",
+		Attributes: []label.KeyValue{label.String("http.url", "/a?page=2&token=secret")},
+	}}))
+
+	events := mockHoneycomb.Events()
+	if assert.Len(events, 1) {
+		ev := events[0]
+		assert.Equal("from-config", ev.Dataset)
+		assert.Equal(uint(5), ev.SampleRate)
+		assert.Equal("config-service", ev.Data["service_name"])
+		assert.Equal("storage", ev.Data["team"])
+		assert.Equal("/a?page=2&token=x", ev.Data["http.url"])
+	}
+}
+
+func TestFullConfigInvalidSettings(t *testing.T) {
+	for _, cfg := range []FullConfig{
+		{GRPCStatusMapping: "sometimes"},
+		{OversizedEventPolicy: "shrink"},
+		{AsyncQueueSize: 100},
+		{EventBudget: 1000},
+	} {
+		cfg.APIKey = "overridden"
+		_, err := NewExporterFromConfig(cfg, WithSender(&transmission.MockSender{}))
+		assert.Error(t, err, "%+v", cfg)
+	}
+
+	var d Duration
+	assert.Error(t, d.UnmarshalText([]byte("soon")))
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "honeycomb-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	want := FullConfig{
+		APIKey:              "from-file",
+		Dataset:             "from-file",
+		Fields:              map[string]interface{}{"team": "storage", "shard": int64(12345678901234567), "ratio": 0.5},
+		SampleRate:          5,
+		EventBudgetInterval: Duration(time.Minute),
+		MaxBatchBytes:       1000000,
+	}
+	for name, contents := range map[string]string{
+		"config.json": `{
+			"api_key": "from-file",
+			"dataset": "from-file",
+			"fields": {"team": "storage", "shard": 12345678901234567, "ratio": 0.5},
+			"sample_rate": 5,
+			"event_budget_interval": "1m",
+			"max_batch_bytes": 1000000
+		}`,
+		"config.yaml": `
+api_key: from-file
+dataset: from-file
+fields:
+  team: storage
+  shard: 12345678901234567
+  ratio: 0.5
+sample_rate: 5
+event_budget_interval: 1m
+max_batch_bytes: 1000000
+`,
+		"config.toml": `
+api_key = "from-file"
+dataset = "from-file"
+sample_rate = 5
+event_budget_interval = "1m"
+max_batch_bytes = 1000000
+
+[fields]
+team = "storage"
+shard = 12345678901234567
+ratio = 0.5
+`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadConfig(path)
+		if assert.Nil(t, err, name) {
+			assert.Equal(t, want, cfg, name)
+		}
+	}
+
+	for name, contents := range map[string]string{
+		"misspelled.yaml": "datset: from-file\n",
+		"invalid.json":    "{",
+		"config.ini":      "dataset=from-file\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadConfig(path)
+		assert.Error(t, err, name)
+	}
+	_, err = LoadConfig(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package honeycomb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// datasetTemplate names the dataset for each span's events after attributes
+// of its resource.
+type datasetTemplate struct {
+	// literals holds the text around the keys, one more than them.
+	literals []string
+	keys     []label.Key
+}
+
+// parseDatasetTemplate parses a template such as
+// "{service.namespace}.{service.name}".
+func parseDatasetTemplate(template string) (*datasetTemplate, error) {
+	t := &datasetTemplate{}
+	rest := template
+	for {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("dataset template %q has an unclosed \"{\"", template)
+		}
+		key := rest[open+1 : open+end]
+		if len(strings.TrimSpace(key)) == 0 || strings.ContainsRune(key, '{') {
+			return nil, fmt.Errorf("dataset template %q has an invalid attribute name %q", template, key)
+		}
+		t.literals = append(t.literals, rest[:open])
+		t.keys = append(t.keys, label.Key(key))
+		rest = rest[open+end+1:]
+	}
+	if strings.ContainsRune(rest, '}') {
+		return nil, fmt.Errorf("dataset template %q has an unopened \"}\"", template)
+	}
+	if len(t.keys) == 0 {
+		return nil, fmt.Errorf("dataset template %q refers to no attributes; use TargetingDataset", template)
+	}
+	t.literals = append(t.literals, rest)
+	return t, nil
+}
+
+// resolve returns the dataset for a span's events, or false if its resource
+// lacks any of the template's attributes.
+func (t *datasetTemplate) resolve(data *trace.SpanSnapshot) (string, bool) {
+	if data.Resource == nil {
+		return "", false
+	}
+	set := data.Resource.LabelSet()
+	var b strings.Builder
+	for i, key := range t.keys {
+		b.WriteString(t.literals[i])
+		v, ok := set.Value(key)
+		if !ok {
+			return "", false
+		}
+		s := v.Emit()
+		if len(s) == 0 {
+			return "", false
+		}
+		b.WriteString(s)
+	}
+	b.WriteString(t.literals[len(t.keys)])
+	return b.String(), true
+}
+
+// WithDatasetTemplate causes the exporter to send the events for each span
+// to a dataset named after attributes of the span's resource, given in
+// braces, such as "{service.namespace}.{service.name}," so that many
+// services sharing one exporter are kept apart in Honeycomb. The events for
+// spans whose resources lack any of the attributes, or have them empty, go
+// to the dataset given by TargetingDataset, or to their service's dataset if
+// the API key is for Honeycomb Environments & Services. Events sent to other
+// datasets by options such as WithErrorsDataset are unaffected.
+func WithDatasetTemplate(template string) ExporterOption {
+	return func(c *exporterConfig) error {
+		if len(template) == 0 {
+			return errors.New("dataset template must not be empty")
+		}
+		t, err := parseDatasetTemplate(template)
+		if err != nil {
+			return err
+		}
+		c.datasetTemplate = t
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+func TestParseDatasetTemplate(t *testing.T) {
+	for _, template := range []string{
+		"",
+		"static",
+		"{service.name",
+		"{}.x",
+		"{ }",
+		"service.name}",
+		"{service.{name}}",
+	} {
+		_, err := NewExporter(Config{APIKey: "overridden"}, WithDatasetTemplate(template))
+		assert.Error(t, err, template)
+	}
+}
+
+func TestDatasetTemplate(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithDatasetTemplate("{service.namespace}.{service.name}-spans"))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{
+		{
+			Name: "templated",
+			Resource: resource.NewWithAttributes(
+				semconv.ServiceNamespaceKey.String("shop"),
+				semconv.ServiceNameKey.String("checkout")),
+		},
+		{
+			Name:     "missing namespace",
+			Resource: resource.NewWithAttributes(semconv.ServiceNameKey.String("checkout")),
+		},
+		{
+			Name: "empty namespace",
+			Resource: resource.NewWithAttributes(
+				semconv.ServiceNamespaceKey.String(""),
+				semconv.ServiceNameKey.String("checkout")),
+		},
+		{Name: "no resource"},
+	}))
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	events := mockHoneycomb.Events()
+	if assert.Len(events, 4) {
+		assert.Equal("shop.checkout-spans", events[0].Dataset)
+		for _, ev := range events[1:] {
+			assert.Equal("test", ev.Dataset, ev.Data["name"])
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package honeycomb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+)
+
+const defaultAPIURL = "https://api.honeycomb.io/"
+
+// DatasetNotFoundError reports that Honeycomb refused events because their
+// dataset doesn't exist and the API key may not create it, or because the API
+// key may not write to it.
+type DatasetNotFoundError struct {
+	// Dataset is the name of the dataset.
+	Dataset string
+	// StatusCode is the HTTP status with which Honeycomb responded.
+	StatusCode int
+}
+
+func (e *DatasetNotFoundError) Error() string {
+	return fmt.Sprintf("Honeycomb dataset %q was not found or is not writable (HTTP status %d); "+
+		"create the dataset in Honeycomb, or use an API key with the \"Send Events\" and "+
+		"\"Create Datasets\" permissions for the team and environment that should own it",
+		e.Dataset, e.StatusCode)
+}
+
+// DiagnosticCheck records the outcome of one step performed by Diagnose.
+type DiagnosticCheck struct {
+	// Name briefly identifies the check, such as "API key."
+	Name string
+	// Detail describes what the check found when it succeeded.
+	Detail string
+	// Err is the reason the check failed, or nil if it succeeded.
+	Err error
+	// Hint suggests how to remedy a failed check.
+	Hint string
+}
+
+// Diagnosis summarizes the results of Diagnose.
+type Diagnosis struct {
+	// APIURL is the Honeycomb API server address that was checked.
+	APIURL string
+	// Dataset is the name of the dataset that was checked. It is empty if the
+	// API key belongs to an environment and no service name was given, since
+	// events then go to datasets named after the services of their spans.
+	Dataset string
+	// Team is the slug of the team that owns the API key, if known.
+	Team string
+	// Environment is the slug of the environment to which the API key
+	// belongs, if known. It is empty for Classic API keys.
+	Environment string
+	// Checks lists the outcome of each check in the order they were performed.
+	Checks []DiagnosticCheck
+}
+
+// OK reports whether every check succeeded.
+func (d *Diagnosis) OK() bool {
+	for _, c := range d.Checks {
+		if c.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *Diagnosis) pass(name, detail string) {
+	d.Checks = append(d.Checks, DiagnosticCheck{Name: name, Detail: detail})
+}
+
+func (d *Diagnosis) fail(name string, err error, hint string) {
+	d.Checks = append(d.Checks, DiagnosticCheck{Name: name, Err: err, Hint: hint})
+}
+
+// authResponse is the subset of the Honeycomb /1/auth response we use.
+type authResponse struct {
+	APIKeyAccess map[string]bool `json:"api_key_access"`
+	Environment  struct {
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	} `json:"environment"`
+	Team struct {
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	} `json:"team"`
+}
+
+func apiEndpoint(apiURL string, elem ...string) (string, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return "", err
+	}
+	if len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return "", fmt.Errorf("API URL %q must include a scheme and host", apiURL)
+	}
+	u.Path = path.Join(append([]string{"/", u.Path}, elem...)...)
+	return u.String(), nil
+}
+
+func apiRequest(ctx context.Context, client *http.Client, apiKey string, endpoint string) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("X-Honeycomb-Team", apiKey)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	return resp, body, err
+}
+
+// Diagnose checks whether an exporter built from the given configuration and
+// options would be able to deliver events to Honeycomb. It verifies that the
+// API server is reachable, that the API key is valid and permitted to send
+// events, and that the target dataset is accessible, recording the outcome of
+// each step in the returned Diagnosis. For an API key that belongs to an
+// environment, the target dataset is the one named after the service given
+// to WithServiceName, and without one, Diagnose doesn't check any dataset.
+//
+// Diagnose only returns an error if the configuration or options are
+// invalid, in which case it returns an OptionErrors, as NewExporter does.
+func Diagnose(ctx context.Context, config Config, opts ...ExporterOption) (*Diagnosis, error) {
+	econf, err := configureExporter(&config, opts)
+	if err != nil {
+		return nil, err
+	}
+	// targetDataset decides the dataset to check as NewExporter decides where
+	// events go.
+	targetDataset := func(environmentKey bool) string {
+		switch {
+		case !environmentKey && len(econf.dataset) != 0:
+			return econf.dataset
+		case !environmentKey:
+			return defaultDataset
+		case len(econf.serviceName) != 0:
+			return serviceDataset(econf.serviceName)
+		}
+		return ""
+	}
+	d := &Diagnosis{
+		APIURL:  econf.apiURL,
+		Dataset: targetDataset(!IsClassicKey(config.APIKey)),
+	}
+	if len(d.APIURL) == 0 {
+		d.APIURL = defaultAPIURL
+	}
+	client := &http.Client{Transport: econf.roundTripper()}
+
+	authURL, err := apiEndpoint(d.APIURL, "1", "auth")
+	if err != nil {
+		return nil, err
+	}
+	resp, body, err := apiRequest(ctx, client, config.APIKey, authURL)
+	if err != nil {
+		d.fail("API reachability", err,
+			fmt.Sprintf("Check that %s is reachable from this host, including any proxy or firewall rules.", d.APIURL))
+		return d, nil
+	}
+	d.pass("API reachability", fmt.Sprintf("%s responded with HTTP status %d", d.APIURL, resp.StatusCode))
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		d.fail("API key", fmt.Errorf("API key was rejected with HTTP status %d", resp.StatusCode),
+			"Check that the API key is copied correctly and has not been revoked.")
+		return d, nil
+	default:
+		d.fail("API key", fmt.Errorf("unexpected HTTP status %d: %s", resp.StatusCode, strings.TrimSpace(string(body))),
+			"Check that the API URL points to a Honeycomb API server.")
+		return d, nil
+	}
+	var auth authResponse
+	if err := json.Unmarshal(body, &auth); err != nil {
+		d.fail("API key", fmt.Errorf("failed to parse authentication response: %v", err),
+			"Check that the API URL points to a Honeycomb API server.")
+		return d, nil
+	}
+	d.Team = auth.Team.Slug
+	d.Environment = auth.Environment.Slug
+	if auth.APIKeyAccess != nil && !auth.APIKeyAccess["events"] {
+		d.fail("API key", errors.New("API key is not permitted to send events"),
+			"Enable the \"Send Events\" permission for this API key in the team settings.")
+		return d, nil
+	}
+	detail := fmt.Sprintf("API key belongs to team %q", d.Team)
+	if len(d.Environment) != 0 {
+		detail += fmt.Sprintf(" and environment %q", d.Environment)
+	}
+	d.pass("API key", detail)
+
+	if econf.keyVerification {
+		d.Dataset = targetDataset(len(auth.Environment.Slug) != 0)
+	}
+	if len(d.Dataset) == 0 {
+		d.pass("Dataset", "API key belongs to an environment, so events go to datasets named after "+
+			"their services; no service name was given to check")
+		return d, nil
+	}
+	datasetURL, err := apiEndpoint(d.APIURL, "1", "datasets", d.Dataset)
+	if err != nil {
+		return nil, err
+	}
+	resp, _, err = apiRequest(ctx, client, config.APIKey, datasetURL)
+	switch {
+	case err != nil:
+		d.fail("Dataset", err, "Retry once the API server is reachable.")
+	case resp.StatusCode == http.StatusOK:
+		d.pass("Dataset", fmt.Sprintf("dataset %q exists", d.Dataset))
+	case resp.StatusCode == http.StatusNotFound:
+		if auth.APIKeyAccess["createDatasets"] {
+			d.pass("Dataset", fmt.Sprintf("dataset %q does not exist yet and will be created by the first event", d.Dataset))
+		} else {
+			d.fail("Dataset", &DatasetNotFoundError{Dataset: d.Dataset, StatusCode: resp.StatusCode},
+				"Create the dataset in Honeycomb, or enable the \"Create Datasets\" permission for this API key.")
+		}
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		d.pass("Dataset", fmt.Sprintf("API key may not inspect dataset %q, so its existence was not verified", d.Dataset))
+	default:
+		d.fail("Dataset", fmt.Errorf("unexpected HTTP status %d checking dataset %q", resp.StatusCode, d.Dataset),
+			"Retry later, or contact Honeycomb support if the problem persists.")
+	}
+	return d, nil
+}
+
+// datasetPreflightTimeout bounds the time NewExporter spends checking the
+// dataset when configured with WithDatasetPreflight.
+const datasetPreflightTimeout = 10 * time.Second
+
+// WithDatasetPreflight causes NewExporter to check, as Diagnose does, that
+// the target dataset exists or that the API key may create it, returning a
+// *DatasetNotFoundError if not. This catches a mistyped dataset name at
+// startup rather than in the error log. If the check can't be completed, such
+// as when the API server is unreachable, NewExporter reports the reason to
+// the error hook and proceeds.
+func WithDatasetPreflight() ExporterOption {
+	return func(c *exporterConfig) error {
+		c.datasetPreflight = true
+		return nil
+	}
+}
+
+// preflightDataset performs the check requested by WithDatasetPreflight.
+func preflightDataset(config Config, opts []ExporterOption, onError func(error)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), datasetPreflightTimeout)
+	defer cancel()
+	d, err := Diagnose(ctx, config, opts...)
+	if err != nil {
+		return err
+	}
+	for _, c := range d.Checks {
+		if c.Err == nil {
+			continue
+		}
+		var notFound *DatasetNotFoundError
+		if errors.As(c.Err, &notFound) {
+			return c.Err
+		}
+		onError(fmt.Errorf("dataset preflight check %q failed: %w", c.Name, c.Err))
+	}
+	return nil
+}
+
+// responseError returns the error for a failed transmission response,
+// recognizing responses that indicate a missing or unwritable dataset, and
+// those described by a *TransmissionError.
+func responseError(r transmission.Response, dataset string) error {
+	switch r.StatusCode {
+	case http.StatusNotFound, http.StatusForbidden:
+		return &DatasetNotFoundError{Dataset: dataset, StatusCode: r.StatusCode}
+	}
+	if err := transmissionError(r); err != nil {
+		return err
+	}
+	return r.Err
+}
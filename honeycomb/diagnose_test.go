@@ -0,0 +1,208 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDiagnosisServer(authStatus int, authBody string, datasetStatus int) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/1/auth", func(w http.ResponseWriter, req *http.Request) {
+		if key := req.Header.Get("X-Honeycomb-Team"); key != "good" && key != testEnvironmentKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(authStatus)
+		io.WriteString(w, authBody)
+	})
+	mux.HandleFunc("/1/datasets/", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(datasetStatus)
+	})
+	return httptest.NewServer(mux)
+}
+
+const testAuthBody = `{
+	"api_key_access": {"events": true, "createDatasets": false},
+	"environment": {"name": "Production", "slug": "production"},
+	"team": {"name": "Example", "slug": "example"}
+}`
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		description   string
+		apiKey        string
+		datasetStatus int
+		expectOK      bool
+		expectChecks  int
+	}{
+		{"healthy", "good", http.StatusOK, true, 3},
+		{"bad key", "bad", http.StatusOK, false, 2},
+		{"missing dataset", "good", http.StatusNotFound, false, 3},
+		{"dataset not inspectable", "good", http.StatusUnauthorized, true, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert := assert.New(t)
+			server := newDiagnosisServer(http.StatusOK, testAuthBody, test.datasetStatus)
+			defer server.Close()
+
+			d, err := Diagnose(context.Background(),
+				Config{APIKey: test.apiKey},
+				WithAPIURL(server.URL),
+				TargetingDataset("test"))
+			assert.Nil(err)
+			assert.Equal(test.expectOK, d.OK())
+			assert.Len(d.Checks, test.expectChecks)
+			if test.expectOK {
+				assert.Equal("example", d.Team)
+				assert.Equal("production", d.Environment)
+			}
+		})
+	}
+}
+
+func TestDiagnoseDatasetByKeyKind(t *testing.T) {
+	tests := []struct {
+		description   string
+		apiKey        string
+		opts          []ExporterOption
+		expectDataset string
+	}{
+		{"classic", "good", []ExporterOption{TargetingDataset("test"), WithServiceName("checkout")}, "test"},
+		{"environment", testEnvironmentKey, []ExporterOption{TargetingDataset("test"), WithServiceName("checkout")}, "checkout"},
+		{"environment without service", testEnvironmentKey, []ExporterOption{TargetingDataset("test")}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert := assert.New(t)
+			var checked []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if strings.HasPrefix(req.URL.Path, "/1/datasets/") {
+					checked = append(checked, strings.TrimPrefix(req.URL.Path, "/1/datasets/"))
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				io.WriteString(w, testAuthBody)
+			}))
+			defer server.Close()
+
+			d, err := Diagnose(context.Background(), Config{APIKey: test.apiKey},
+				append(test.opts, WithAPIURL(server.URL))...)
+			assert.Nil(err)
+			assert.Equal(test.expectDataset, d.Dataset)
+			assert.Len(d.Checks, 3)
+			if len(test.expectDataset) == 0 {
+				assert.True(d.OK())
+				assert.Empty(checked)
+			} else {
+				assert.False(d.OK())
+				assert.Equal([]string{test.expectDataset}, checked)
+			}
+		})
+	}
+}
+
+func TestDiagnoseUnreachable(t *testing.T) {
+	assert := assert.New(t)
+	server := newDiagnosisServer(http.StatusOK, testAuthBody, http.StatusOK)
+	server.Close()
+
+	d, err := Diagnose(context.Background(), Config{APIKey: "good"}, WithAPIURL(server.URL))
+	assert.Nil(err)
+	assert.False(d.OK())
+	assert.Len(d.Checks, 1)
+	assert.NotEmpty(d.Checks[0].Hint)
+}
+
+func TestDiagnoseInvalidConfig(t *testing.T) {
+	_, err := Diagnose(context.Background(), Config{})
+	assert.Error(t, err)
+}
+
+func TestDatasetPreflight(t *testing.T) {
+	tests := []struct {
+		description   string
+		datasetStatus int
+		closed        bool
+		expectError   bool
+		expectOnError bool
+	}{
+		{"exists", http.StatusOK, false, false, false},
+		{"missing", http.StatusNotFound, false, true, false},
+		{"unreachable", http.StatusOK, true, false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert := assert.New(t)
+			server := newDiagnosisServer(http.StatusOK, testAuthBody, test.datasetStatus)
+			defer server.Close()
+			if test.closed {
+				server.Close()
+			}
+
+			var errs []error
+			exporter, err := NewExporter(Config{APIKey: "good"},
+				WithAPIURL(server.URL),
+				TargetingDataset("test"),
+				WithDatasetPreflight(),
+				CallingOnError(func(err error) {
+					errs = append(errs, err)
+				}),
+				WithSender(&transmission.MockSender{}))
+			if test.expectError {
+				var notFound *DatasetNotFoundError
+				assert.True(errors.As(err, &notFound))
+				assert.Equal("test", notFound.Dataset)
+				assert.Nil(exporter)
+			} else {
+				assert.Nil(err)
+				assert.Nil(exporter.Shutdown(context.Background()))
+			}
+			assert.Equal(test.expectOnError, len(errs) != 0)
+		})
+	}
+}
+
+func TestDatasetPreflightEnvironmentKey(t *testing.T) {
+	assert := assert.New(t)
+	server := newDiagnosisServer(http.StatusOK, testAuthBody, http.StatusNotFound)
+	defer server.Close()
+
+	exporter, err := NewExporter(Config{APIKey: testEnvironmentKey},
+		WithAPIURL(server.URL),
+		TargetingDataset("test"),
+		WithDatasetPreflight(),
+		CallingOnError(func(error) {}),
+		WithSender(&transmission.MockSender{}))
+	assert.Nil(err)
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	_, err = NewExporter(Config{APIKey: testEnvironmentKey},
+		WithAPIURL(server.URL),
+		WithServiceName("checkout"),
+		WithDatasetPreflight(),
+		WithSender(&transmission.MockSender{}))
+	var notFound *DatasetNotFoundError
+	assert.True(errors.As(err, &notFound))
+	assert.Equal("checkout", notFound.Dataset)
+}
+
+func TestResponseError(t *testing.T) {
+	assert := assert.New(t)
+	failure := errors.New("timed out")
+
+	assert.Nil(responseError(transmission.Response{StatusCode: http.StatusAccepted}, "test"))
+	assert.Equal(failure, responseError(transmission.Response{Err: failure}, "test"))
+	assert.Equal(&DatasetNotFoundError{Dataset: "test", StatusCode: http.StatusNotFound},
+		responseError(transmission.Response{StatusCode: http.StatusNotFound}, "test"))
+	assert.Contains(responseError(transmission.Response{StatusCode: http.StatusForbidden}, "test").Error(),
+		"Create Datasets")
+}
@@ -0,0 +1,41 @@
+package honeycomb
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// disabledEnv is the environment variable that, set to "true," disables
+// every exporter.
+const disabledEnv = "HONEYCOMB_DISABLED"
+
+// WithDisabled, given true, causes NewExporter to return an exporter that
+// does nothing: it sends no events, starts no goroutines, and makes no
+// network requests, though NewExporter still checks its options, other than
+// requiring an API key. Setting the HONEYCOMB_DISABLED environment variable
+// to "true" disables exporters in the same way, so that tests and local
+// development can use the same wiring as production without sending
+// anything to Honeycomb. If the option is given more than once, the last
+// applies, though it can't enable an exporter the environment disables.
+func WithDisabled(d bool) ExporterOption {
+	return func(c *exporterConfig) error {
+		c.disabled = d
+		return nil
+	}
+}
+
+// disabledByEnv reports whether the HONEYCOMB_DISABLED environment variable
+// disables exporters.
+func disabledByEnv() (bool, error) {
+	v := strings.TrimSpace(os.Getenv(disabledEnv))
+	if len(v) == 0 {
+		return false, nil
+	}
+	disabled, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, not %q", disabledEnv, v)
+	}
+	return disabled, nil
+}
@@ -0,0 +1,46 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestDisabledExporter(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+
+	exporter, err := NewExporter(Config{}, WithDisabled(true), WithSender(mockHoneycomb))
+	assert.Nil(err, "disabled exporters need no API key")
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "span"}}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	exporter.RunErrorLogger(ctx)
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.Empty(mockHoneycomb.Events())
+
+	_, err = NewExporter(Config{}, WithDisabled(true), WithSampleRate(0))
+	assert.Error(err, "options are still checked")
+
+	// The last option applies.
+	enabled := &transmission.MockSender{}
+	exporter, err = makeTestExporter(enabled, WithDisabled(true), WithDisabled(false))
+	assert.Nil(err)
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "span"}}))
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.Len(enabled.Events(), 1)
+
+	setEnv(t, disabledEnv, "true")
+	exporter, err = makeTestExporter(mockHoneycomb, WithDisabled(false))
+	assert.Nil(err)
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "span"}}))
+	assert.Empty(mockHoneycomb.Events())
+
+	setEnv(t, disabledEnv, "maybe")
+	_, err = makeTestExporter(mockHoneycomb)
+	assert.Error(err)
+}
@@ -0,0 +1,388 @@
+package honeycomb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// diskBufferSuffix is the suffix of the names of the files holding batches
+// of events in a disk buffer's directory.
+const diskBufferSuffix = ".batch"
+
+// bufferedHeaders are the headers of a request sending a batch of events
+// that the disk buffer keeps with it. The API key isn't written to disk.
+var bufferedHeaders = []string{"Content-Type", "Content-Encoding", "User-Agent"}
+
+// WithDiskBuffer causes the exporter to write the batches of events it can't
+// send because Honeycomb is unreachable, with requests failing without a
+// response or with HTTP status 502, 503, or 504, to files in dir, and to send
+// them once a request to Honeycomb succeeds again, even if that is in a later
+// process using the same directory. This lets deployments with intermittent
+// connectivity, such as those at the edge, ride out outages without losing
+// spans. The files hold at most maxBytes; when a batch doesn't fit, the
+// exporter discards the oldest, reporting them to the error hook.
+//
+// The exporter reports the events it writes to disk as accepted to the hook
+// given to CallingOnResponse, and failures to send them later to the error
+// hook, once per batch. The files don't hold the API key; the events are
+// sent with that of the exporter sending them. Only one exporter at a time
+// may use a directory. WithDiskBuffer has no effect on a transmission given
+// to WithSender.
+func WithDiskBuffer(dir string, maxBytes int64) ExporterOption {
+	return func(c *exporterConfig) error {
+		if len(dir) == 0 {
+			return errors.New("disk buffer directory must not be empty")
+		}
+		if maxBytes <= 0 {
+			return errors.New("disk buffer size must be positive")
+		}
+		c.diskBufferDir = dir
+		c.diskBufferMaxBytes = maxBytes
+		return nil
+	}
+}
+
+// bufferedBatch describes the request that sent a batch of events held by a
+// disk buffer, whose body follows it in the batch's file.
+type bufferedBatch struct {
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+}
+
+// bufferedFile is a file holding a batch of events.
+type bufferedFile struct {
+	name string
+	size int64
+}
+
+// diskBuffer holds the batches of events that failed to reach Honeycomb in
+// files, sending them again once requests to Honeycomb succeed.
+type diskBuffer struct {
+	dir      string
+	maxBytes int64
+	base     http.RoundTripper
+	onError  func(error)
+
+	// ctx is canceled when the buffer is closed, interrupting the batch
+	// being sent again.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// files are the files in the directory, oldest first.
+	files     []bufferedFile
+	size      int64
+	seq       uint64
+	replaying bool
+	closed    bool
+}
+
+// openDiskBuffer opens the disk buffer in dir, creating the directory if
+// necessary, and making requests through base.
+func openDiskBuffer(dir string, maxBytes int64, base http.RoundTripper, onError func(error)) (*diskBuffer, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("creating disk buffer: %w", err)
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("opening disk buffer: %w", err)
+	}
+	b := &diskBuffer{
+		dir:      dir,
+		maxBytes: maxBytes,
+		base:     base,
+		onError:  onError,
+	}
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+	// ReadDir sorts the entries by name, and so by age.
+	for _, entry := range entries {
+		switch {
+		case strings.HasSuffix(entry.Name(), diskBufferSuffix):
+			b.files = append(b.files, bufferedFile{name: entry.Name(), size: entry.Size()})
+			b.size += entry.Size()
+		case strings.HasSuffix(entry.Name(), diskBufferSuffix+".tmp"):
+			// A batch left half written by a process that crashed.
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+	return b, nil
+}
+
+func (b *diskBuffer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		r := req.Clone(req.Context())
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		req = r
+	}
+	resp, err := b.base.RoundTrip(req)
+	if !unreachable(req, resp, err) {
+		if err == nil && resp.StatusCode/100 == 2 {
+			b.replay(req.Header.Get("X-Honeycomb-Team"))
+		}
+		return resp, err
+	}
+	n, countErr := countBatchEvents(req.Header, body)
+	if countErr != nil {
+		return resp, err
+	}
+	if spillErr := b.spill(req, body); spillErr != nil {
+		b.onError(fmt.Errorf("writing events to disk buffer: %w", spillErr))
+		return resp, err
+	}
+	if resp != nil {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	return acceptedResponse(req, n), nil
+}
+
+// unreachable reports whether a request failed because Honeycomb couldn't be
+// reached: whether it failed without a response, unless it was canceled, or
+// Honeycomb's proxy or load balancer responded in its place. Requests timing
+// out, as those to a network that drops them do once libhoney's client gives
+// up, count as unreachable.
+func unreachable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(req.Context().Err(), context.Canceled)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// resendLater reports whether a response with the given status to a batch
+// sent from the buffer means it should be kept to send again later: when
+// Honeycomb is throttling requests, failing, or rejecting the API key, which
+// may be fixed by the next exporter using the buffer.
+func resendLater(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusUnauthorized || status >= 500
+}
+
+var (
+	zstdDecoderOnce sync.Once
+	zstdDecoder     *zstd.Decoder
+	zstdDecoderErr  error
+)
+
+// countBatchEvents returns the number of events in the body of a request
+// sending a batch of them.
+func countBatchEvents(header http.Header, body []byte) (int, error) {
+	if header.Get("Content-Type") != "application/json" {
+		return 0, fmt.Errorf("can't count events encoded as %q", header.Get("Content-Type"))
+	}
+	if header.Get("Content-Encoding") == "zstd" {
+		zstdDecoderOnce.Do(func() {
+			zstdDecoder, zstdDecoderErr = zstd.NewReader(nil)
+		})
+		if zstdDecoderErr != nil {
+			return 0, zstdDecoderErr
+		}
+		var err error
+		if body, err = zstdDecoder.DecodeAll(body, nil); err != nil {
+			return 0, err
+		}
+	}
+	var events []json.RawMessage
+	if err := json.Unmarshal(body, &events); err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// acceptedResponse returns a response accepting the given number of events,
+// as Honeycomb's would.
+func acceptedResponse(req *http.Request, n int) *http.Response {
+	statuses := make([]string, n)
+	for i := range statuses {
+		statuses[i] = `{"status":202}`
+	}
+	body := "[" + strings.Join(statuses, ",") + "]"
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// spill writes the batch of events sent by req to a file, discarding the
+// oldest files to make room for it.
+func (b *diskBuffer) spill(req *http.Request, body []byte) error {
+	header := http.Header{}
+	for _, name := range bufferedHeaders {
+		if value := req.Header.Get(name); len(value) != 0 {
+			header.Set(name, value)
+		}
+	}
+	meta, err := json.Marshal(bufferedBatch{URL: req.URL.String(), Header: header})
+	if err != nil {
+		return err
+	}
+	data := append(append(meta, '\n'), body...)
+	size := int64(len(data))
+	if size > b.maxBytes {
+		return fmt.Errorf("batch of %d bytes exceeds the buffer's size", size)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return errors.New("disk buffer is closed")
+	}
+	discarded := 0
+	for b.size+size > b.maxBytes && len(b.files) != 0 {
+		oldest := b.files[0]
+		b.files = b.files[1:]
+		b.size -= oldest.size
+		os.Remove(filepath.Join(b.dir, oldest.name))
+		discarded++
+	}
+	if discarded != 0 {
+		b.onError(fmt.Errorf("disk buffer is full; discarded %d batches of events", discarded))
+	}
+	name := fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), b.seq%1000000, diskBufferSuffix)
+	b.seq++
+	path := filepath.Join(b.dir, name)
+	if err := ioutil.WriteFile(path+".tmp", data, 0600); err != nil {
+		os.Remove(path + ".tmp")
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		os.Remove(path + ".tmp")
+		return err
+	}
+	b.files = append(b.files, bufferedFile{name: name, size: size})
+	b.size += size
+	return nil
+}
+
+// replay starts sending the buffered batches of events again, with the given
+// API key, unless they are already being sent.
+func (b *diskBuffer) replay(apiKey string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.replaying || b.closed || len(b.files) == 0 {
+		return
+	}
+	b.replaying = true
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for {
+			b.mu.Lock()
+			if b.closed || len(b.files) == 0 {
+				b.replaying = false
+				b.mu.Unlock()
+				return
+			}
+			f := b.files[0]
+			b.mu.Unlock()
+			if !b.resend(f, apiKey) {
+				b.mu.Lock()
+				b.replaying = false
+				b.mu.Unlock()
+				return
+			}
+		}
+	}()
+}
+
+// resend sends the batch of events in a file again, removing the file unless
+// Honeycomb is still unreachable or asks for it to be sent again later. It
+// reports whether to go on to the next.
+func (b *diskBuffer) resend(f bufferedFile, apiKey string) bool {
+	data, err := ioutil.ReadFile(filepath.Join(b.dir, f.name))
+	if err != nil {
+		// The file was discarded to make room for another.
+		b.forget(f)
+		return true
+	}
+	var meta bufferedBatch
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 || json.Unmarshal(data[:i], &meta) != nil {
+		b.onError(fmt.Errorf("discarding corrupt disk buffer file %s", f.name))
+		b.forget(f)
+		return true
+	}
+	req, err := http.NewRequestWithContext(b.ctx, http.MethodPost, meta.URL, bytes.NewReader(data[i+1:]))
+	if err != nil {
+		b.onError(fmt.Errorf("discarding disk buffer file %s: %w", f.name, err))
+		b.forget(f)
+		return true
+	}
+	for name, values := range meta.Header {
+		req.Header[name] = values
+	}
+	req.Header.Set("X-Honeycomb-Team", apiKey)
+	resp, err := b.base.RoundTrip(req)
+	if unreachable(req, resp, err) || b.ctx.Err() != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return false
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resendLater(resp.StatusCode) {
+		b.onError(fmt.Errorf("keeping events in disk buffer: got HTTP status %d", resp.StatusCode))
+		return false
+	}
+	if resp.StatusCode != http.StatusOK {
+		b.onError(fmt.Errorf("resending events from disk buffer: got HTTP status %d", resp.StatusCode))
+	}
+	b.forget(f)
+	return true
+}
+
+// forget removes a file from the buffer.
+func (b *diskBuffer) forget(f bufferedFile) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, g := range b.files {
+		if g.name == f.name {
+			b.files = append(b.files[:i], b.files[i+1:]...)
+			b.size -= g.size
+			break
+		}
+	}
+	os.Remove(filepath.Join(b.dir, f.name))
+}
+
+// close stops sending buffered batches of events, leaving them for a later
+// exporter using the same directory.
+func (b *diskBuffer) close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.cancel()
+	b.wg.Wait()
+}
@@ -0,0 +1,290 @@
+package honeycomb
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// outageServer accepts events unless down, recording the requests it
+// accepts.
+type outageServer struct {
+	down int32
+
+	mu       sync.Mutex
+	accepted [][]byte
+	keys     []string
+}
+
+func (s *outageServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	if atomic.LoadInt32(&s.down) != 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	s.mu.Lock()
+	s.accepted = append(s.accepted, body)
+	s.keys = append(s.keys, r.Header.Get("X-Honeycomb-Team"))
+	s.mu.Unlock()
+	io.WriteString(w, `[{"status": 202}]`)
+}
+
+func bufferedFiles(t *testing.T, dir string) []string {
+	names, err := filepath.Glob(filepath.Join(dir, "*"+diskBufferSuffix))
+	assert.Nil(t, err)
+	return names
+}
+
+func TestDiskBuffer(t *testing.T) {
+	assert := assert.New(t)
+	outage := &outageServer{down: 1}
+	server := httptest.NewServer(outage)
+	defer server.Close()
+	tmp, err := ioutil.TempDir("", "honeycomb-buffer")
+	if !assert.Nil(err) {
+		return
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "buffer")
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var statuses []int
+	var errs []error
+	newExporter := func(apiKey string) *Exporter {
+		exporter, err := NewExporter(Config{APIKey: apiKey},
+			TargetingDataset("test"),
+			WithAPIURL(server.URL),
+			WithDiskBuffer(dir, 1<<20),
+			CallingOnResponse(func(r Response) {
+				mu.Lock()
+				statuses = append(statuses, r.StatusCode)
+				mu.Unlock()
+			}),
+			CallingOnError(func(err error) {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}))
+		assert.Nil(err)
+		return exporter
+	}
+
+	// Batches failing during an outage are kept on disk, and outlive the
+	// exporter.
+	exporter := newExporter("first")
+	for i := 0; i < 2; i++ {
+		assert.Nil(exporter.ExportSpans(ctx, []*trace.SpanSnapshot{{Name: "buffered"}}))
+		assert.Nil(exporter.ForceFlush(ctx))
+	}
+	assert.Nil(exporter.Shutdown(ctx))
+	assert.Len(bufferedFiles(t, dir), 2)
+	assert.Empty(outage.accepted)
+	assert.Equal([]int{http.StatusAccepted, http.StatusAccepted}, statuses)
+	assert.Empty(errs)
+
+	// Once Honeycomb is reachable, they are sent again.
+	atomic.StoreInt32(&outage.down, 0)
+	exporter = newExporter("second")
+	assert.Nil(exporter.ExportSpans(ctx, []*trace.SpanSnapshot{{Name: "sent"}}))
+	assert.Nil(exporter.ForceFlush(ctx))
+	assert.Eventually(func() bool {
+		return len(bufferedFiles(t, dir)) == 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Nil(exporter.Shutdown(ctx))
+	assert.Empty(errs)
+	outage.mu.Lock()
+	defer outage.mu.Unlock()
+	assert.Len(outage.accepted, 3)
+	assert.Equal([]string{"second", "second", "second"}, outage.keys)
+}
+
+func TestDiskBufferDiscardsOldest(t *testing.T) {
+	assert := assert.New(t)
+	outage := &outageServer{down: 1}
+	server := httptest.NewServer(outage)
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "honeycomb-buffer")
+	if !assert.Nil(err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	var errs []error
+	var mu sync.Mutex
+	exporter, err := NewExporter(Config{APIKey: "overridden"},
+		TargetingDataset("test"),
+		WithAPIURL(server.URL),
+		WithDiskBuffer(dir, 600),
+		CallingOnError(func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}))
+	if !assert.Nil(err) {
+		return
+	}
+	for _, name := range []string{"first", "second"} {
+		assert.Nil(exporter.ExportSpans(ctx, []*trace.SpanSnapshot{{Name: name}}))
+		assert.Nil(exporter.ForceFlush(ctx))
+	}
+	assert.Nil(exporter.Shutdown(ctx))
+	files := bufferedFiles(t, dir)
+	if assert.Len(files, 1) {
+		data, err := ioutil.ReadFile(files[0])
+		assert.Nil(err)
+		decoder, err := zstd.NewReader(nil)
+		assert.Nil(err)
+		body, err := decoder.DecodeAll(data[bytes.IndexByte(data, '\n')+1:], nil)
+		assert.Nil(err)
+		assert.Contains(string(body), `"name":"second"`)
+	}
+	if assert.Len(errs, 1) {
+		assert.Contains(errs[0].Error(), "discarded 1 batches")
+	}
+}
+
+func TestDiskBufferKeepsThrottled(t *testing.T) {
+	assert := assert.New(t)
+	// The server fails while down, then accepts the given number of requests
+	// before throttling the rest.
+	var down, accepts, requests int32 = 1, 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		if atomic.LoadInt32(&down) != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		atomic.AddInt32(&requests, 1)
+		if atomic.AddInt32(&accepts, -1) < 0 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		io.WriteString(w, `[{"status": 202}]`)
+	}))
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "honeycomb-buffer")
+	if !assert.Nil(err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var errs []error
+	exporter, err := NewExporter(Config{APIKey: "throttled"},
+		TargetingDataset("test"),
+		WithAPIURL(server.URL),
+		WithDiskBuffer(dir, 1<<20),
+		CallingOnError(func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}))
+	if !assert.Nil(err) {
+		return
+	}
+	export := func() {
+		assert.Nil(exporter.ExportSpans(ctx, []*trace.SpanSnapshot{{Name: "span"}}))
+		assert.Nil(exporter.ForceFlush(ctx))
+	}
+	export()
+	assert.Len(bufferedFiles(t, dir), 1)
+
+	// A batch throttled as it's sent again stays on disk.
+	atomic.StoreInt32(&down, 0)
+	atomic.StoreInt32(&accepts, 1)
+	export()
+	assert.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.EqualValues(2, atomic.LoadInt32(&requests))
+	assert.Len(bufferedFiles(t, dir), 1)
+
+	// A throttled batch doesn't start sending them again either.
+	export()
+	time.Sleep(20 * time.Millisecond)
+	assert.EqualValues(3, atomic.LoadInt32(&requests))
+	assert.Len(bufferedFiles(t, dir), 1)
+
+	atomic.StoreInt32(&accepts, 2)
+	export()
+	assert.Eventually(func() bool {
+		return len(bufferedFiles(t, dir)) == 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Nil(exporter.Shutdown(ctx))
+	assert.EqualValues(5, atomic.LoadInt32(&requests))
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.NotEmpty(errs) {
+		assert.Contains(errs[0].Error(), "HTTP status 429")
+	}
+}
+
+func TestDiskBufferSpillsTimedOutRetries(t *testing.T) {
+	assert := assert.New(t)
+	// The server hangs like a network dropping requests, until the test ends.
+	hung := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hung
+	}))
+	defer server.Close()
+	defer close(hung)
+	dir, err := ioutil.TempDir("", "honeycomb-buffer")
+	if !assert.Nil(err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	retry := &retryTransport{base: http.DefaultTransport, policy: RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}}
+	b, err := openDiskBuffer(dir, 1<<20, retry, func(err error) {
+		t.Error(err)
+	})
+	if !assert.Nil(err) {
+		return
+	}
+	defer b.close()
+
+	// libhoney's client sets a deadline on its requests, which passes while
+	// the first attempt hangs.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/1/batch/test", strings.NewReader(`[{"data": {}}]`))
+	if !assert.Nil(err) {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.RoundTrip(req)
+	if assert.Nil(err) {
+		assert.Equal(http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+	}
+	assert.Len(bufferedFiles(t, dir), 1)
+}
+
+func TestWithDiskBufferInvalid(t *testing.T) {
+	assert.Error(t, ValidateOptions(WithDiskBuffer("", 1)))
+	assert.Error(t, ValidateOptions(WithDiskBuffer("buffer", 0)))
+}
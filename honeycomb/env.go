@@ -0,0 +1,33 @@
+package honeycomb
+
+import (
+	"os"
+	"strings"
+)
+
+// The environment variables from which exporters take the settings not
+// given by their configuration and options.
+const (
+	apiKeyEnv      = "HONEYCOMB_API_KEY"
+	datasetEnv     = "HONEYCOMB_DATASET"
+	apiEndpointEnv = "HONEYCOMB_API_ENDPOINT"
+	serviceNameEnv = "HONEYCOMB_SERVICE_NAME"
+)
+
+// envDefault returns the value of an environment variable if s is empty,
+// or else s.
+func envDefault(s, name string) string {
+	if len(s) != 0 {
+		return s
+	}
+	return strings.TrimSpace(os.Getenv(name))
+}
+
+// applyEnvironment fills in the dataset, API URL, and service name from the
+// HONEYCOMB_DATASET, HONEYCOMB_API_ENDPOINT, and HONEYCOMB_SERVICE_NAME
+// environment variables, if set, unless given by options.
+func (c *exporterConfig) applyEnvironment() {
+	c.dataset = envDefault(c.dataset, datasetEnv)
+	c.apiURL = envDefault(c.apiURL, apiEndpointEnv)
+	c.serviceName = envDefault(c.serviceName, serviceNameEnv)
+}
@@ -0,0 +1,52 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestExporterSettingsFromEnvironment(t *testing.T) {
+	assert := assert.New(t)
+	setEnv(t, apiKeyEnv, "from-env")
+	setEnv(t, datasetEnv, "env-dataset")
+	setEnv(t, apiEndpointEnv, "https://api.example.com")
+	setEnv(t, serviceNameEnv, "env-service")
+
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := NewExporter(Config{}, WithSender(mockHoneycomb))
+	assert.Nil(err)
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "span"}}))
+	if assert.Len(mockHoneycomb.Events(), 1) {
+		ev := mockHoneycomb.Events()[0]
+		assert.Equal("from-env", ev.APIKey)
+		assert.Equal("env-dataset", ev.Dataset)
+		assert.Equal("https://api.example.com", ev.APIHost)
+		assert.Equal("env-service", ev.Data["service_name"])
+	}
+
+	// Configuration and options take precedence.
+	mockHoneycomb = &transmission.MockSender{}
+	exporter, err = NewExporter(Config{APIKey: "from-config"},
+		WithSender(mockHoneycomb),
+		TargetingDataset("option-dataset"),
+		WithAPIURL("https://api.honeycomb.io"),
+		WithServiceName("option-service"))
+	assert.Nil(err)
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "span"}}))
+	if assert.Len(mockHoneycomb.Events(), 1) {
+		ev := mockHoneycomb.Events()[0]
+		assert.Equal("from-config", ev.APIKey)
+		assert.Equal("option-dataset", ev.Dataset)
+		assert.Equal("https://api.honeycomb.io", ev.APIHost)
+		assert.Equal("option-service", ev.Data["service_name"])
+	}
+
+	setEnv(t, apiKeyEnv, "")
+	_, err = NewExporter(Config{}, WithSender(mockHoneycomb))
+	assert.Error(err)
+}
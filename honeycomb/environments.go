@@ -0,0 +1,67 @@
+package honeycomb
+
+import (
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// unknownServiceDataset is the dataset to which an exporter with an
+// Environments & Services API key sends the events of spans without a
+// service name, as Honeycomb does for OTLP.
+const unknownServiceDataset = "unknown_service"
+
+var (
+	environmentKeyPattern       = regexp.MustCompile(`^[a-zA-Z0-9]{22}$`)
+	environmentIngestKeyPattern = regexp.MustCompile(`^hc[a-z]ik_[a-z0-9]{58}$`)
+)
+
+// IsClassicKey reports whether an API key belongs to a team of Honeycomb
+// Classic, judging by its format, rather than to an environment of Honeycomb
+// Environments & Services. Keys of unrecognized format, including empty ones,
+// are taken to be Classic.
+//
+// An exporter with an Environments & Services key sends the events for each
+// span to a dataset named after its service, as Honeycomb does for data sent
+// by OTLP, rather than to the dataset given by TargetingDataset. Spans
+// without a service name, or with one beginning with "unknown_service," go
+// to the "unknown_service" dataset. If the exporter is configured with
+// WithKeyVerification, the kind of key is decided by whether Honeycomb
+// reports that it belongs to an environment.
+func IsClassicKey(key string) bool {
+	return !environmentKeyPattern.MatchString(key) && !environmentIngestKeyPattern.MatchString(key)
+}
+
+// spanDataset returns the dataset to which the events of a span go by
+// default, or false if they go to the exporter's dataset.
+func (e *Exporter) spanDataset(data *trace.SpanSnapshot) (string, bool) {
+	if r := e.routingRule(data); r != nil && len(r.Dataset) != 0 {
+		return r.Dataset, true
+	}
+	if e.datasetTemplate != nil {
+		if dataset, ok := e.datasetTemplate.resolve(data); ok {
+			return dataset, true
+		}
+	}
+	if !e.environmentKey {
+		return "", false
+	}
+	name := spanServiceName(data, e.serviceName)
+	if e.serviceNamePrecedence != 0 {
+		// exportSpan has already reported any conflict.
+		name, _ = e.resolveServiceName(data)
+	}
+	return serviceDataset(name), true
+}
+
+// serviceDataset returns the dataset to which an exporter with an
+// Environments & Services API key sends the events of spans from the named
+// service.
+func serviceDataset(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 || strings.HasPrefix(name, unknownServiceDataset) {
+		return unknownServiceDataset
+	}
+	return name
+}
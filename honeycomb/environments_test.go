@@ -0,0 +1,104 @@
+package honeycomb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+const (
+	testClassicKey     = "0123456789abcdef0123456789abcdef"
+	testEnvironmentKey = "abcdefghij0123456789AB"
+)
+
+func TestIsClassicKey(t *testing.T) {
+	for key, classic := range map[string]bool{
+		"":                                  true,
+		"overridden":                        true,
+		testClassicKey:                      true,
+		testEnvironmentKey:                  false,
+		"hcaic_" + strings.Repeat("a1", 29): true,
+		"hcaik_" + strings.Repeat("a1", 29): false,
+		"hcaik_" + strings.Repeat("A1", 29): true,
+	} {
+		assert.Equal(t, classic, IsClassicKey(key), key)
+	}
+}
+
+func TestEnvironmentKeyDatasets(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	var errs []error
+	exporter, err := NewExporter(Config{APIKey: testEnvironmentKey},
+		TargetingDataset("ignored"),
+		WithSender(mockHoneycomb),
+		CallingOnError(func(err error) {
+			errs = append(errs, err)
+		}))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Len(errs, 1)
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{
+		{Name: "checkout", Resource: resource.NewWithAttributes(semconv.ServiceNameKey.String(" checkout "))},
+		{Name: "unknown", Resource: resource.NewWithAttributes(semconv.ServiceNameKey.String("unknown_service:go"))},
+		{Name: "none"},
+	}))
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	events := mockHoneycomb.Events()
+	if assert.Len(events, 3) {
+		assert.Equal("checkout", events[0].Dataset)
+		assert.Equal(unknownServiceDataset, events[1].Dataset)
+		assert.Equal(unknownServiceDataset, events[2].Dataset)
+	}
+
+	// A configured service name applies to spans whose resources lack one.
+	mockHoneycomb = &transmission.MockSender{}
+	exporter, err = NewExporter(Config{APIKey: testEnvironmentKey},
+		WithServiceName("inventory"),
+		WithSender(mockHoneycomb))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "none"}}))
+	assert.Nil(exporter.Shutdown(context.Background()))
+	if events := mockHoneycomb.Events(); assert.Len(events, 1) {
+		assert.Equal("inventory", events[0].Dataset)
+	}
+}
+
+func TestEnvironmentKeyVerified(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"team": {"slug": "acme"}, "environment": {"slug": "prod"}}`)
+	}))
+	defer server.Close()
+
+	// Honeycomb's answer overrides the key's format.
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := NewExporter(Config{APIKey: testClassicKey},
+		WithAPIURL(server.URL),
+		WithKeyVerification(),
+		WithSender(mockHoneycomb))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{
+		{Name: "checkout", Resource: resource.NewWithAttributes(semconv.ServiceNameKey.String("checkout"))},
+	}))
+	assert.Nil(exporter.Shutdown(context.Background()))
+	if events := mockHoneycomb.Events(); assert.Len(events, 1) {
+		assert.Equal("checkout", events[0].Dataset)
+	}
+}
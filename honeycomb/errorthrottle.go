@@ -0,0 +1,134 @@
+package honeycomb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WithErrorThrottle limits the errors passed to the error hook to one of each
+// class per interval, so that an outage doesn't produce an error for every
+// span. Errors are classed by their reason, such as ErrUnauthorized or a
+// missing dataset, ignoring the span for which each event was sent, or else
+// by their message. The first error of a class passes at once, and those
+// following within the interval are counted; the next to pass afterward is
+// wrapped in a *ThrottledError reporting how many were withheld. Shutdown
+// reports the errors still withheld the same way.
+func WithErrorThrottle(interval time.Duration) ExporterOption {
+	return func(c *exporterConfig) error {
+		if interval <= 0 {
+			return errors.New("error throttle interval must be positive")
+		}
+		c.errorThrottleInterval = interval
+		return nil
+	}
+}
+
+// ThrottledError is passed to the error hook by an exporter configured with
+// WithErrorThrottle in place of an error similar to others it withheld.
+// Errors.Is and errors.As see through it to that error.
+type ThrottledError struct {
+	// Err is the error passed to the hook.
+	Err error
+	// Suppressed is the number of similar errors withheld since the last
+	// one passed to the hook.
+	Suppressed int
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("%v (%d similar errors suppressed)", e.Err, e.Suppressed)
+}
+
+// Unwrap returns the error passed to the hook.
+func (e *ThrottledError) Unwrap() error {
+	return e.Err
+}
+
+// errorClass returns the key by which errors are throttled.
+func errorClass(err error) string {
+	var spanErr *SpanError
+	if errors.As(err, &spanErr) {
+		err = spanErr.Err
+	}
+	var txErr *TransmissionError
+	if errors.As(err, &txErr) {
+		return txErr.Reason.Error()
+	}
+	var notFound *DatasetNotFoundError
+	if errors.As(err, &notFound) {
+		return "dataset not found: " + notFound.Dataset
+	}
+	return err.Error()
+}
+
+// withheldErrors counts the errors of one class withheld since one passed.
+type withheldErrors struct {
+	passed time.Time
+	count  int
+	last   error
+}
+
+// errorThrottle passes errors on to the error hook, at most one of each
+// class per interval.
+type errorThrottle struct {
+	interval time.Duration
+	onError  func(error)
+	now      func() time.Time
+
+	mu      sync.Mutex
+	classes map[string]*withheldErrors
+}
+
+func newErrorThrottle(interval time.Duration, onError func(error)) *errorThrottle {
+	return &errorThrottle{
+		interval: interval,
+		onError:  onError,
+		now:      time.Now,
+		classes:  make(map[string]*withheldErrors),
+	}
+}
+
+func (t *errorThrottle) handle(err error) {
+	class := errorClass(err)
+	now := t.now()
+	t.mu.Lock()
+	w, ok := t.classes[class]
+	if ok && now.Sub(w.passed) < t.interval {
+		w.count++
+		w.last = err
+		t.mu.Unlock()
+		return
+	}
+	suppressed := 0
+	if ok {
+		suppressed = w.count
+	}
+	t.classes[class] = &withheldErrors{passed: now}
+	t.mu.Unlock()
+	if suppressed > 0 {
+		err = &ThrottledError{Err: err, Suppressed: suppressed}
+	}
+	t.onError(err)
+}
+
+// flush passes on the last error of each class withheld, reporting how many
+// others were.
+func (t *errorThrottle) flush() {
+	t.mu.Lock()
+	var withheld []*withheldErrors
+	for class, w := range t.classes {
+		if w.count > 0 {
+			withheld = append(withheld, w)
+		}
+		delete(t.classes, class)
+	}
+	t.mu.Unlock()
+	for _, w := range withheld {
+		err := w.last
+		if w.count > 1 {
+			err = &ThrottledError{Err: err, Suppressed: w.count - 1}
+		}
+		t.onError(err)
+	}
+}
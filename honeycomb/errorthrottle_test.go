@@ -0,0 +1,95 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestErrorThrottle(t *testing.T) {
+	assert := assert.New(t)
+	var passed []error
+	throttle := newErrorThrottle(time.Minute, func(err error) {
+		passed = append(passed, err)
+	})
+	now := time.Unix(1600000000, 0)
+	throttle.now = func() time.Time { return now }
+
+	unauthorized := func(span byte) error {
+		return &SpanError{
+			Span: SpanReference{SpanID: apitrace.SpanID{span}},
+			Err:  &TransmissionError{Reason: ErrUnauthorized, StatusCode: http.StatusUnauthorized},
+		}
+	}
+	other := errors.New("other")
+	throttle.handle(unauthorized(1))
+	throttle.handle(unauthorized(2))
+	throttle.handle(other)
+	throttle.handle(unauthorized(3))
+	if !assert.Len(passed, 2) {
+		return
+	}
+	assert.Equal(unauthorized(1), passed[0])
+	assert.Equal(other, passed[1])
+
+	now = now.Add(time.Minute)
+	throttle.handle(unauthorized(4))
+	if assert.Len(passed, 3) {
+		assert.Equal(&ThrottledError{Err: unauthorized(4), Suppressed: 2}, passed[2])
+		assert.True(errors.Is(passed[2], ErrUnauthorized))
+	}
+
+	throttle.handle(unauthorized(5))
+	throttle.handle(unauthorized(6))
+	throttle.handle(other)
+	throttle.flush()
+	if assert.Len(passed, 5) {
+		assert.Equal(other, passed[3])
+		assert.Equal(&ThrottledError{Err: unauthorized(6), Suppressed: 1}, passed[4])
+	}
+}
+
+func TestWithErrorThrottle(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{BlockOnResponses: true}
+	var errs []error
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithErrorThrottle(time.Hour),
+		CallingOnError(func(err error) {
+			errs = append(errs, err)
+		}))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Nil(exporter.Start(context.Background()))
+	spans := make([]*trace.SpanSnapshot, 3)
+	for i := range spans {
+		spans[i] = &trace.SpanSnapshot{SpanContext: apitrace.SpanContext{SpanID: apitrace.SpanID{byte(i + 1)}}}
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), spans))
+	for _, ev := range mockHoneycomb.Events() {
+		mockHoneycomb.SendResponse(transmission.Response{
+			StatusCode: http.StatusUnauthorized,
+			Err:        errors.New("got unexpected HTTP status 401"),
+			Metadata:   ev.Metadata,
+		})
+	}
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	if assert.Len(errs, 2) {
+		assert.True(errors.Is(errs[0], ErrUnauthorized))
+		var throttled *ThrottledError
+		if assert.True(errors.As(errs[1], &throttled)) {
+			assert.Equal(1, throttled.Suppressed)
+		}
+	}
+	assert.Error(ValidateOptions(WithErrorThrottle(0)))
+}
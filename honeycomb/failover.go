@@ -0,0 +1,212 @@
+package honeycomb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of the fields of FailoverPolicy left zero.
+const (
+	defaultFailoverAfter         = 30 * time.Second
+	defaultFailoverProbeInterval = 30 * time.Second
+)
+
+// FailoverPolicy configures WithFailover.
+type FailoverPolicy struct {
+	// APIURL is the address of the secondary API server, such as
+	// "https://api.honeycomb.io/" when the primary is a Refinery cluster.
+	APIURL string
+	// After is how long the primary API server must have been unreachable
+	// for the exporter to fail over. If zero, it is 30 seconds.
+	After time.Duration
+	// ProbeInterval is the interval at which the exporter, while failed
+	// over, sends one batch of events to the primary API server as a probe,
+	// failing back if it's reachable. If zero, it is 30 seconds.
+	ProbeInterval time.Duration
+	// OnFailover, if not nil, is called with true when the exporter fails
+	// over and with false when it fails back. It must not block, as it holds
+	// up the exporter's requests.
+	OnFailover func(failedOver bool)
+}
+
+// WithFailover causes the exporter to send events to a secondary API server
+// once the primary, given to WithAPIURL, has been unreachable for as long as
+// policy's After, such as to send directly to Honeycomb while a Refinery
+// cluster is down. The primary is unreachable while its requests fail
+// without a response or with HTTP status 502, 503, or 504. While failed
+// over, the exporter sends one batch per probe interval to the primary,
+// failing back if it responds, or else sending the batch to the secondary.
+// The exporter reports whether it's failed over from FailedOver and to
+// policy's OnFailover.
+//
+// Both servers receive the exporter's API key, and both must be in the
+// region given to WithRequiredRegion, if any. WithFailover has no effect on
+// a transmission given to WithSender.
+func WithFailover(policy FailoverPolicy) ExporterOption {
+	return func(c *exporterConfig) error {
+		u, err := url.Parse(policy.APIURL)
+		if err != nil {
+			return fmt.Errorf("invalid failover API URL: %w", err)
+		}
+		if len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return fmt.Errorf("failover API URL %q must be absolute", policy.APIURL)
+		}
+		if policy.After < 0 {
+			return errors.New("failover period must not be negative")
+		}
+		if policy.ProbeInterval < 0 {
+			return errors.New("failover probe interval must not be negative")
+		}
+		if policy.After == 0 {
+			policy.After = defaultFailoverAfter
+		}
+		if policy.ProbeInterval == 0 {
+			policy.ProbeInterval = defaultFailoverProbeInterval
+		}
+		c.failover = &policy
+		return nil
+	}
+}
+
+// failoverTransport sends requests to the secondary API server while the
+// primary is unreachable.
+type failoverTransport struct {
+	base      http.RoundTripper
+	policy    FailoverPolicy
+	secondary *url.URL
+	// failedOver is 1 while requests go to the secondary, read without
+	// holding mu.
+	failedOver int32
+
+	mu sync.Mutex
+	// downSince is the time of the first of the primary's latest run of
+	// failures, or zero if its latest request succeeded.
+	downSince time.Time
+	// probed is the time at which a request last went to the primary as a
+	// probe.
+	probed time.Time
+}
+
+func newFailoverTransport(base http.RoundTripper, policy FailoverPolicy) *failoverTransport {
+	secondary, _ := url.Parse(policy.APIURL)
+	return &failoverTransport{
+		base:      base,
+		policy:    policy,
+		secondary: secondary,
+	}
+}
+
+// usePrimary reports whether to send a request at now to the primary,
+// letting a probe through once per probe interval while failed over.
+func (t *failoverTransport) usePrimary(now time.Time) bool {
+	if atomic.LoadInt32(&t.failedOver) == 0 {
+		return true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if now.Sub(t.probed) < t.policy.ProbeInterval {
+		return false
+	}
+	t.probed = now
+	return true
+}
+
+// setFailedOver records whether requests go to the secondary. mu must be
+// held.
+func (t *failoverTransport) setFailedOver(failedOver bool) {
+	var v int32
+	if failedOver {
+		v = 1
+	}
+	atomic.StoreInt32(&t.failedOver, v)
+	if t.policy.OnFailover != nil {
+		t.policy.OnFailover(failedOver)
+	}
+}
+
+// record accounts for the outcome of a request sent to the primary at now,
+// reporting whether requests go to the secondary afterward.
+func (t *failoverTransport) record(reachable bool, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	failedOver := atomic.LoadInt32(&t.failedOver) != 0
+	if reachable {
+		t.downSince = time.Time{}
+		if failedOver {
+			t.setFailedOver(false)
+		}
+		return false
+	}
+	if failedOver {
+		return true
+	}
+	if t.downSince.IsZero() {
+		t.downSince = now
+	}
+	if now.Sub(t.downSince) < t.policy.After {
+		return false
+	}
+	t.probed = now
+	t.setFailedOver(true)
+	return true
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// libhoney's request bodies can't be read twice, so keep a copy for
+	// sending to the secondary.
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !t.usePrimary(time.Now()) {
+		return t.base.RoundTrip(t.redirect(req, body, t.secondary))
+	}
+	resp, err := t.base.RoundTrip(t.redirect(req, body, nil))
+	if req.Context().Err() != nil || !t.record(!unreachable(req, resp, err), time.Now()) {
+		return resp, err
+	}
+	if resp != nil {
+		// Drain the body so that the connection may be reused.
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	return t.base.RoundTrip(t.redirect(req, body, t.secondary))
+}
+
+// redirect returns a copy of req with the given body, sent to server if not
+// nil.
+func (t *failoverTransport) redirect(req *http.Request, body []byte, server *url.URL) *http.Request {
+	r := req.Clone(req.Context())
+	if body != nil {
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+	}
+	if server != nil {
+		r.URL.Scheme = server.Scheme
+		r.URL.Host = server.Host
+		r.URL.Path = strings.TrimSuffix(server.Path, "/") + r.URL.Path
+		r.URL.RawPath = ""
+		r.Host = ""
+	}
+	return r
+}
+
+// FailedOver reports whether the exporter is sending events to the secondary
+// API server given to WithFailover.
+func (e *Exporter) FailedOver() bool {
+	return e.failover != nil && atomic.LoadInt32(&e.failover.failedOver) != 0
+}
@@ -0,0 +1,140 @@
+package honeycomb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestFailoverStates(t *testing.T) {
+	assert := assert.New(t)
+	var states []bool
+	f := newFailoverTransport(nil, FailoverPolicy{
+		APIURL:        "https://api.honeycomb.io/",
+		After:         time.Minute,
+		ProbeInterval: time.Minute,
+		OnFailover: func(failedOver bool) {
+			states = append(states, failedOver)
+		},
+	})
+	now := time.Now()
+
+	assert.False(f.record(false, now))
+	assert.False(f.record(true, now.Add(time.Minute)))
+	assert.False(f.record(false, now.Add(2*time.Minute)))
+	assert.True(f.usePrimary(now.Add(2 * time.Minute)))
+	assert.True(f.record(false, now.Add(3*time.Minute)))
+	assert.False(f.usePrimary(now.Add(3*time.Minute + time.Second)))
+
+	assert.True(f.usePrimary(now.Add(4 * time.Minute)))
+	assert.False(f.usePrimary(now.Add(4*time.Minute + time.Second)))
+	assert.True(f.record(false, now.Add(4*time.Minute+time.Second)))
+	assert.True(f.usePrimary(now.Add(5 * time.Minute)))
+	assert.False(f.record(true, now.Add(5*time.Minute)))
+	assert.True(f.usePrimary(now.Add(5 * time.Minute)))
+
+	assert.Equal([]bool{true, false}, states)
+}
+
+func TestFailover(t *testing.T) {
+	assert := assert.New(t)
+	var primaryRequests, secondaryRequests, down int32 = 0, 0, 1
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryRequests, 1)
+		if atomic.LoadInt32(&down) != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `[{"status": 202}]`)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&secondaryRequests, 1)
+		assert.Equal("/1/batch/test", r.URL.Path)
+		io.WriteString(w, `[{"status": 202}]`)
+	}))
+	defer secondary.Close()
+
+	var mu sync.Mutex
+	var states []bool
+	var errs []error
+	exporter, err := NewExporter(Config{APIKey: "failing-over"},
+		TargetingDataset("test"),
+		WithAPIURL(primary.URL),
+		WithFailover(FailoverPolicy{
+			APIURL:        secondary.URL,
+			After:         time.Nanosecond,
+			ProbeInterval: 50 * time.Millisecond,
+			OnFailover: func(failedOver bool) {
+				mu.Lock()
+				states = append(states, failedOver)
+				mu.Unlock()
+			},
+		}),
+		CallingOnError(func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}))
+	if !assert.Nil(err) {
+		return
+	}
+	ctx := context.Background()
+	export := func() {
+		assert.Nil(exporter.ExportSpans(ctx, []*trace.SpanSnapshot{{Name: "span"}}))
+		assert.Nil(exporter.ForceFlush(ctx))
+	}
+
+	// The first failure starts the period, and the second ends it.
+	export()
+	assert.False(exporter.FailedOver())
+	export()
+	assert.True(exporter.FailedOver())
+	export()
+	assert.EqualValues(2, atomic.LoadInt32(&primaryRequests))
+	assert.EqualValues(2, atomic.LoadInt32(&secondaryRequests))
+
+	atomic.StoreInt32(&down, 0)
+	time.Sleep(50 * time.Millisecond)
+	export()
+	assert.False(exporter.FailedOver())
+	assert.EqualValues(3, atomic.LoadInt32(&primaryRequests))
+	assert.EqualValues(2, atomic.LoadInt32(&secondaryRequests))
+	assert.Nil(exporter.Shutdown(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal([]bool{true, false}, states)
+	assert.Len(errs, 1)
+}
+
+func TestWithFailoverInvalid(t *testing.T) {
+	assert.Error(t, ValidateOptions(WithFailover(FailoverPolicy{})))
+	assert.Error(t, ValidateOptions(WithFailover(FailoverPolicy{APIURL: "api.honeycomb.io"})))
+	assert.Error(t, ValidateOptions(WithFailover(FailoverPolicy{APIURL: "https://api.honeycomb.io/", After: -time.Second})))
+	assert.Error(t, ValidateOptions(WithFailover(FailoverPolicy{APIURL: "https://api.honeycomb.io/", ProbeInterval: -time.Second})))
+}
+
+func TestWithFailoverRequiredRegion(t *testing.T) {
+	assert := assert.New(t)
+	assert.Nil(ValidateOptions(
+		WithRegion("eu"),
+		WithFailover(FailoverPolicy{APIURL: "https://api.eu1.honeycomb.io/"}),
+		WithRequiredRegion("eu")))
+	err := ValidateOptions(
+		WithRegion("eu"),
+		WithFailover(FailoverPolicy{APIURL: "https://api.honeycomb.io/"}),
+		WithRequiredRegion("eu"))
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "failover")
+	}
+}
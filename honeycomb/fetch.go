@@ -0,0 +1,76 @@
+package honeycomb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// maxFetchedDocumentSize bounds the size of the JSON documents fetched by a
+// jsonFetcher.
+const maxFetchedDocumentSize = 1 << 20
+
+// jsonFetcher fetches a JSON document, such as a field policy or sampling
+// strategy, from a URL, using the ETag response header to avoid refetching
+// a document that has not changed.
+type jsonFetcher struct {
+	client *http.Client
+	url    string
+	// what names the document in errors.
+	what string
+	// unchanged is returned if the document has not changed.
+	unchanged error
+
+	mu   sync.Mutex
+	etag string
+}
+
+// newJSONFetcher returns a jsonFetcher using the given HTTP client, or
+// http.DefaultClient if nil.
+func newJSONFetcher(url string, client *http.Client, what string, unchanged error) *jsonFetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &jsonFetcher{client: client, url: url, what: what, unchanged: unchanged}
+}
+
+// fetch fetches the document and decodes it into v, returning f.unchanged if
+// it has not changed since it was last fetched.
+func (f *jsonFetcher) fetch(ctx context.Context, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	if len(f.etag) != 0 {
+		req.Header.Set("If-None-Match", f.etag)
+	}
+	f.mu.Unlock()
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return f.unchanged
+	default:
+		return fmt.Errorf("fetching %s from %s: unexpected HTTP status %d", f.what, f.url, resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxFetchedDocumentSize))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("parsing %s from %s: %v", f.what, f.url, err)
+	}
+	f.mu.Lock()
+	f.etag = resp.Header.Get("ETag")
+	f.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,215 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// FieldPolicy governs which span, event, link, and resource attributes the
+// exporter may send to Honeycomb. Fields the exporter itself generates, such as
+// "trace.trace_id" and "duration_ms," and fields added with WithField or
+// WithDynamicField are not subject to the policy.
+//
+// Each entry in Allow and Deny is either an exact attribute name or a prefix
+// followed by "*", such as "app.*".
+type FieldPolicy struct {
+	// Allow lists the attributes that may be sent. If empty, all attributes
+	// not denied may be sent.
+	Allow []string `json:"allow"`
+	// Deny lists the attributes that must not be sent. Deny takes precedence
+	// over Allow.
+	Deny []string `json:"deny"`
+}
+
+type fieldMatcher struct {
+	names    map[string]struct{}
+	prefixes []string
+}
+
+func newFieldMatcher(patterns []string) (fieldMatcher, error) {
+	m := fieldMatcher{names: make(map[string]struct{}, len(patterns))}
+	for _, p := range patterns {
+		switch {
+		case len(p) == 0:
+			return m, fmt.Errorf("invalid field pattern %q", p)
+		case strings.HasSuffix(p, "*"):
+			m.prefixes = append(m.prefixes, strings.TrimSuffix(p, "*"))
+		default:
+			m.names[p] = struct{}{}
+		}
+	}
+	return m, nil
+}
+
+func (m *fieldMatcher) empty() bool {
+	return len(m.names) == 0 && len(m.prefixes) == 0
+}
+
+func (m *fieldMatcher) matches(name string) bool {
+	if _, ok := m.names[name]; ok {
+		return true
+	}
+	for _, p := range m.prefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// compiledFieldPolicy is the form of a FieldPolicy consulted during export.
+type compiledFieldPolicy struct {
+	allow fieldMatcher
+	deny  fieldMatcher
+}
+
+func (p FieldPolicy) compile() (*compiledFieldPolicy, error) {
+	allow, err := newFieldMatcher(p.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := newFieldMatcher(p.Deny)
+	if err != nil {
+		return nil, err
+	}
+	return &compiledFieldPolicy{allow: allow, deny: deny}, nil
+}
+
+func (p *compiledFieldPolicy) permits(name string) bool {
+	if p.deny.matches(name) {
+		return false
+	}
+	return p.allow.empty() || p.allow.matches(name)
+}
+
+// WithFieldPolicy restricts the attributes the exporter sends to Honeycomb
+// according to the given policy.
+//
+// This function replaces any policy registered previously. When combined
+// with WithFieldPolicySource, this policy applies until the source first
+// supplies one.
+func WithFieldPolicy(p FieldPolicy) ExporterOption {
+	return func(c *exporterConfig) error {
+		compiled, err := p.compile()
+		if err != nil {
+			return err
+		}
+		c.fieldPolicy = compiled
+		return nil
+	}
+}
+
+// FieldPolicySource supplies the current field policy. It returns
+// ErrFieldPolicyUnchanged if the policy has not changed since the previous
+// call.
+type FieldPolicySource func(ctx context.Context) (FieldPolicy, error)
+
+// ErrFieldPolicyUnchanged is returned by a FieldPolicySource to indicate that
+// the policy it last supplied remains current.
+var ErrFieldPolicyUnchanged = errors.New("field policy unchanged")
+
+// WithFieldPolicySource causes the exporter to consult the given source for
+// its field policy immediately and then once per interval, so that the set of
+// attributes sent to Honeycomb can be tightened without restarting the
+// process. If the source fails, the exporter keeps using its current policy
+// and reports the failure to the error hook. Until the source first supplies
+// a policy, the exporter uses the one given to WithFieldPolicy, or, without
+// one, sends none of the attributes a policy governs.
+func WithFieldPolicySource(interval time.Duration, source FieldPolicySource) ExporterOption {
+	return func(c *exporterConfig) error {
+		if interval <= 0 {
+			return errors.New("field policy refresh interval must be positive")
+		}
+		if source == nil {
+			return errors.New("field policy source must not be nil")
+		}
+		c.fieldPolicyInterval = interval
+		c.fieldPolicySource = source
+		return nil
+	}
+}
+
+// FieldPolicyFromURL returns a FieldPolicySource that fetches a JSON-encoded
+// FieldPolicy from the given URL using the given HTTP client, or
+// http.DefaultClient if nil. It uses the ETag response header to avoid
+// refetching a policy that has not changed.
+func FieldPolicyFromURL(url string, client *http.Client) FieldPolicySource {
+	f := newJSONFetcher(url, client, "field policy", ErrFieldPolicyUnchanged)
+	return func(ctx context.Context) (FieldPolicy, error) {
+		var p FieldPolicy
+		err := f.fetch(ctx, &p)
+		return p, err
+	}
+}
+
+// fieldPolicyHolder holds the policy in effect, which may be replaced while
+// the exporter is in use.
+type fieldPolicyHolder struct {
+	current atomic.Value // *compiledFieldPolicy
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// denyAllFields is the policy in effect before a FieldPolicySource supplies
+// one, absent a policy given to WithFieldPolicy.
+var denyAllFields = &compiledFieldPolicy{deny: fieldMatcher{prefixes: []string{""}}}
+
+func newFieldPolicyHolder(initial *compiledFieldPolicy) *fieldPolicyHolder {
+	h := &fieldPolicyHolder{}
+	if initial == nil {
+		initial = denyAllFields
+	}
+	h.current.Store(initial)
+	return h
+}
+
+func (h *fieldPolicyHolder) permits(name string) bool {
+	return h.current.Load().(*compiledFieldPolicy).permits(name)
+}
+
+func (h *fieldPolicyHolder) refresh(source FieldPolicySource, interval time.Duration, onError func(error)) {
+	fetch := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		defer cancel()
+		p, err := source(ctx)
+		if err == nil {
+			var compiled *compiledFieldPolicy
+			if compiled, err = p.compile(); err == nil {
+				h.current.Store(compiled)
+			}
+		}
+		if err != nil && err != ErrFieldPolicyUnchanged {
+			onError(fmt.Errorf("refreshing field policy: %w", err))
+		}
+	}
+
+	h.stop = make(chan struct{})
+	h.done = make(chan struct{})
+	go func() {
+		defer close(h.done)
+		fetch()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fetch()
+			case <-h.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (h *fieldPolicyHolder) close() {
+	if h.stop != nil {
+		close(h.stop)
+		<-h.done
+	}
+}
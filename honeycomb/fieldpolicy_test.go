@@ -0,0 +1,165 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+)
+
+func TestFieldPolicyPermits(t *testing.T) {
+	tests := []struct {
+		description string
+		policy      FieldPolicy
+		permitted   []string
+		forbidden   []string
+	}{
+		{
+			"empty policy",
+			FieldPolicy{},
+			[]string{"a", "app.user"},
+			nil,
+		},
+		{
+			"allow prefix",
+			FieldPolicy{Allow: []string{"app.*", "http.method"}},
+			[]string{"app.user", "http.method"},
+			[]string{"http.url", "user"},
+		},
+		{
+			"deny overrides allow",
+			FieldPolicy{Allow: []string{"app.*"}, Deny: []string{"app.secret*"}},
+			[]string{"app.user"},
+			[]string{"app.secret", "app.secret_token"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert := assert.New(t)
+			p, err := test.policy.compile()
+			assert.Nil(err)
+			for _, name := range test.permitted {
+				assert.True(p.permits(name), name)
+			}
+			for _, name := range test.forbidden {
+				assert.False(p.permits(name), name)
+			}
+		})
+	}
+
+	_, err := FieldPolicy{Deny: []string{""}}.compile()
+	assert.Error(t, err)
+}
+
+func TestHoneycombOutputWithFieldPolicy(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	tr, err := setUpTestExporter(mockHoneycomb,
+		WithField("team", "core"),
+		WithFieldPolicy(FieldPolicy{
+			Allow: []string{"app.*"},
+			Deny:  []string{"app.password"},
+		}))
+	assert.Nil(err)
+
+	_, span := tr.Start(context.TODO(), "myTestSpan")
+	span.SetAttributes(
+		label.String("app.user", "alice"),
+		label.String("app.password", "hunter2"),
+		label.String("http.url", "https://example.com/?token=secret"),
+	)
+	span.End()
+
+	assert.Len(mockHoneycomb.Events(), 1)
+	mainEventFields := mockHoneycomb.Events()[0].Data
+	assert.Equal("alice", mainEventFields["app.user"])
+	assert.Equal("core", mainEventFields["team"])
+	assert.Equal("myTestSpan", mainEventFields["name"])
+	assert.NotContains(mainEventFields, "app.password")
+	assert.NotContains(mainEventFields, "http.url")
+}
+
+func TestFieldPolicyFromURL(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"deny": ["user.email"]}`))
+	}))
+	defer server.Close()
+
+	source := FieldPolicyFromURL(server.URL, nil)
+	p, err := source(context.Background())
+	assert.Nil(err)
+	assert.Equal([]string{"user.email"}, p.Deny)
+
+	_, err = source(context.Background())
+	assert.Equal(ErrFieldPolicyUnchanged, err)
+	assert.Equal(2, requests)
+}
+
+func TestFieldPolicyRefresh(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := make(chan struct{}, 1)
+	h := newFieldPolicyHolder(nil)
+	h.refresh(func(context.Context) (FieldPolicy, error) {
+		select {
+		case calls <- struct{}{}:
+		default:
+		}
+		return FieldPolicy{Deny: []string{"secret"}}, nil
+	}, time.Hour, func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	})
+	<-calls
+	assert.Eventually(func() bool {
+		return !h.permits("secret")
+	}, time.Second, time.Millisecond)
+	assert.True(h.permits("public"))
+	h.close()
+}
+
+func TestFieldPolicyDeniesUntilLoaded(t *testing.T) {
+	assert := assert.New(t)
+
+	var available int32
+	errs := make(chan error, 1)
+	h := newFieldPolicyHolder(nil)
+	h.refresh(func(context.Context) (FieldPolicy, error) {
+		if atomic.LoadInt32(&available) == 0 {
+			return FieldPolicy{}, errors.New("unavailable")
+		}
+		return FieldPolicy{Deny: []string{"secret"}}, nil
+	}, time.Millisecond, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+	assert.Error(<-errs)
+	assert.False(h.permits("secret"))
+	assert.False(h.permits("public"))
+
+	atomic.StoreInt32(&available, 1)
+	assert.Eventually(func() bool {
+		return h.permits("public")
+	}, time.Second, time.Millisecond)
+	assert.False(h.permits("secret"))
+	h.close()
+}
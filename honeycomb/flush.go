@@ -0,0 +1,67 @@
+package honeycomb
+
+import (
+	"context"
+	"time"
+)
+
+// queueFlushPollInterval is how often ForceFlush checks whether the workers
+// configured by WithAsyncExport have exported the spans queued for them.
+const queueFlushPollInterval = 5 * time.Millisecond
+
+// ForceFlush sends the events the exporter has queued for transmission and
+// waits for Honeycomb's responses to them, having first exported the spans
+// queued by WithAsyncExport and decided the traces awaiting a tail sampling
+// decision. Unlike Shutdown, it leaves the exporter running, for serverless
+// functions and batch jobs that must deliver their spans before being
+// suspended. Failures reported in the responses are passed to the error
+// hook.
+//
+// If ctx is done first, ForceFlush returns ctx.Err(), leaving the flush to
+// finish in the background. After Shutdown, it returns ErrExporterShutdown.
+func (e *Exporter) ForceFlush(ctx context.Context) error {
+	if !e.beginUse() {
+		return ErrExporterShutdown
+	}
+	if e.disabled {
+		e.endUse()
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		// The flush may outlast ForceFlush, but not the exporter.
+		defer e.endUse()
+		if e.queue != nil {
+			if err := e.queue.flush(ctx); err != nil {
+				done <- err
+				return
+			}
+		}
+		if e.tail != nil {
+			e.tail.flush(time.Now())
+		}
+		e.flushClient()
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flushClient sends the events queued by libhoney, waiting for the
+// responses, while no other events are queued, since libhoney can't accept
+// them during a flush. It then waits for the goroutine run by Start to
+// consume the responses queued, and consumes those left in the queue of
+// responses libhoney replaces, which the goroutine may not reach before
+// moving on to the new queue, and which nothing else consumes without it.
+func (e *Exporter) flushClient() {
+	e.flushMu.Lock()
+	responses := e.client.TxResponses()
+	e.client.Flush()
+	e.flushMu.Unlock()
+	e.syncResponses()
+	e.drainQueuedResponses(responses)
+}
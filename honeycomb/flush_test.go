@@ -0,0 +1,60 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestForceFlush(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	var errs []error
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithAsyncExport(16, 2),
+		CallingOnError(func(err error) {
+			errs = append(errs, err)
+		}))
+	assert.Nil(err)
+
+	sds := []*trace.SpanSnapshot{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	mockHoneycomb.SendResponse(transmission.Response{Err: errors.New("rejected")})
+	assert.Nil(exporter.ForceFlush(context.Background()))
+
+	assert.Len(mockHoneycomb.Events(), 3, "the queued spans are exported")
+	assert.Equal(1, mockHoneycomb.Stopped, "libhoney's queue is flushed")
+	assert.Equal([]error{errors.New("rejected")}, errs, "responses are reported")
+
+	// The exporter keeps running.
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.Len(mockHoneycomb.Events(), 6)
+}
+
+func TestForceFlushCanceled(t *testing.T) {
+	assert := assert.New(t)
+	release := make(chan struct{})
+	exporter, err := makeTestExporter(&transmission.MockSender{},
+		WithAsyncExport(16, 1),
+		WithBeforeSend(func(context.Context, *libhoney.Event, *trace.SpanSnapshot) bool {
+			<-release
+			return true
+		}))
+	assert.Nil(err)
+
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "stuck"}}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(context.Canceled, exporter.ForceFlush(ctx))
+
+	close(release)
+	assert.Nil(exporter.ForceFlush(context.Background()))
+	assert.Nil(exporter.Shutdown(context.Background()))
+}
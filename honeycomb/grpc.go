@@ -0,0 +1,111 @@
+package honeycomb
+
+import (
+	"strconv"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	grpcStatusCodeField = "rpc.grpc.status_code"
+	grpcStatusNameField = "rpc.grpc.status_name"
+	responseStatusField = "response.status_code"
+	errorField          = "error"
+)
+
+// grpcStatusNames are the names of the gRPC status codes, indexed by code.
+var grpcStatusNames = [...]string{
+	"OK",
+	"CANCELLED",
+	"UNKNOWN",
+	"INVALID_ARGUMENT",
+	"DEADLINE_EXCEEDED",
+	"NOT_FOUND",
+	"ALREADY_EXISTS",
+	"PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION",
+	"ABORTED",
+	"OUT_OF_RANGE",
+	"UNIMPLEMENTED",
+	"INTERNAL",
+	"UNAVAILABLE",
+	"DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+func grpcStatusName(code int64) string {
+	if code >= 0 && code < int64(len(grpcStatusNames)) {
+		return grpcStatusNames[code]
+	}
+	return "CODE(" + strconv.FormatInt(code, 10) + ")"
+}
+
+// GRPCErrorPolicy decides whether a span of the given kind that finished
+// with the given gRPC status code represents an error.
+type GRPCErrorPolicy func(code int64, kind apitrace.SpanKind) bool
+
+// GRPCAllErrors is a GRPCErrorPolicy that treats every status code other than
+// OK as an error.
+func GRPCAllErrors(code int64, _ apitrace.SpanKind) bool {
+	return code != 0
+}
+
+// GRPCServerFaults is a GRPCErrorPolicy following the OpenTelemetry semantic
+// conventions: server spans are errors only for the status codes that
+// indicate a fault in the server, namely UNKNOWN, DEADLINE_EXCEEDED,
+// UNIMPLEMENTED, INTERNAL, UNAVAILABLE, and DATA_LOSS, while other spans are
+// errors for every status code other than OK.
+func GRPCServerFaults(code int64, kind apitrace.SpanKind) bool {
+	if kind != apitrace.SpanKindServer {
+		return code != 0
+	}
+	switch code {
+	case 2, 4, 12, 13, 14, 15:
+		return true
+	default:
+		return false
+	}
+}
+
+// grpcStatusMapper returns a transform that adds the response fields for a
+// span's gRPC status code, marking the span as an error as the given policy
+// dictates.
+func grpcStatusMapper(policy GRPCErrorPolicy) func(*libhoney.Event, *trace.SpanSnapshot) {
+	return func(ev *libhoney.Event, data *trace.SpanSnapshot) {
+		var code int64
+		switch v := ev.Fields()[grpcStatusCodeField].(type) {
+		case int64:
+			code = v
+		case int32:
+			code = int64(v)
+		default:
+			return
+		}
+		ev.AddField(responseStatusField, code)
+		ev.AddField(grpcStatusNameField, grpcStatusName(code))
+		if policy(code, data.SpanKind) {
+			ev.AddField(errorField, true)
+		}
+	}
+}
+
+// WithGRPCStatusMapping causes the exporter to add a "response.status_code"
+// field holding the gRPC status code and a "rpc.grpc.status_name" field
+// holding its name, such as "NOT_FOUND," to the events for spans with the
+// "rpc.grpc.status_code" attribute. The exporter also sets the "error" field
+// for spans whose status code the given policy deems an error, in addition to
+// those whose status is Error. If policy is nil, the exporter uses
+// GRPCServerFaults.
+func WithGRPCStatusMapping(policy GRPCErrorPolicy) ExporterOption {
+	return func(c *exporterConfig) error {
+		if policy == nil {
+			policy = GRPCServerFaults
+		}
+		c.processors = append(c.processors, transformProcessor(grpcStatusMapper(policy)))
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestGRPCStatusName(t *testing.T) {
+	assert.Equal(t, "OK", grpcStatusName(0))
+	assert.Equal(t, "NOT_FOUND", grpcStatusName(5))
+	assert.Equal(t, "UNAUTHENTICATED", grpcStatusName(16))
+	assert.Equal(t, "CODE(42)", grpcStatusName(42))
+}
+
+func TestHoneycombOutputWithGRPCStatusMapping(t *testing.T) {
+	tests := []struct {
+		description string
+		policy      GRPCErrorPolicy
+		kind        apitrace.SpanKind
+		code        int
+		wantName    string
+		wantError   bool
+	}{
+		{"ok", nil, apitrace.SpanKindServer, 0, "OK", false},
+		{"server client fault", nil, apitrace.SpanKindServer, 5, "NOT_FOUND", false},
+		{"server fault", nil, apitrace.SpanKindServer, 13, "INTERNAL", true},
+		{"client", nil, apitrace.SpanKindClient, 5, "NOT_FOUND", true},
+		{"all errors", GRPCAllErrors, apitrace.SpanKindServer, 5, "NOT_FOUND", true},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			mockHoneycomb := &transmission.MockSender{}
+			assert := assert.New(t)
+
+			tr, err := setUpTestExporter(mockHoneycomb, WithGRPCStatusMapping(test.policy))
+			assert.Nil(err)
+
+			_, span := tr.Start(context.TODO(), "myTestSpan", apitrace.WithSpanKind(test.kind))
+			span.SetAttributes(label.Int("rpc.grpc.status_code", test.code))
+			span.End()
+
+			assert.Len(mockHoneycomb.Events(), 1)
+			fields := mockHoneycomb.Events()[0].Data
+			assert.Equal(int64(test.code), fields["response.status_code"])
+			assert.Equal(test.wantName, fields["rpc.grpc.status_name"])
+			if test.wantError {
+				assert.Equal(true, fields["error"])
+			} else {
+				assert.NotEqual(true, fields["error"])
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package honeycomb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Healthy reports whether the exporter appears able to deliver events to
+// Honeycomb, such as for a readiness probe. It reports false once the
+// exporter has been shut down, while the circuit breaker configured by
+// WithAuthCircuitBreaker is open, while the queue used by WithAsyncExport is
+// full, and while Honeycomb's most recent response to an event was a failure
+// rather than a success. Responses are learned from libhoney, which only the
+// goroutine run by Start, or RunErrorLogger, consumes. An exporter that
+// hasn't yet sent any events is healthy, as is a disabled one.
+func (e *Exporter) Healthy() bool {
+	if atomic.LoadInt32(&e.closing) != 0 {
+		return false
+	}
+	if e.disabled {
+		return true
+	}
+	if e.CircuitState() == CircuitOpen {
+		return false
+	}
+	if q := e.QueueStats(); q.Capacity > 0 && q.Depth >= q.Capacity {
+		return false
+	}
+	stats := e.Stats()
+	return stats.LastError == nil || !stats.LastErrorTime.After(stats.LastSuccessTime)
+}
+
+// Ping checks that Honeycomb is reachable and accepts the exporter's API key
+// by calling the API's authentication endpoint, without sending any events.
+// It returns a *TransmissionError matching ErrUnauthorized if Honeycomb
+// rejects the key, and ErrExporterShutdown once the exporter has been shut
+// down. A disabled exporter doesn't contact Honeycomb, and returns nil.
+func (e *Exporter) Ping(ctx context.Context) error {
+	if atomic.LoadInt32(&e.closing) != 0 {
+		return ErrExporterShutdown
+	}
+	if e.disabled {
+		return nil
+	}
+	authURL, err := apiEndpoint(e.apiURL, "1", "auth")
+	if err != nil {
+		return err
+	}
+	resp, body, err := apiRequest(ctx, e.pingClient, e.apiKey, authURL)
+	if err != nil {
+		return fmt.Errorf("pinging Honeycomb: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &TransmissionError{Reason: ErrUnauthorized, StatusCode: resp.StatusCode}
+	default:
+		return fmt.Errorf("pinging Honeycomb: unexpected HTTP status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+}
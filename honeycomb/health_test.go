@@ -0,0 +1,75 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestHealthy(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{BlockOnResponses: true}
+	exporter, err := makeTestExporter(mockHoneycomb, CallingOnError(func(error) {}))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Nil(exporter.Start(context.Background()))
+	assert.True(exporter.Healthy())
+
+	respond := func(r transmission.Response) {
+		assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "span"}}))
+		events := mockHoneycomb.Events()
+		r.Metadata = events[len(events)-1].Metadata
+		attempted := exporter.TransmissionStats().Enqueued
+		mockHoneycomb.SendResponse(r)
+		assert.Eventually(func() bool {
+			s := exporter.TransmissionStats()
+			return s.Sent+s.Failed+s.Dropped == attempted
+		}, time.Second, time.Millisecond)
+	}
+	respond(transmission.Response{StatusCode: http.StatusUnauthorized, Err: errors.New("got unexpected HTTP status 401")})
+	assert.False(exporter.Healthy())
+	respond(transmission.Response{StatusCode: http.StatusAccepted})
+	assert.True(exporter.Healthy())
+
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.False(exporter.Healthy())
+}
+
+func TestPing(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("/1/auth", r.URL.Path)
+		if r.Header.Get("X-Honeycomb-Team") != "good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, `{"team": {"slug": "test"}}`)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	for _, apiKey := range []string{"good", "bad"} {
+		exporter, err := NewExporter(Config{APIKey: apiKey}, WithAPIURL(server.URL))
+		if !assert.Nil(err) {
+			return
+		}
+		err = exporter.Ping(ctx)
+		if apiKey == "good" {
+			assert.Nil(err)
+		} else {
+			assert.True(errors.Is(err, ErrUnauthorized))
+		}
+		assert.Nil(exporter.Shutdown(ctx))
+		assert.Equal(ErrExporterShutdown, exporter.Ping(ctx))
+	}
+}
@@ -0,0 +1,119 @@
+package honeycomb
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// defaultHealthCheckRoutes are the routes of the requests dropped by a
+// HealthCheckFilter given no patterns.
+var defaultHealthCheckRoutes = []string{"/healthz", "/readyz", "/metrics"}
+
+// HealthCheckFilter is a sampler that drops the server spans of requests
+// from load balancers, orchestrators, and metrics scrapers, such as those
+// for "/healthz," along with all of their descendants, leaving other spans
+// to another sampler. A server span is dropped if its "http.route"
+// attribute, the path of its "http.target" attribute, or its name, matches
+// one of the filter's patterns when it starts.
+//
+// Spans whose local parents weren't sampled are dropped as well, so that
+// the descendants of dropped spans are dropped with them.
+type HealthCheckFilter struct {
+	patterns []string
+	delegate sdktrace.Sampler
+}
+
+var _ sdktrace.Sampler = (*HealthCheckFilter)(nil)
+
+// NewHealthCheckFilter returns a HealthCheckFilter that drops the server
+// spans matching the given patterns, or "/healthz," "/readyz," and
+// "/metrics" if none are given, and samples other spans with delegate.
+// Patterns use the syntax of path.Match, in which "*" matches any sequence
+// of characters other than "/", and are also matched against span names
+// prefixed with an HTTP method, such as "GET /healthz."
+func NewHealthCheckFilter(delegate sdktrace.Sampler, patterns ...string) (*HealthCheckFilter, error) {
+	if delegate == nil {
+		return nil, errors.New("health check filter's delegate sampler must not be nil")
+	}
+	if len(patterns) == 0 {
+		patterns = defaultHealthCheckRoutes
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("health check pattern %q: %w", pattern, err)
+		}
+	}
+	return &HealthCheckFilter{
+		patterns: append([]string(nil), patterns...),
+		delegate: delegate,
+	}, nil
+}
+
+// matches reports whether a route, path, or span name matches one of the
+// filter's patterns.
+func (f *HealthCheckFilter) matches(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	// Span names may be prefixed with the request's method.
+	if i := strings.IndexByte(s, ' '); i >= 0 && !strings.HasPrefix(s, "/") {
+		s = s[i+1:]
+	}
+	for _, pattern := range f.patterns {
+		if ok, _ := path.Match(pattern, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isHealthCheck reports whether a starting span is the server span of a
+// health check request.
+func (f *HealthCheckFilter) isHealthCheck(p sdktrace.SamplingParameters) bool {
+	if p.Kind != apitrace.SpanKindServer {
+		return false
+	}
+	if f.matches(p.Name) {
+		return true
+	}
+	for _, kv := range p.Attributes {
+		switch kv.Key {
+		case httpRouteKey:
+			if f.matches(kv.Value.Emit()) {
+				return true
+			}
+		case semconv.HTTPTargetKey:
+			target := kv.Value.Emit()
+			if i := strings.IndexByte(target, '?'); i >= 0 {
+				target = target[:i]
+			}
+			if f.matches(target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ShouldSample implements sdktrace.Sampler.
+func (f *HealthCheckFilter) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := p.ParentContext
+	if parent.IsValid() && !p.HasRemoteParent && !parent.IsSampled() {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop}
+	}
+	if f.isHealthCheck(p) {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop}
+	}
+	return f.delegate.ShouldSample(p)
+}
+
+// Description implements sdktrace.Sampler.
+func (f *HealthCheckFilter) Description() string {
+	return fmt.Sprintf("HealthCheckFilter{patterns:%s,delegate:%s}", strings.Join(f.patterns, ","), f.delegate.Description())
+}
@@ -0,0 +1,64 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestHealthCheckFilter(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := makeTestExporter(mockHoneycomb)
+	assert.Nil(err)
+	filter, err := NewHealthCheckFilter(sdktrace.AlwaysSample())
+	assert.Nil(err)
+	tr, err := setUpTestProvider(exporter, sdktrace.WithConfig(sdktrace.Config{DefaultSampler: filter}))
+	assert.Nil(err)
+
+	server := apitrace.WithSpanKind(apitrace.SpanKindServer)
+	for _, start := range []func() (context.Context, apitrace.Span){
+		func() (context.Context, apitrace.Span) {
+			return tr.Start(context.Background(), "GET /healthz", server)
+		},
+		func() (context.Context, apitrace.Span) {
+			return tr.Start(context.Background(), "HTTP GET", server,
+				apitrace.WithAttributes(label.String("http.target", "/readyz?verbose=1")))
+		},
+		func() (context.Context, apitrace.Span) {
+			return tr.Start(context.Background(), "handler", server,
+				apitrace.WithAttributes(label.String("http.route", "/metrics")))
+		},
+	} {
+		ctx, span := start()
+		_, child := tr.Start(ctx, "db.ping")
+		child.End()
+		span.End()
+	}
+	ctx, span := tr.Start(context.Background(), "GET /cart", server)
+	_, child := tr.Start(ctx, "GET /healthz")
+	child.End()
+	span.End()
+
+	var names []interface{}
+	for _, ev := range mockHoneycomb.Events() {
+		names = append(names, ev.Data["name"])
+	}
+	assert.Equal([]interface{}{"GET /healthz", "GET /cart"}, names, "only server spans are filtered")
+
+	custom, err := NewHealthCheckFilter(sdktrace.AlwaysSample(), "/status/*")
+	assert.Nil(err)
+	assert.Equal(sdktrace.Drop, custom.ShouldSample(sdktrace.SamplingParameters{Name: "/status/db", Kind: apitrace.SpanKindServer}).Decision)
+	assert.Equal(sdktrace.RecordAndSample, custom.ShouldSample(sdktrace.SamplingParameters{Name: "/healthz", Kind: apitrace.SpanKindServer}).Decision)
+
+	_, err = NewHealthCheckFilter(sdktrace.AlwaysSample(), "[")
+	assert.Error(err)
+	_, err = NewHealthCheckFilter(nil)
+	assert.Error(err)
+}
@@ -17,11 +17,19 @@ package honeycomb
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	libhoney "github.com/honeycombio/libhoney-go"
@@ -29,7 +37,10 @@ import (
 
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/semconv"
+	apitrace "go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -43,6 +54,9 @@ type Config struct {
 	// send events.
 	//
 	// Don't have a Honeycomb account? Sign up at https://ui.honeycomb.io/signup.
+	//
+	// If empty, the API key is taken from the HONEYCOMB_API_KEY environment
+	// variable.
 	APIKey string
 }
 
@@ -51,11 +65,120 @@ type exporterConfig struct {
 	serviceName       string
 	staticFields      map[string]interface{}
 	dynamicFields     map[string]func() interface{}
+	valueSerializers  map[reflect.Type]ValueSerializer
 	apiURL            string
 	userAgentAddendum string
 	sender            transmission.Sender
 	onError           func(error)
 	debug             bool
+	auditInterval     time.Duration
+	auditReport       func([]string)
+
+	truncationInterval time.Duration
+	truncationReport   func(TruncationReport)
+
+	queueDepthField string
+
+	datasetPreflight bool
+
+	maxBatchBytes int
+
+	volumeAccounting bool
+
+	eventBudget          uint64
+	eventBudgetInterval  time.Duration
+	eventBudgetExceeded  func(EventBudgetExceeded)
+	overBudgetSampleRate uint
+
+	throughputTarget float64
+	throughputWindow time.Duration
+
+	oversizedPolicy OversizedEventPolicy
+	oversizedHook   func(*libhoney.Event, int) bool
+
+	fieldPolicy         *compiledFieldPolicy
+	fieldPolicyInterval time.Duration
+	fieldPolicySource   FieldPolicySource
+
+	requiredRegion string
+
+	omitResourceAttributes bool
+
+	sampleRate uint
+
+	serviceFields map[string]map[string]interface{}
+
+	errorsDataset          string
+	errorsDatasetAllErrors bool
+
+	errorStack func(*trace.SpanSnapshot) string
+
+	asyncQueueSize int
+	asyncWorkers   int
+
+	onExportResult func(ExportResult)
+
+	maxEventAttributes int
+
+	annotationSampling *annotationSampling
+
+	timestampAttribute label.Key
+
+	spanKindFields map[apitrace.SpanKind]map[string]interface{}
+
+	exportedSpanKinds map[apitrace.SpanKind]bool
+
+	processors []EventProcessor
+	beforeSend func(context.Context, *libhoney.Event, *trace.SpanSnapshot) bool
+
+	selfTracer         apitrace.Tracer
+	selfTracingDataset string
+
+	serviceNamePrecedence ServiceNamePrecedence
+
+	tailSampling *tailSamplingConfig
+
+	deterministicOrdering bool
+
+	minSpanDuration time.Duration
+
+	maxSpansPerTrace int
+
+	disabled bool
+
+	transportSplit *transportSplit
+
+	onResponse func(Response)
+
+	transport   http.RoundTripper
+	proxyURL    *url.URL
+	tlsConfig   *tls.Config
+	rootCAs     *x509.CertPool
+	clientCerts []tls.Certificate
+
+	retryPolicy    *RetryPolicy
+	circuitBreaker *CircuitBreakerPolicy
+
+	diskBufferDir      string
+	diskBufferMaxBytes int64
+
+	onQueueOverflow func(SpanReference)
+
+	meterProvider metric.MeterProvider
+
+	errorThrottleInterval time.Duration
+
+	manualStart bool
+
+	keyVerification bool
+
+	datasetTemplate *datasetTemplate
+
+	routingRules []RoutingRule
+
+	mirrorDatasets []mirrorDataset
+
+	failover *FailoverPolicy
 }
 
 const (
@@ -67,6 +190,101 @@ const (
 // NewExporter function.
 type ExporterOption func(*exporterConfig) error
 
+// OptionErrors holds every problem found with a set of exporter options,
+// in the order in which they were found, so that they can all be fixed at
+// once.
+type OptionErrors []error
+
+func (e OptionErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d problems with exporter options: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors, for use by errors.Is and errors.As.
+func (e OptionErrors) Unwrap() []error {
+	return e
+}
+
+// configure applies options to a new exporter configuration, returning any
+// errors they report, along with any problems with the combination of
+// options.
+func configure(opts []ExporterOption) (exporterConfig, OptionErrors) {
+	econf := exporterConfig{}
+	var errs OptionErrors
+	for _, o := range opts {
+		if err := o(&econf); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	econf.applyEnvironment()
+	if disabled, err := disabledByEnv(); err != nil {
+		errs = append(errs, err)
+	} else if disabled {
+		econf.disabled = true
+	}
+	if econf.overBudgetSampleRate != 0 && econf.eventBudget == 0 {
+		errs = append(errs, errors.New("over-budget sample rate requires an event budget"))
+	}
+	if econf.deterministicOrdering && (econf.asyncQueueSize > 0 || econf.tailSampling != nil) {
+		errs = append(errs, errors.New("deterministic ordering can't be combined with asynchronous export or tail sampling"))
+	}
+	if len(econf.queueDepthField) != 0 && econf.asyncQueueSize == 0 && econf.tailSampling == nil {
+		errs = append(errs, errors.New("queue depth field requires asynchronous export or tail sampling"))
+	}
+	if econf.transport != nil && econf.proxyURL != nil {
+		errs = append(errs, errors.New("proxy URL can't be combined with a custom round tripper"))
+	}
+	if econf.transport != nil && econf.customTLS() {
+		errs = append(errs, errors.New("TLS settings can't be combined with a custom round tripper"))
+	}
+	if len(econf.requiredRegion) != 0 {
+		apiURL := econf.apiURL
+		if len(apiURL) == 0 {
+			apiURL = defaultAPIURL
+		}
+		if err := checkAPIURLRegion(apiURL, econf.requiredRegion); err != nil {
+			errs = append(errs, err)
+		}
+		if econf.failover != nil {
+			if err := checkAPIURLRegion(econf.failover.APIURL, econf.requiredRegion); err != nil {
+				errs = append(errs, fmt.Errorf("failover: %w", err))
+			}
+		}
+	}
+	return econf, errs
+}
+
+// configureExporter applies options to a new exporter configuration,
+// returning an OptionErrors listing any problems with them or with config,
+// which it completes from the environment.
+func configureExporter(config *Config, opts []ExporterOption) (exporterConfig, error) {
+	config.APIKey = envDefault(config.APIKey, apiKeyEnv)
+	econf, errs := configure(opts)
+	if len(config.APIKey) == 0 && !econf.disabled {
+		errs = append(OptionErrors{errors.New("API key must not be empty")}, errs...)
+	}
+	if len(errs) != 0 {
+		return econf, errs
+	}
+	return econf, nil
+}
+
+// ValidateOptions checks a set of exporter options without creating an
+// exporter, returning an OptionErrors listing every problem that NewExporter
+// would report, or nil if there are none.
+func ValidateOptions(opts ...ExporterOption) error {
+	if _, errs := configure(opts); len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
 func validateField(name string) error {
 	if len(name) == 0 {
 		return errors.New("field name must not be empty")
@@ -77,7 +295,10 @@ func validateField(name string) error {
 // TargetingDataset specifies the name of the Honeycomb dataset to which the
 // exporter will send events.
 //
-// If not specified, the default dataset name is "opentelemetry."
+// If not specified, the default dataset name is "opentelemetry." Exporters
+// with an API key for Honeycomb Environments & Services send the events for
+// spans to datasets named after their services instead, as described by
+// IsClassicKey.
 func TargetingDataset(name string) ExporterOption {
 	return func(c *exporterConfig) error {
 		if len(name) == 0 {
@@ -217,6 +438,112 @@ func WithDynamicFields(m map[string]func() interface{}) ExporterOption {
 	}
 }
 
+// WithServiceFields adds sets of fields to the exporter that apply only to
+// spans produced by particular services. The outer map is keyed by service
+// name, matched against each span's "service.name" resource attribute, or,
+// failing that, the name given to WithServiceName. Events published for spans
+// of a matching service will include fields pairing each name in the inner map
+// with its corresponding value, taking precedence over fields added with
+// WithField or WithDynamicField.
+//
+// This lets one exporter shared by several services, as in a multi-tenant
+// process or a bridge, attach different ownership details to each service's
+// spans. This function replaces any fields registered previously for the
+// same service.
+func WithServiceFields(m map[string]map[string]interface{}) ExporterOption {
+	return func(c *exporterConfig) error {
+		for service, fields := range m {
+			if len(service) == 0 {
+				return errors.New("service name must not be empty")
+			}
+			copied := make(map[string]interface{}, len(fields))
+			for name, value := range fields {
+				if err := validateField(name); err != nil {
+					return err
+				}
+				copied[name] = value
+			}
+			if c.serviceFields == nil {
+				c.serviceFields = make(map[string]map[string]interface{}, len(m))
+			}
+			c.serviceFields[service] = copied
+		}
+		return nil
+	}
+}
+
+// WithErrorsDataset causes the exporter to send a copy of each "exception"
+// span event, and each "error" span event as recorded by Span.RecordError, to
+// the named dataset, in addition to the dataset to which it sends all events,
+// so that errors from many services can be triaged together in one place,
+// perhaps with a shorter retention period. If includeErrorSpans is true, the
+// exporter also copies the event for each span whose status is Error.
+func WithErrorsDataset(name string, includeErrorSpans bool) ExporterOption {
+	return func(c *exporterConfig) error {
+		if len(name) == 0 {
+			return errors.New("errors dataset name must not be empty")
+		}
+		c.errorsDataset = name
+		c.errorsDatasetAllErrors = includeErrorSpans
+		return nil
+	}
+}
+
+// WithErrorStack causes the exporter to attach a stack trace as the field
+// "error.stack" to the event for each span whose status is Error, unless the
+// span already recorded one with an "exception.stacktrace" attribute on an
+// "exception" or "error" event. The stack trace is obtained by calling f
+// with the span, or, if f is nil, by capturing the stack of the goroutine
+// exporting the span.
+//
+// An empty string returned by f leaves the event without the field.
+func WithErrorStack(f func(*trace.SpanSnapshot) string) ExporterOption {
+	return func(c *exporterConfig) error {
+		if f == nil {
+			f = func(*trace.SpanSnapshot) string {
+				return string(debug.Stack())
+			}
+		}
+		c.errorStack = f
+		return nil
+	}
+}
+
+// WithMaxEventAttributeCount limits the number of attributes copied onto the
+// event sent for each span event to n. Span event attributes take priority;
+// any room left over is filled with the span's resource attributes, which are
+// otherwise all copied onto every such event. This limit does not apply to
+// the events sent for spans themselves.
+func WithMaxEventAttributeCount(n int) ExporterOption {
+	return func(c *exporterConfig) error {
+		if n <= 0 {
+			return errors.New("maximum event attribute count must be positive")
+		}
+		c.maxEventAttributes = n
+		return nil
+	}
+}
+
+// WithTimestampAttribute names an attribute whose value, when present on a
+// span or span event, overrides the timestamp of the event sent for it, which
+// otherwise is the time the span started or the span event occurred. This is
+// useful when replaying historical data or exporting spans reconstructed from
+// logs.
+//
+// The attribute's value may be a string in RFC 3339 format or a number giving
+// the time since the Unix epoch in seconds, milliseconds, microseconds, or
+// nanoseconds, as judged by its magnitude. Values that can't be interpreted
+// as a time are ignored.
+func WithTimestampAttribute(name string) ExporterOption {
+	return func(c *exporterConfig) error {
+		if len(name) == 0 {
+			return errors.New("timestamp attribute name must not be empty")
+		}
+		c.timestampAttribute = label.Key(name)
+		return nil
+	}
+}
+
 // WithAPIURL specifies the URL for the Honeycomb API server to which to send
 // events.
 //
@@ -249,10 +576,13 @@ func WithUserAgentAddendum(a string) ExporterOption {
 }
 
 // CallingOnError specifies a hook function to be called when an error occurs
-// sending events to Honeycomb.
+// sending events to Honeycomb. Use errors.Is to recognize failures such as
+// ErrUnauthorized and ErrRateLimited, and errors.As to get the details from
+// their *TransmissionError or *DatasetNotFoundError.
 //
-// If not specified, the default hook logs the errors. Specifying a nil value
-// suppresses this default logging behavior.
+// If not specified, the default hook passes the errors to the OpenTelemetry
+// error handler set with otel.SetErrorHandler, which by default logs them.
+// Specifying a nil value suppresses this default behavior.
 func CallingOnError(f func(error)) ExporterOption {
 	return func(c *exporterConfig) error {
 		if f == nil {
@@ -263,6 +593,55 @@ func CallingOnError(f func(error)) ExporterOption {
 	}
 }
 
+// ExportResult summarizes the outcome of one call to ExportSpans.
+type ExportResult struct {
+	// Accepted is the number of spans whose events were all queued for
+	// transmission.
+	Accepted int
+	// Failed holds the spans that could not be queued for transmission, in the
+	// order they were passed to ExportSpans.
+	Failed []*trace.SpanSnapshot
+	// Errors holds the reason each corresponding span in Failed could not be
+	// queued.
+	Errors []error
+}
+
+// ExportError is the error ExportSpans returns when it fails to export some
+// of the spans given it, summarizing the outcome of the call so that callers
+// can tell partial failures from total ones, and retry only the failures.
+type ExportError struct {
+	ExportResult
+}
+
+func (e *ExportError) Error() string {
+	total := e.Accepted + len(e.Failed)
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("failed to export %d of %d spans", len(e.Failed), total)
+	}
+	return fmt.Sprintf("failed to export %d of %d spans: %v", len(e.Failed), total, e.Errors[0])
+}
+
+// Unwrap returns the reasons the spans could not be exported, for use by
+// errors.Is and errors.As.
+func (e *ExportError) Unwrap() []error {
+	return e.Errors
+}
+
+// CallingOnExportResult specifies a hook function to be called with a
+// summary of each call to ExportSpans, identifying which spans were accepted
+// and which failed, so that a wrapping exporter can retry only the failures.
+//
+// Spans count as accepted once their events are queued for transmission;
+// failures to transmit them later are reported only to the error hook. When
+// the exporter is configured with WithAsyncExport, spans count as accepted
+// once they are queued for export.
+func CallingOnExportResult(f func(ExportResult)) ExporterOption {
+	return func(c *exporterConfig) error {
+		c.onExportResult = f
+		return nil
+	}
+}
+
 // WithDebug causes the exporter to emit verbose logging to STDOUT
 // if provided with a true argument, otherwise it has no effect.
 //
@@ -283,9 +662,80 @@ func WithDebugEnabled() ExporterOption {
 	return WithDebug(true)
 }
 
-// withHoneycombSender sets the event sender on the Honeycomb transmission subsystem.
-func withHoneycombSender(s transmission.Sender) ExporterOption {
+// WithFieldAudit causes the exporter to report the names, but not the values,
+// of all the fields it has sent to Honeycomb, once per interval and again on
+// shutdown. Each report includes only the fields sent since the previous
+// report, sorted by name.
+//
+// If report is nil, the exporter logs the field names instead.
+func WithFieldAudit(interval time.Duration, report func(names []string)) ExporterOption {
+	return func(c *exporterConfig) error {
+		if interval <= 0 {
+			return errors.New("field audit interval must be positive")
+		}
+		c.auditInterval = interval
+		c.auditReport = report
+		return nil
+	}
+}
+
+// WithTruncationAdvisory causes the exporter to watch for spans that the SDK
+// truncated because they had more attributes, span events, or links than its
+// configured limits allow, and to report a summary of them once per interval
+// and again on shutdown. The exporter reports only intervals in which it saw
+// truncated spans.
+//
+// If report is nil, the exporter logs a warning instead.
+func WithTruncationAdvisory(interval time.Duration, report func(TruncationReport)) ExporterOption {
 	return func(c *exporterConfig) error {
+		if interval <= 0 {
+			return errors.New("truncation advisory interval must be positive")
+		}
+		c.truncationInterval = interval
+		c.truncationReport = report
+		return nil
+	}
+}
+
+// WithoutResourceAttributes prevents the exporter from copying the attributes
+// of each span's resource onto the events it sends, so that only span
+// attributes and fields added to the exporter are sent.
+func WithoutResourceAttributes() ExporterOption {
+	return func(c *exporterConfig) error {
+		c.omitResourceAttributes = true
+		return nil
+	}
+}
+
+// WithSampleRate specifies the rate at which the spans reaching the exporter
+// were sampled, such as 10 when using a sampler that keeps one trace in ten,
+// and causes every event the exporter sends to carry that rate explicitly.
+//
+// libhoney omits a sample rate of 1 when transmitting events, which downstream
+// tools such as Refinery and usage reports treat inconsistently, so the rate
+// is also recorded in the field "meta.sample_rate."
+//
+// Spans carrying a positive integer "SampleRate" attribute, such as those
+// sampled by a RuleSampler, are sent with that rate instead.
+func WithSampleRate(rate uint) ExporterOption {
+	return func(c *exporterConfig) error {
+		if rate == 0 {
+			return errors.New("sample rate must be positive")
+		}
+		c.sampleRate = rate
+		return nil
+	}
+}
+
+// WithSender replaces the transmission subsystem through which the exporter
+// sends events to Honeycomb. This is mainly useful for testing and
+// benchmarking, with senders that record or discard events rather than
+// sending them.
+func WithSender(s transmission.Sender) ExporterOption {
+	return func(c *exporterConfig) error {
+		if s == nil {
+			return errors.New("sender must not be nil")
+		}
 		c.sender = s
 		return nil
 	}
@@ -293,7 +743,17 @@ func withHoneycombSender(s transmission.Sender) ExporterOption {
 
 // Exporter is an implementation of trace.Exporter that uploads a span to Honeycomb.
 type Exporter struct {
+	// txCounters counts the events handed to libhoney by outcome, and stats
+	// accumulates the counts reported by Stats. They come first so that
+	// their counts are 64-bit aligned, as the atomic operations on them
+	// require on 32-bit platforms.
+	txCounters txCounters
+	stats      exportStats
+
 	client *libhoney.Client
+	// dataset is the dataset with which the exporter was created, which
+	// libhoney gives the events it creates.
+	dataset string
 
 	// serviceName identifies your application. If set it will be added to all
 	// events as `service_name`.
@@ -301,9 +761,154 @@ type Exporter struct {
 	// While optional, setting this field is extremely valuable when you
 	// instrument multiple services.
 	serviceName string
+	// serviceNamePrecedence, if set, decides between serviceName and the
+	// service name given by each span's resource.
+	serviceNamePrecedence ServiceNamePrecedence
 	// onError is the hook to be called when there is an error occurred when
 	// uploading the span data. If no custom hook is set, errors are logged.
 	onError func(err error)
+	// auditor, if set, records the names of fields sent to Honeycomb.
+	auditor *fieldAuditor
+	// truncation, if set, watches for spans truncated by the SDK.
+	truncation *truncationAdvisor
+	// fieldPolicy, if set, governs which attributes may be sent.
+	fieldPolicy *fieldPolicyHolder
+	// omitResourceAttributes suppresses copying resource attributes onto
+	// events.
+	omitResourceAttributes bool
+	// serviceFields holds fields to add to events for spans of particular
+	// services, keyed by service name.
+	serviceFields map[string]map[string]interface{}
+	// errorsDataset, if set, receives copies of exception events and,
+	// if errorsDatasetAllErrors is true, of error spans.
+	errorsDataset          string
+	errorsDatasetAllErrors bool
+	// errorStack, if set, supplies stack traces for error spans.
+	errorStack func(*trace.SpanSnapshot) string
+	// queue, if set, holds spans awaiting asynchronous export.
+	queue *exportQueue
+	// onExportResult, if set, is called with the outcome of each ExportSpans
+	// call.
+	onExportResult func(ExportResult)
+	// maxEventAttributes, if positive, limits the attributes copied onto the
+	// events for span events.
+	maxEventAttributes int
+	// annotationSampling, if set, holds the rates at which to send the events
+	// for span events and links.
+	annotationSampling *annotationSampling
+	// timestampAttribute, if set, names the attribute that overrides event
+	// timestamps.
+	timestampAttribute label.Key
+	// spanKindFields holds the fields added to the events for spans of
+	// particular kinds.
+	spanKindFields map[apitrace.SpanKind]map[string]interface{}
+	// exportedSpanKinds, if set, holds the kinds of the spans to send.
+	exportedSpanKinds map[apitrace.SpanKind]bool
+	// processors form the pipeline through which the event for each span
+	// passes before it is sent.
+	processors []EventProcessor
+	// beforeSend, if set, is called with each event just before it is sent
+	// and may veto it.
+	beforeSend func(context.Context, *libhoney.Event, *trace.SpanSnapshot) bool
+	// selfTracer, if set, records spans describing the exporter's own work.
+	// The events for those spans go to selfTracingDataset, or are dropped if
+	// it's empty, reporting that once with selfSpansDropped.
+	selfTracer         apitrace.Tracer
+	selfTracingDataset string
+	selfSpansDropped   sync.Once
+	// tail, if set, holds spans until deciding whether to keep their traces.
+	tail *tailSampler
+	// flusher, if set, flushes events when their accumulated size reaches a
+	// threshold.
+	flusher *byteFlusher
+	// oversize, if set, applies a policy to events too large to send.
+	oversize *oversizeGuard
+	// valueSerializers holds the functions for converting field values of
+	// particular types before sending them.
+	valueSerializers map[reflect.Type]ValueSerializer
+	// volumes, if set, counts the events sent to each dataset.
+	volumes *volumeAccountant
+	// budget, if set, limits the events sent per interval.
+	budget *eventBudget
+	// adaptive, if set, samples traces to keep the rate of events sent near
+	// a target.
+	adaptive *adaptiveSampler
+	// ordered causes each batch of spans to be exported in a stable order,
+	// one batch at a time, holding orderMu, flushing afterward.
+	ordered bool
+	orderMu sync.Mutex
+	// minSpanDuration, if positive, is the duration below which spans other
+	// than errors and roots are dropped, counted by shortSpans.
+	minSpanDuration time.Duration
+	shortSpans      *shortSpanCounter
+	// spanCounter, if set, limits the spans sent per trace.
+	spanCounter *traceSpanCounter
+	// disabled causes the exporter to do nothing.
+	disabled bool
+	// transportSplit, if set, hands the spans of some traces to an alternate
+	// exporter.
+	transportSplit *transportSplit
+	// started records whether Start has been called, or NewExporter has
+	// started its goroutine itself, as recorded by autoStarted. stopLogger,
+	// if set, stops the goroutine, which closes loggerDone when done.
+	lifecycleMu sync.Mutex
+	started     bool
+	autoStarted bool
+	// responseSync receives channels for the goroutine run by Start to close
+	// once it has consumed the responses queued.
+	responseSync chan chan struct{}
+	// live holds the settings Reload can change: the dataset to which events
+	// go by default and, if nonzero, the sample rate set explicitly on every
+	// event. debugLog, if set, passes libhoney's debugging output on while
+	// debug logging is enabled.
+	live     atomic.Value // *liveSettings
+	debugLog *debugLogger
+	// team and environment are the slugs of the team and environment to
+	// which the API key belongs, if verified.
+	team        string
+	environment string
+	stopLogger  context.CancelFunc
+	loggerDone  chan struct{}
+	// closing is set to 1 when Shutdown begins. shutdownMu is held for
+	// reading while using the exporter, and for writing while closing it.
+	closing    int32
+	shutdownMu sync.RWMutex
+	// flushMu is held for reading while queuing an event and for writing
+	// while flushing libhoney's queue.
+	flushMu sync.RWMutex
+	// retryAfter, if set, records the delay requested by rate limiting
+	// responses.
+	retryAfter *retryAfterTransport
+	// onResponse, if set, is called with the response to each event.
+	onResponse func(Response)
+	// breaker, if set, stops the exporter sending spans while Honeycomb
+	// rejects its API key.
+	breaker *circuitBreaker
+	// diskBuffer, if set, holds the batches of events that failed to reach
+	// Honeycomb.
+	diskBuffer *diskBuffer
+	// onQueueOverflow, if set, is called for each event libhoney drops.
+	onQueueOverflow func(SpanReference)
+	// selfMetrics, if set, records metrics about the exporter's work.
+	selfMetrics *selfMetrics
+	// apiURL and apiKey address the requests made by Ping with pingClient.
+	apiURL     string
+	apiKey     string
+	pingClient *http.Client
+	// throttle, if set, limits the errors passed to the error hook.
+	throttle *errorThrottle
+	// datasetTemplate, if set, names the dataset for each span's events.
+	datasetTemplate *datasetTemplate
+	// environmentKey records that the API key belongs to an environment of
+	// Honeycomb Environments & Services, as described by IsClassicKey.
+	environmentKey bool
+	// routingRules are the rules given to WithRoutingRules.
+	routingRules []RoutingRule
+	// mirrorDatasets receive copies of the events for spans.
+	mirrorDatasets []mirrorDataset
+	// failover, if set, sends requests to the secondary API server while
+	// the primary is unreachable.
+	failover *failoverTransport
 }
 
 var _ trace.SpanExporter = (*Exporter)(nil)
@@ -324,17 +929,117 @@ const (
 	spanRefTypeFollowsFrom spanRefType = 1
 )
 
+// LinkRefTypeKey is the link attribute from which the exporter takes the
+// ref_type of the event it sends for a link, with the value "child_of" or
+// "follows_from." Links without it are sent as child_of.
+// OCProtoSpanToOTelSpanSnapshot sets it from the types of OpenCensus links.
+const LinkRefTypeKey = label.Key("link.ref_type")
+
+const (
+	linkRefTypeChildOf     = "child_of"
+	linkRefTypeFollowsFrom = "follows_from"
+)
+
+// linkRefType returns the reference type given by a link's LinkRefTypeKey
+// attribute, along with its other attributes.
+func linkRefType(attrs []label.KeyValue) (spanRefType, []label.KeyValue) {
+	for i, kv := range attrs {
+		if kv.Key != LinkRefTypeKey {
+			continue
+		}
+		refType := spanRefTypeChildOf
+		if kv.Value.AsString() == linkRefTypeFollowsFrom {
+			refType = spanRefTypeFollowsFrom
+		}
+		rest := make([]label.KeyValue, 0, len(attrs)-1)
+		rest = append(rest, attrs[:i]...)
+		return refType, append(rest, attrs[i+1:]...)
+	}
+	return spanRefTypeChildOf, attrs
+}
+
+// Names of fields the exporter adds to events itself.
+const (
+	serviceNameField    = "service_name"
+	statusCodeField     = "status.code"
+	statusMessageField  = "status.message"
+	sampleRateField     = "meta.sample_rate"
+	sampleReasonField   = "meta.sample_reason"
+	childSpanCountField = "meta.child_span_count"
+	errorStackField     = "error.stack"
+)
+
+// exceptionEventName is the name of span events that record exceptions, per
+// the OpenTelemetry semantic conventions, and errorEventName the name of those
+// recorded by the SDK's Span.RecordError.
+const (
+	exceptionEventName = "exception"
+	errorEventName     = "error"
+)
+
+// isErrorEvent reports whether a span event with the given name records an
+// error or exception.
+func isErrorEvent(name string) bool {
+	return name == exceptionEventName || name == errorEventName
+}
+
+// exceptionStacktraceKey is the attribute of exception span events that holds a
+// stack trace.
+const exceptionStacktraceKey = label.Key("exception.stacktrace")
+
 const (
 	traceIDShortLength = 8
 	traceIDLongLength  = 16
 )
 
-func transcribeAttributesTo(ev *libhoney.Event, attrs []label.KeyValue) {
+func (e *Exporter) transcribeAttributesTo(ev *libhoney.Event, attrs []label.KeyValue) {
 	for _, kv := range attrs {
-		ev.AddField(string(kv.Key), kv.Value.AsInterface())
+		if e.fieldPolicy != nil && !e.fieldPolicy.permits(string(kv.Key)) {
+			continue
+		}
+		ev.AddField(string(kv.Key), attributeValue(ev, kv.Value))
 	}
 }
 
+// limitLayeredAttributes selects at most n attributes from an underlay and an
+// overlay, preferring those of the overlay, and returns the selections.
+func limitLayeredAttributes(underlay, overlay []label.KeyValue, n int) ([]label.KeyValue, []label.KeyValue) {
+	if len(overlay) >= n {
+		return nil, overlay[:n]
+	}
+	if len(underlay)+len(overlay) <= n {
+		return underlay, overlay
+	}
+	overlaid := make(map[label.Key]struct{}, len(overlay))
+	for _, kv := range overlay {
+		overlaid[kv.Key] = struct{}{}
+	}
+	remaining := n - len(overlay)
+	selected := make([]label.KeyValue, 0, remaining)
+	for _, kv := range underlay {
+		if len(selected) == remaining {
+			break
+		}
+		if _, ok := overlaid[kv.Key]; !ok {
+			selected = append(selected, kv)
+		}
+	}
+	return selected, overlay
+}
+
+// spanServiceName returns the name of the service that produced a span, taken
+// from its resource or, failing that, the given default.
+func spanServiceName(data *trace.SpanSnapshot, defaultName string) string {
+	if data.Resource != nil {
+		if v, ok := data.Resource.LabelSet().Value(semconv.ServiceNameKey); ok {
+			if name := v.AsString(); len(name) != 0 {
+				return name
+			}
+		}
+	}
+	return defaultName
+}
+
 // span is the format of trace events that Honeycomb accepts.
 type span struct {
 	TraceID         string  `json:"trace.trace_id"`
@@ -403,47 +1108,129 @@ func honeycombSpan(s *trace.SpanSnapshot) *span {
 }
 
 // NewExporter returns an implementation of trace.Exporter that uploads spans to Honeycomb.
+// If the configuration or options are invalid, it returns an OptionErrors
+// listing every problem found with them.
+//
+// Settings not given by the configuration or options are taken from the
+// environment, if present: the API key from HONEYCOMB_API_KEY, the dataset
+// from HONEYCOMB_DATASET, the API URL from HONEYCOMB_API_ENDPOINT, and the
+// service name from HONEYCOMB_SERVICE_NAME.
 func NewExporter(config Config, opts ...ExporterOption) (*Exporter, error) {
 	// Developer note: bump this with each release
 	// TODO: Stamp this via a variable set at link time with a value derived
 	// from the current VCS tag.
 	const versionStr = "0.15.0"
 
-	if len(config.APIKey) == 0 {
-		return nil, errors.New("API key must not be empty")
-	}
-
-	econf := exporterConfig{}
-	for _, o := range opts {
-		if err := o(&econf); err != nil {
-			return nil, err
-		}
+	econf, err := configureExporter(&config, opts)
+	if err != nil {
+		return nil, err
 	}
-	if len(econf.dataset) == 0 {
+	explicitDataset := len(econf.dataset) != 0
+	if !explicitDataset {
 		econf.dataset = defaultDataset
 	}
+	if econf.disabled {
+		exporter := &Exporter{
+			dataset:     econf.dataset,
+			serviceName: econf.serviceName,
+			disabled:    true,
+		}
+		exporter.live.Store(&liveSettings{dataset: econf.dataset, sampleRate: econf.sampleRate})
+		return exporter, nil
+	}
 
 	libhoneyConfig := libhoney.ClientConfig{
 		APIKey:  config.APIKey,
 		Dataset: econf.dataset,
 	}
+	apiURL := defaultAPIURL
 	if len(econf.apiURL) != 0 {
 		libhoneyConfig.APIHost = econf.apiURL
+		apiURL = econf.apiURL
 	}
 	userAgent := econf.userAgentAddendum
 	if len(userAgent) == 0 {
 		userAgent = "Honeycomb-OpenTelemetry-exporter"
 	}
 	libhoney.UserAgentAddition = userAgent + "/" + versionStr
+	debugLog := &debugLogger{}
+	debugLog.setEnabled(econf.debug)
+	libhoneyConfig.Logger = debugLog
+	onError := econf.onError
+	if onError == nil {
+		onError = handleError
+	}
+	var throttle *errorThrottle
+	if econf.errorThrottleInterval > 0 {
+		throttle = newErrorThrottle(econf.errorThrottleInterval, onError)
+		onError = throttle.handle
+	}
+	var auth authResponse
+	if econf.keyVerification {
+		if auth, err = verifyAPIKey(apiURL, config.APIKey, econf.roundTripper(), onError); err != nil {
+			return nil, err
+		}
+	}
+	environmentKey := !IsClassicKey(config.APIKey)
+	if len(auth.Team.Slug) != 0 {
+		environmentKey = len(auth.Environment.Slug) != 0
+	}
+	if environmentKey && explicitDataset {
+		onError(fmt.Errorf("ignoring dataset %q, since the API key belongs to a Honeycomb environment; "+
+			"events go to datasets named after their services", econf.dataset))
+	}
+
+	var selfMetrics *selfMetrics
+	if econf.meterProvider != nil {
+		if selfMetrics, err = newSelfMetrics(econf.meterProvider); err != nil {
+			return nil, fmt.Errorf("creating self-metrics instruments: %w", err)
+		}
+	}
+
+	var retryAfter *retryAfterTransport
+	var breaker *circuitBreaker
+	var diskBuffer *diskBuffer
+	var failover *failoverTransport
 	if econf.sender != nil {
 		libhoneyConfig.Transmission = econf.sender
-	}
-	if econf.debug {
-		libhoneyConfig.Logger = &libhoney.DefaultLogger{}
+	} else {
+		transport := econf.roundTripper()
+		if econf.failover != nil {
+			failover = newFailoverTransport(transport, *econf.failover)
+			transport = failover
+		}
+		if econf.retryPolicy != nil {
+			transport = &retryTransport{base: transport, policy: *econf.retryPolicy}
+		}
+		if econf.circuitBreaker != nil {
+			breaker = newCircuitBreaker(*econf.circuitBreaker)
+			transport = &breakerTransport{base: transport, breaker: breaker}
+		}
+		if len(econf.diskBufferDir) != 0 {
+			if diskBuffer, err = openDiskBuffer(econf.diskBufferDir, econf.diskBufferMaxBytes, transport, onError); err != nil {
+				return nil, err
+			}
+			transport = diskBuffer
+		}
+		if selfMetrics != nil {
+			transport = &meteringTransport{base: transport, metrics: selfMetrics}
+		}
+		if econf.selfTracer != nil {
+			transport = &tracingTransport{
+				base:        transport,
+				tracer:      econf.selfTracer,
+				skipDataset: econf.selfTracingDataset,
+			}
+		}
+		retryAfter = &retryAfterTransport{base: transport}
+		libhoneyConfig.Transmission = newTransmission(retryAfter, libhoneyConfig.Logger)
 	}
 
 	client, err := libhoney.NewClient(libhoneyConfig)
 	if err != nil {
+		if diskBuffer != nil {
+			diskBuffer.close()
+		}
 		return nil, err
 	}
 
@@ -454,90 +1241,491 @@ func NewExporter(config Config, opts ...ExporterOption) (*Exporter, error) {
 		client.AddDynamicField(name, f)
 	}
 
-	onError := econf.onError
-	if onError == nil {
-		onError = func(err error) {
-			log.Printf("Error when sending spans to Honeycomb: %v", err)
+	if econf.datasetPreflight {
+		if err := preflightDataset(config, opts, onError); err != nil {
+			client.Close()
+			if diskBuffer != nil {
+				diskBuffer.close()
+			}
+			return nil, err
 		}
 	}
 
-	return &Exporter{
-		client:      client,
-		serviceName: econf.serviceName,
-		onError:     onError,
-	}, nil
+	exporter := &Exporter{
+		client:                 client,
+		dataset:                econf.dataset,
+		serviceName:            econf.serviceName,
+		serviceNamePrecedence:  econf.serviceNamePrecedence,
+		onError:                onError,
+		omitResourceAttributes: econf.omitResourceAttributes,
+		serviceFields:          econf.serviceFields,
+		errorsDataset:          econf.errorsDataset,
+		errorsDatasetAllErrors: econf.errorsDatasetAllErrors,
+		errorStack:             econf.errorStack,
+		onExportResult:         econf.onExportResult,
+		maxEventAttributes:     econf.maxEventAttributes,
+		annotationSampling:     econf.annotationSampling,
+		timestampAttribute:     econf.timestampAttribute,
+		spanKindFields:         econf.spanKindFields,
+		exportedSpanKinds:      econf.exportedSpanKinds,
+		processors:             econf.processors,
+		beforeSend:             econf.beforeSend,
+		selfTracer:             econf.selfTracer,
+		selfTracingDataset:     econf.selfTracingDataset,
+		valueSerializers:       econf.valueSerializers,
+		ordered:                econf.deterministicOrdering,
+		minSpanDuration:        econf.minSpanDuration,
+		transportSplit:         econf.transportSplit,
+		retryAfter:             retryAfter,
+		onResponse:             econf.onResponse,
+		breaker:                breaker,
+		diskBuffer:             diskBuffer,
+		onQueueOverflow:        econf.onQueueOverflow,
+		selfMetrics:            selfMetrics,
+		apiURL:                 apiURL,
+		apiKey:                 config.APIKey,
+		pingClient:             &http.Client{Transport: econf.roundTripper()},
+		throttle:               throttle,
+		responseSync:           make(chan chan struct{}),
+		debugLog:               debugLog,
+		team:                   auth.Team.Slug,
+		environment:            auth.Environment.Slug,
+		datasetTemplate:        econf.datasetTemplate,
+		environmentKey:         environmentKey,
+		routingRules:           econf.routingRules,
+		mirrorDatasets:         econf.mirrorDatasets,
+		failover:               failover,
+	}
+	exporter.live.Store(&liveSettings{dataset: econf.dataset, sampleRate: econf.sampleRate})
+	if econf.auditInterval > 0 {
+		exporter.auditor = newFieldAuditor(econf.auditInterval, econf.auditReport)
+		go exporter.auditor.run()
+	}
+	if econf.truncationInterval > 0 {
+		exporter.truncation = newTruncationAdvisor(econf.truncationInterval, econf.truncationReport)
+		go exporter.truncation.run()
+	}
+	if econf.fieldPolicy != nil || econf.fieldPolicySource != nil {
+		exporter.fieldPolicy = newFieldPolicyHolder(econf.fieldPolicy)
+		if econf.fieldPolicySource != nil {
+			exporter.fieldPolicy.refresh(econf.fieldPolicySource, econf.fieldPolicyInterval, onError)
+		}
+	}
+	if econf.maxBatchBytes > 0 {
+		exporter.flusher = &byteFlusher{
+			max:   int64(econf.maxBatchBytes),
+			flush: client.Flush,
+		}
+	}
+	if econf.eventBudget > 0 {
+		exporter.budget = newEventBudget(econf.eventBudget, econf.eventBudgetInterval, econf.overBudgetSampleRate, econf.eventBudgetExceeded)
+	}
+	if econf.throughputTarget > 0 {
+		exporter.adaptive = newAdaptiveSampler(econf.throughputTarget, econf.throughputWindow)
+	}
+	if econf.minSpanDuration > 0 {
+		exporter.shortSpans = &shortSpanCounter{}
+	}
+	if econf.maxSpansPerTrace > 0 {
+		exporter.spanCounter = newTraceSpanCounter(econf.maxSpansPerTrace)
+	}
+	if econf.volumeAccounting {
+		exporter.volumes = newVolumeAccountant()
+	}
+	if econf.oversizedPolicy != 0 || econf.oversizedHook != nil {
+		exporter.oversize = &oversizeGuard{
+			limit:    maxEventBytes,
+			policy:   econf.oversizedPolicy,
+			hook:     econf.oversizedHook,
+			newEvent: client.NewEvent,
+		}
+	}
+	if econf.tailSampling != nil {
+		exporter.tail = newTailSampler(*econf.tailSampling, exporter.exportTailSampled)
+	}
+	if econf.asyncQueueSize > 0 {
+		exporter.queue = newExportQueue(econf.asyncQueueSize, econf.asyncWorkers, exporter.exportQueued)
+	}
+	if len(econf.queueDepthField) != 0 {
+		client.AddDynamicField(econf.queueDepthField, func() interface{} {
+			return exporter.pendingSpans()
+		})
+	}
+	if selfMetrics != nil {
+		err := selfMetrics.observeQueues(exporter)
+		if err == nil && exporter.volumes != nil {
+			err = selfMetrics.observeVolumes(exporter)
+		}
+		if err != nil {
+			exporter.Shutdown(context.Background())
+			return nil, fmt.Errorf("creating self-metrics instruments: %w", err)
+		}
+	}
+	if !econf.manualStart {
+		exporter.autoStart()
+	}
+	return exporter, nil
 }
 
 // RunErrorLogger consumes from the response queue, calling the onError callback
-// when errors are encountered.
+// when errors are encountered, and the hook given to CallingOnResponse for
+// every response.
 //
 // This method will block until the passed context.Context is canceled, or until
-// exporter.Close is called.
+// exporter.Close is called. Start runs it more robustly. If the goroutine run
+// by Start is already consuming the responses, as it is unless the exporter
+// is configured with WithAutoStart(false), RunErrorLogger leaves them to it,
+// only blocking.
 func (e *Exporter) RunErrorLogger(ctx context.Context) {
-	responses := e.client.TxResponses()
+	e.lifecycleMu.Lock()
+	done := e.loggerDone
+	e.lifecycleMu.Unlock()
+	if e.disabled || done != nil {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		return
+	}
+	e.consumeResponses(ctx, e.txResponses())
+}
+
+// txResponses returns libhoney's current queue of responses, which flushes
+// replace.
+func (e *Exporter) txResponses() chan transmission.Response {
+	e.flushMu.RLock()
+	defer e.flushMu.RUnlock()
+	return e.client.TxResponses()
+}
+
+// consumeResponses handles the responses in a queue of responses until it is
+// closed or ctx is canceled.
+func (e *Exporter) consumeResponses(ctx context.Context, responses chan transmission.Response) {
 	for {
 		select {
 		case r, ok := <-responses:
 			if !ok {
 				return
 			}
-			if r.Err != nil {
-				e.onError(r.Err)
-			}
+			e.handleResponse(r)
+		case synced := <-e.responseSync:
+			e.drainQueuedResponses(responses)
+			close(synced)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-// ExportSpans exports a sequence of OpenTelemetry spans to Honeycomb.
+// ExportSpans exports a sequence of OpenTelemetry spans to Honeycomb. If it
+// fails to export any of them, it returns an *ExportError identifying them,
+// having exported the rest. After Shutdown, it returns ErrExporterShutdown.
 func (e *Exporter) ExportSpans(ctx context.Context, sds []*trace.SpanSnapshot) error {
-	for _, span := range sds {
-		e.exportSpan(ctx, span)
+	if !e.beginUse() {
+		return ErrExporterShutdown
+	}
+	defer e.endUse()
+	if e.disabled {
+		return nil
+	}
+	var result ExportResult
+	if e.selfTracer != nil && !onlySelfSpans(sds) {
+		var span apitrace.Span
+		ctx, span = e.selfTracer.Start(ctx, "honeycomb.ExportSpans")
+		count := len(sds)
+		defer func() {
+			span.SetAttributes(
+				selfSpanCountKey.Int(count),
+				selfFailedSpanCountKey.Int(len(result.Failed)))
+			if len(result.Errors) != 0 {
+				span.SetStatus(codes.Error, result.Errors[0].Error())
+			}
+			span.End()
+		}()
+	}
+	if e.truncation != nil {
+		for _, s := range sds {
+			e.truncation.record(s)
+		}
+	}
+	var alternate []*trace.SpanSnapshot
+	if e.transportSplit != nil {
+		sds, alternate = e.transportSplit.divide(sds)
+	}
+	if e.breaker != nil && len(sds) != 0 && !e.breaker.allow(time.Now()) {
+		result.Failed = sds
+		for range sds {
+			result.Errors = append(result.Errors, ErrCircuitOpen)
+		}
+	} else if e.queue != nil {
+		if dropped := e.queue.enqueue(sds); dropped > 0 {
+			err := fmt.Errorf("export queue is full; dropped %d spans", dropped)
+			e.onError(err)
+			result.Failed = sds[len(sds)-dropped:]
+			if e.onQueueOverflow != nil {
+				for _, s := range result.Failed {
+					e.onQueueOverflow(spanReference(s))
+				}
+			}
+			for range result.Failed {
+				result.Errors = append(result.Errors, err)
+			}
+		}
+		result.Accepted = len(sds) - len(result.Failed)
+	} else if e.tail != nil {
+		e.tail.add(sds)
+		result.Accepted = len(sds)
+	} else {
+		if e.ordered {
+			e.orderMu.Lock()
+			defer e.orderMu.Unlock()
+			sds = sortedSpans(sds)
+		}
+		for _, span := range sds {
+			if err := e.exportSpan(ctx, span, nil); err != nil {
+				result.Failed = append(result.Failed, span)
+				result.Errors = append(result.Errors, err)
+			} else {
+				result.Accepted++
+			}
+		}
+		if e.ordered {
+			e.flushClient()
+		}
+	}
+	if e.transportSplit != nil {
+		e.transportSplit.recordEvents(result)
+		if err := e.transportSplit.exportAlternate(ctx, alternate, e.onError); err != nil {
+			result.Failed = append(result.Failed, alternate...)
+			for range alternate {
+				result.Errors = append(result.Errors, err)
+			}
+		} else {
+			result.Accepted += len(alternate)
+		}
+	}
+	if e.onExportResult != nil {
+		e.onExportResult(result)
+	}
+	if e.selfMetrics != nil {
+		e.selfMetrics.recordExport(ctx, result)
+	}
+	if len(result.Failed) != 0 {
+		return &ExportError{ExportResult: result}
 	}
 	return nil
 }
 
-func (e *Exporter) exportSpan(ctx context.Context, data *trace.SpanSnapshot) {
+// exportSpan sends the events for a span, along with the tail sampler's
+// decision to keep its trace, if any, returning the first error encountered
+// queuing them for transmission.
+func (e *Exporter) exportSpan(ctx context.Context, data *trace.SpanSnapshot, tail *tailDecision) error {
+	if e.selfTracer != nil && len(e.selfTracingDataset) == 0 && isSelfSpan(data) {
+		e.selfSpansDropped.Do(func() {
+			e.onError(errSelfSpansDropped)
+		})
+		return nil
+	}
+	if !e.kindExported(data) || e.tooShort(data) {
+		return nil
+	}
+	rule := e.routingRule(data)
+	if rule != nil && rule.Drop {
+		return nil
+	}
+	serviceName := e.serviceName
+	if e.serviceNamePrecedence != 0 {
+		var err error
+		if serviceName, err = e.resolveServiceName(data); err != nil {
+			e.onError(err)
+			return err
+		}
+	}
+	var failure error
+	sampleRate := e.settings().sampleRate
+	if rate, ok := spanSampleRate(data.Attributes); ok {
+		sampleRate = rate
+	}
+	if tail != nil {
+		if sampleRate == 0 {
+			sampleRate = 1
+		}
+		sampleRate *= tail.rate
+	}
+	if e.spanCounter != nil {
+		if ok, first := e.spanCounter.admit(data.SpanContext.TraceID); !ok {
+			if first {
+				return e.sendTraceTruncated(ctx, data, sampleRate)
+			}
+			return nil
+		}
+	}
+	// sampledAt accounts for the exporter sampling the span at a rate.
+	sampledAt := func(rate uint) {
+		if rate > 1 {
+			if sampleRate == 0 {
+				sampleRate = 1
+			}
+			sampleRate *= rate
+		}
+	}
+	if rule != nil && rule.SampleRate > 1 {
+		if !exporterSampled(data.SpanContext.TraceID, "routing", rule.SampleRate) {
+			return nil
+		}
+		sampledAt(rule.SampleRate)
+	}
+	if e.adaptive != nil {
+		events := 1 + len(data.MessageEvents) + len(data.Links)
+		rate, ok := e.adaptive.admit(data.SpanContext.TraceID, events)
+		if !ok {
+			return nil
+		}
+		sampledAt(rate)
+	}
+	if e.budget != nil {
+		rate, ok := e.budget.admit(data.SpanContext.TraceID)
+		if !ok {
+			return nil
+		}
+		sampledAt(rate)
+	}
+	sendEvent := func(ev *libhoney.Event) {
+		if err := e.send(ctx, ev, data, sampleRate); err != nil && failure == nil {
+			failure = err
+		}
+	}
+
 	ev := e.client.NewEvent()
 
-	applyResourceAttributes := func(ev *libhoney.Event) {
-		if data.Resource != nil {
-			transcribeAttributesTo(ev, data.Resource.Attributes())
+	var serviceFields map[string]interface{}
+	if e.serviceFields != nil {
+		if e.serviceNamePrecedence != 0 {
+			serviceFields = e.serviceFields[serviceName]
+		} else {
+			serviceFields = e.serviceFields[spanServiceName(data, e.serviceName)]
 		}
-		if len(e.serviceName) != 0 {
-			ev.AddField("service_name", e.serviceName)
+	}
+	var resourceAttrs []label.KeyValue
+	if data.Resource != nil && !e.omitResourceAttributes {
+		resourceAttrs = data.Resource.Attributes()
+		if e.serviceNamePrecedence != 0 {
+			resourceAttrs = withoutServiceName(resourceAttrs)
 		}
 	}
-	transcribeLayeredAttributesTo := func(ev *libhoney.Event, attrs []label.KeyValue) {
+	applyResourceAttributes := func(ev *libhoney.Event, resourceAttrs []label.KeyValue) {
+		e.transcribeAttributesTo(ev, resourceAttrs)
+		if len(serviceName) != 0 {
+			ev.AddField(serviceNameField, serviceName)
+		}
+		if serviceFields != nil {
+			ev.Add(serviceFields)
+		}
+	}
+	transcribeLayeredAttributesTo := func(ev *libhoney.Event, resourceAttrs, attrs []label.KeyValue) {
 		// Treat resource-defined attributes as underlays, with any same-keyed message event
 		// attributes taking precedence. Apply them first.
-		applyResourceAttributes(ev)
-		transcribeAttributesTo(ev, attrs)
+		applyResourceAttributes(ev, resourceAttrs)
+		e.transcribeAttributesTo(ev, attrs)
 	}
 
 	// Treat resource-defined attributes as underlays, with any same-keyed span attributes taking
 	// precedence. Apply them first.
-	applyResourceAttributes(ev)
-	ev.Timestamp = data.StartTime
-	ev.Add(honeycombSpan(data))
+	applyResourceAttributes(ev, resourceAttrs)
+	ev.Timestamp = e.eventTimestamp(data.StartTime, data.Attributes)
+	hcSpan := honeycombSpan(data)
+	ev.Add(hcSpan)
+	if fields := e.spanKindFields[data.SpanKind]; fields != nil {
+		ev.Add(fields)
+	}
+	e.transcribeAttributesTo(ev, data.Attributes)
+	if tail != nil {
+		reason := tail.reason
+		if head, ok := ev.Fields()[sampleReasonField].(string); ok && len(head) != 0 {
+			reason = head + ", " + reason
+		}
+		ev.AddField(sampleReasonField, reason)
+	}
+
+	ev.AddField(statusCodeField, int32(data.StatusCode))
+	ev.AddField(statusMessageField, data.StatusMessage)
+	ev.AddField(childSpanCountField, data.ChildSpanCount)
+
+	// process passes an event through the pipeline, reporting whether to send
+	// it.
+	process := func(ev *libhoney.Event) bool {
+		keep, err := runProcessors(ctx, e.processors, ev, data)
+		if err != nil {
+			e.onError(err)
+			if failure == nil {
+				failure = err
+			}
+		}
+		return keep
+	}
+	// The span's own event decides whether any of its events are sent, so
+	// process it before sending those for its span events and links.
+	if !process(ev) {
+		return failure
+	}
+
+	if e.errorStack != nil && data.StatusCode == codes.Error && !hasRecordedStack(data) {
+		if stack := e.errorStack(data); len(stack) != 0 {
+			ev.AddField(errorStackField, stack)
+		}
+	}
+
+	messageEvents, links := data.MessageEvents, data.Links
+	eventRate, linkRate := uint(1), uint(1)
+	if s := e.annotationSampling; s != nil {
+		eventRate, linkRate = s.spanEvents, s.links
+		if !annotationsSampled(data.SpanContext.TraceID, eventRate) {
+			messageEvents = nil
+		}
+		if !annotationsSampled(data.SpanContext.TraceID, linkRate) {
+			links = nil
+		}
+	}
+	// sendAnnotation sends the event for a span event or link sampled at the
+	// given rate.
+	sendAnnotation := func(ev *libhoney.Event, rate uint) {
+		annotationSampleRate := sampleRate
+		if rate > 1 {
+			if annotationSampleRate == 0 {
+				annotationSampleRate = 1
+			}
+			annotationSampleRate *= rate
+		}
+		if err := e.send(ctx, ev, data, annotationSampleRate); err != nil && failure == nil {
+			failure = err
+		}
+	}
 
 	// We send these message events as zero-duration spans.
-	for _, a := range data.MessageEvents {
+	for _, a := range messageEvents {
 		spanEv := e.client.NewEvent()
-		transcribeLayeredAttributesTo(spanEv, a.Attributes)
-		spanEv.Timestamp = a.Time
+		underlay, overlay := resourceAttrs, a.Attributes
+		if e.maxEventAttributes > 0 {
+			underlay, overlay = limitLayeredAttributes(underlay, overlay, e.maxEventAttributes)
+		}
+		transcribeLayeredAttributesTo(spanEv, underlay, overlay)
+		spanEv.Timestamp = e.eventTimestamp(a.Time, a.Attributes)
 
 		spanEv.Add(spanEvent{
 			Name:           a.Name,
-			TraceID:        getHoneycombTraceID(data.SpanContext.TraceID[:]),
-			ParentID:       data.SpanContext.SpanID.String(),
+			TraceID:        hcSpan.TraceID,
+			ParentID:       hcSpan.ID,
 			ParentName:     data.Name,
 			AnnotationType: "span_event",
 		})
-		if err := spanEv.Send(); err != nil {
-			e.onError(err)
+		if !process(spanEv) {
+			continue
 		}
+		if len(e.errorsDataset) != 0 && isErrorEvent(a.Name) {
+			sendAnnotation(e.copyEvent(spanEv, e.errorsDataset), eventRate)
+		}
+		sendAnnotation(spanEv, eventRate)
+		atomic.AddUint64(&e.stats.spanEventsEmitted, 1)
 	}
 
 	// link represents a link to a trace and span that lives elsewhere.
@@ -553,40 +1741,227 @@ func (e *Exporter) exportSpan(ctx context.Context, data *trace.SpanSnapshot) {
 		RefType        spanRefType `json:"ref_type,omitempty"`
 	}
 
-	for _, spanLink := range data.Links {
+	for _, spanLink := range links {
 		linkEv := e.client.NewEvent()
-		transcribeLayeredAttributesTo(linkEv, spanLink.Attributes)
+		refType, attrs := linkRefType(spanLink.Attributes)
+		transcribeLayeredAttributesTo(linkEv, resourceAttrs, attrs)
 
 		linkEv.Add(link{
-			TraceID:        getHoneycombTraceID(data.SpanContext.TraceID[:]),
-			ParentID:       data.SpanContext.SpanID.String(),
+			TraceID:        hcSpan.TraceID,
+			ParentID:       hcSpan.ID,
 			LinkTraceID:    getHoneycombTraceID(spanLink.TraceID[:]),
 			LinkSpanID:     spanLink.SpanID.String(),
 			AnnotationType: "link",
-			// TODO(akvanhar): properly set the reference type when specs are defined
-			// see https://github.com/open-telemetry/opentelemetry-specification/issues/65
-			RefType: spanRefTypeChildOf,
+			RefType:        refType,
 		})
-		if err := linkEv.Send(); err != nil {
-			e.onError(err)
+		if !process(linkEv) {
+			continue
 		}
+		sendAnnotation(linkEv, linkRate)
+		atomic.AddUint64(&e.stats.linksEmitted, 1)
 	}
 
-	for _, kv := range data.Attributes {
-		ev.AddField(string(kv.Key), kv.Value.AsInterface())
+	if len(e.errorsDataset) != 0 && e.errorsDatasetAllErrors && data.StatusCode == codes.Error {
+		sendEvent(e.copyEvent(ev, e.errorsDataset))
 	}
+	sendEvent(ev)
+	atomic.AddUint64(&e.stats.spansConverted, 1)
+	return failure
+}
 
-	ev.AddField("status.code", int32(data.StatusCode))
-	ev.AddField("status.message", data.StatusMessage)
+// hasRecordedStack reports whether a span recorded an exception along with its
+// stack trace.
+func hasRecordedStack(data *trace.SpanSnapshot) bool {
+	for _, a := range data.MessageEvents {
+		if !isErrorEvent(a.Name) {
+			continue
+		}
+		for _, kv := range a.Attributes {
+			if kv.Key == exceptionStacktraceKey {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// eventTimestamp returns the timestamp for an event, overriding t with the
+// time given by the configured timestamp attribute, if present.
+func (e *Exporter) eventTimestamp(t time.Time, attrs []label.KeyValue) time.Time {
+	if len(e.timestampAttribute) != 0 {
+		if override, ok := timestampFromAttributes(attrs, e.timestampAttribute); ok {
+			return override
+		}
+	}
+	return t
+}
 
-	if err := ev.SendPresampled(); err != nil {
+// copyEvent returns a copy of an event addressed to the given dataset.
+func (e *Exporter) copyEvent(ev *libhoney.Event, dataset string) *libhoney.Event {
+	c := e.client.NewEvent()
+	c.Add(ev.Fields())
+	c.Dataset = dataset
+	c.Timestamp = ev.Timestamp
+	c.SampleRate = ev.SampleRate
+	return c
+}
+
+// send transmits an event for a span with the given sample rate, if not
+// zero, unless the beforeSend hook vetoes it, reporting any failure to
+// enqueue it to the onError hook as well as returning it. The OpenTelemetry
+// SDK has already sampled the spans by the time they reach the exporter, so
+// events bypass libhoney's own sampling.
+func (e *Exporter) send(ctx context.Context, ev *libhoney.Event, data *trace.SpanSnapshot, sampleRate uint) error {
+	if sampleRate != 0 {
+		ev.SampleRate = sampleRate
+		ev.AddField(sampleRateField, sampleRate)
+	}
+	own := ev.Dataset == e.dataset
+	if own {
+		if dataset, ok := e.spanDataset(data); ok {
+			ev.Dataset = dataset
+		}
+	}
+	if e.selfTracer != nil && isSelfSpan(data) {
+		ev.Dataset = e.selfTracingDataset
+		own = false
+	}
+	if e.beforeSend != nil && !e.beforeSend(ctx, ev, data) {
+		return nil
+	}
+	serializeFields(ev, e.valueSerializers)
+	if !own || len(e.mirrorDatasets) == 0 {
+		return e.deliver(ev, data)
+	}
+	// Copy the event before delivering it, which may split or truncate it.
+	mirrorFailure := e.mirror(ev, data)
+	if err := e.deliver(ev, data); err != nil {
+		return err
+	}
+	return mirrorFailure
+}
+
+// deliver transmits an event for a span, subject to the oversized event
+// policy, returning the first failure to do so.
+func (e *Exporter) deliver(ev *libhoney.Event, data *trace.SpanSnapshot) error {
+	span := spanReference(data)
+	if e.oversize == nil {
+		return e.transmit(ev, span, 0)
+	}
+	events, size := e.oversize.check(ev)
+	var failure error
+	for _, ev := range events {
+		if err := e.transmit(ev, span, size); err != nil && failure == nil {
+			failure = err
+		}
+	}
+	return failure
+}
+
+// transmit queues an event for the given span for transmission, reporting
+// any failure to the onError hook as well as returning it. The size is that
+// of the event once serialized, or zero if it isn't yet known.
+func (e *Exporter) transmit(ev *libhoney.Event, span SpanReference, size int) error {
+	// Events created by libhoney take the dataset the exporter was created
+	// with, which Reload may have changed since.
+	if dataset := e.settings().dataset; ev.Dataset == e.dataset && dataset != e.dataset {
+		ev.Dataset = dataset
+	}
+	ev.Metadata = eventMetadata{span: span, dataset: ev.Dataset}
+	if e.auditor != nil {
+		e.auditor.record(ev.Fields())
+	}
+	if size == 0 && (e.flusher != nil || e.volumes != nil) {
+		size = serializedSize(ev.Fields())
+	}
+	var err error
+	atomic.AddUint64(&e.stats.sendsAttempted, 1)
+	// Count the event first, lest its response be counted before it.
+	atomic.AddUint64(&e.txCounters.enqueued, 1)
+	e.flushMu.RLock()
+	if e.flusher != nil {
+		err = e.flusher.send(size, ev.SendPresampled)
+	} else {
+		err = ev.SendPresampled()
+	}
+	e.flushMu.RUnlock()
+	if err != nil {
+		atomic.AddUint64(&e.txCounters.enqueued, ^uint64(0))
+		e.stats.recordFailure(err)
 		e.onError(err)
+		return err
+	}
+	if e.selfMetrics != nil {
+		e.selfMetrics.events.Add(context.Background(), 1)
+	}
+	if e.volumes != nil {
+		e.volumes.record(ev.Dataset, size)
 	}
+	if e.budget != nil {
+		e.budget.record()
+	}
+	return nil
 }
 
 // Shutdown waits for all in-flight messages to be sent. You should
-// call Shutdoown() before app termination.
+// call Shutdoown() before app termination. If the exporter was started with
+// Start, Shutdown also waits for the responses to those messages to be
+// consumed.
+//
+// If ctx is done first, Shutdown stops waiting and returns ctx.Err(), leaving
+// the messages to be sent in the background, so that a slow Honeycomb
+// endpoint can't delay the termination of the process past a deadline.
+//
+// Shutdown may be called only once; later calls return ErrExporterShutdown.
 func (e *Exporter) Shutdown(ctx context.Context) error {
-	e.client.Close()
-	return nil
+	if !atomic.CompareAndSwapInt32(&e.closing, 0, 1) {
+		return ErrExporterShutdown
+	}
+	if e.disabled {
+		return nil
+	}
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		// Wait for calls to ExportSpans and ForceFlush already under way.
+		e.shutdownMu.Lock()
+		defer e.shutdownMu.Unlock()
+		if e.queue != nil {
+			e.queue.close()
+		}
+		if e.tail != nil {
+			e.tail.close()
+		}
+		e.client.Close()
+		if e.diskBuffer != nil {
+			e.diskBuffer.close()
+		}
+	}()
+	var err error
+	select {
+	case <-closed:
+		err = e.awaitResponses(ctx)
+	case <-ctx.Done():
+		// This stops the goroutine started by Start without waiting.
+		e.awaitResponses(ctx)
+		err = ctx.Err()
+	}
+	if e.auditor != nil {
+		e.auditor.close()
+	}
+	if e.truncation != nil {
+		e.truncation.close()
+	}
+	if e.fieldPolicy != nil {
+		e.fieldPolicy.close()
+	}
+	if e.transportSplit != nil {
+		if alternateErr := e.transportSplit.alternate.Shutdown(ctx); alternateErr != nil && err == nil {
+			err = alternateErr
+		}
+	}
+	if e.throttle != nil {
+		e.throttle.flush()
+	}
+	return err
 }
@@ -3,10 +3,12 @@ package honeycomb
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
 
+	libhoney "github.com/honeycombio/libhoney-go"
 	"github.com/honeycombio/libhoney-go/transmission"
 	"github.com/stretchr/testify/assert"
 
@@ -16,6 +18,7 @@ import (
 	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
 	apitrace "go.opentelemetry.io/otel/trace"
 )
 
@@ -198,7 +201,7 @@ func makeTestExporter(mockHoneycomb *transmission.MockSender, opts ...ExporterOp
 		append(opts,
 			TargetingDataset("test"),
 			WithServiceName("opentelemetry-test"),
-			withHoneycombSender(mockHoneycomb))...,
+			WithSender(mockHoneycomb))...,
 	)
 }
 
@@ -398,6 +401,75 @@ func TestHoneycombOutputWithLinks(t *testing.T) {
 	assert.Equal(int64(2), linkFields["two"])
 }
 
+func TestHoneycombOutputWithLinkRefTypes(t *testing.T) {
+	linkTraceID, _ := apitrace.TraceIDFromHex("0102030405060709090a0b0c0d0e0f11")
+	linkSpanID, _ := apitrace.SpanIDFromHex("0102030405060709")
+
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb)
+	assert.Nil(err)
+
+	tr, err := setUpTestProvider(exporter)
+	assert.Nil(err)
+
+	sc := apitrace.SpanContext{TraceID: linkTraceID, SpanID: linkSpanID}
+	_, span := tr.Start(context.TODO(), "myTestSpan", apitrace.WithLinks(
+		apitrace.Link{SpanContext: sc},
+		apitrace.Link{SpanContext: sc, Attributes: []label.KeyValue{LinkRefTypeKey.String("child_of")}},
+		apitrace.Link{SpanContext: sc, Attributes: []label.KeyValue{LinkRefTypeKey.String("follows_from")}},
+	))
+	span.End()
+
+	assert.Len(mockHoneycomb.Events(), 4)
+	var refTypes []interface{}
+	for _, ev := range mockHoneycomb.Events()[:3] {
+		assert.Equal("link", ev.Data["meta.annotation_type"])
+		assert.NotContains(ev.Data, string(LinkRefTypeKey))
+		refTypes = append(refTypes, ev.Data["ref_type"])
+	}
+	assert.Equal([]interface{}{nil, nil, spanRefTypeFollowsFrom}, refTypes)
+}
+
+func TestHoneycombOptionErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := []ExporterOption{
+		TargetingDataset(""),
+		WithSampleRate(0),
+		WithServiceName("valid"),
+		WithOverBudgetSampleRate(10),
+	}
+	_, err := NewExporter(Config{}, opts...)
+	var errs OptionErrors
+	if assert.True(errors.As(err, &errs)) {
+		assert.Equal([]string{
+			"API key must not be empty",
+			"dataset name must not be empty",
+			"sample rate must be positive",
+			"over-budget sample rate requires an event budget",
+		}, errorMessages(errs))
+	}
+
+	err = ValidateOptions(opts...)
+	if assert.True(errors.As(err, &errs)) {
+		assert.Len(errs, 3)
+	}
+	assert.Nil(ValidateOptions(TargetingDataset("test"), WithSampleRate(2)))
+
+	_, err = NewExporter(Config{APIKey: "overridden"}, WithSampleRate(0))
+	assert.EqualError(err, "sample rate must be positive")
+}
+
+func errorMessages(errs []error) []string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return msgs
+}
+
 func TestHoneycombConfigValidation(t *testing.T) {
 	tests := []struct {
 		description string
@@ -693,3 +765,349 @@ func TestHoneycombOutputWithResource(t *testing.T) {
 	assert.Equal(int64(underlay), mainEventFields["b"])
 	assert.Equal(int64(middle), mainEventFields["c"])
 }
+
+func TestHoneycombFieldAudit(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	var reports [][]string
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithField("team", "core"),
+		WithFieldAudit(time.Hour, func(names []string) {
+			reports = append(reports, names)
+		}))
+	assert.Nil(err)
+
+	tr, err := setUpTestProvider(exporter)
+	assert.Nil(err)
+
+	_, span := tr.Start(context.TODO(), "myTestSpan")
+	span.SetAttributes(label.String("ex.com/string", "yes"))
+	span.End()
+
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.Len(reports, 1)
+	assert.Contains(reports[0], "team")
+	assert.Contains(reports[0], "ex.com/string")
+	assert.Contains(reports[0], "trace.span_id")
+	assert.NotContains(reports[0], "yes")
+
+	_, err = makeTestExporter(mockHoneycomb, WithFieldAudit(0, nil))
+	assert.Error(err)
+}
+
+func TestHoneycombOutputWithoutResourceAttributes(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithField("a", 1),
+		WithoutResourceAttributes())
+	assert.Nil(err)
+
+	tr, err := setUpTestProvider(exporter,
+		sdktrace.WithResource(resource.NewWithAttributes(
+			label.String("host.name", "build-17.internal"),
+		)))
+	assert.Nil(err)
+
+	_, span := tr.Start(context.TODO(), "myTestSpan")
+	span.SetAttributes(label.Int64("b", 2))
+	span.AddEvent("something")
+	span.End()
+
+	assert.Len(mockHoneycomb.Events(), 2)
+	for _, ev := range mockHoneycomb.Events() {
+		assert.NotContains(ev.Data, "host.name")
+		assert.Equal(1, ev.Data["a"])
+		assert.Equal("opentelemetry-test", ev.Data["service_name"])
+	}
+	assert.Equal(int64(2), mockHoneycomb.Events()[1].Data["b"])
+}
+
+func TestHoneycombOutputWithSampleRate(t *testing.T) {
+	for _, rate := range []uint{1, 20} {
+		mockHoneycomb := &transmission.MockSender{}
+		assert := assert.New(t)
+
+		tr, err := setUpTestExporter(mockHoneycomb, WithSampleRate(rate))
+		assert.Nil(err)
+
+		_, span := tr.Start(context.TODO(), "myTestSpan")
+		span.AddEvent("something")
+		span.End()
+
+		assert.Len(mockHoneycomb.Events(), 2)
+		for _, ev := range mockHoneycomb.Events() {
+			assert.Equal(rate, ev.SampleRate)
+			assert.Equal(rate, ev.Data["meta.sample_rate"])
+		}
+	}
+
+	_, err := makeTestExporter(&transmission.MockSender{}, WithSampleRate(0))
+	assert.Error(t, err)
+}
+
+func TestHoneycombOutputWithServiceFields(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithField("team", "platform"),
+		WithServiceFields(map[string]map[string]interface{}{
+			"checkout": {
+				"team":    "payments",
+				"on_call": "#payments-oncall",
+			},
+			"opentelemetry-test": {
+				"team": "observability",
+			},
+		}))
+	assert.Nil(err)
+
+	for _, res := range []*resource.Resource{
+		resource.NewWithAttributes(semconv.ServiceNameKey.String("checkout")),
+		resource.NewWithAttributes(semconv.ServiceNameKey.String("inventory")),
+		nil,
+	} {
+		err := exporter.ExportSpans(context.Background(), []*exporttrace.SpanSnapshot{{Name: "span", Resource: res}})
+		assert.Nil(err)
+	}
+
+	events := mockHoneycomb.Events()
+	assert.Len(events, 3)
+	assert.Equal("payments", events[0].Data["team"])
+	assert.Equal("#payments-oncall", events[0].Data["on_call"])
+	assert.Equal("platform", events[1].Data["team"])
+	assert.NotContains(events[1].Data, "on_call")
+	assert.Equal("observability", events[2].Data["team"])
+}
+
+func TestHoneycombOutputWithErrorsDataset(t *testing.T) {
+	for _, includeErrorSpans := range []bool{false, true} {
+		mockHoneycomb := &transmission.MockSender{}
+		assert := assert.New(t)
+
+		tr, err := setUpTestExporter(mockHoneycomb, WithErrorsDataset("errors", includeErrorSpans))
+		assert.Nil(err)
+
+		_, span := tr.Start(context.TODO(), "myTestSpan")
+		span.AddEvent("something")
+		span.RecordError(errors.New("boom"))
+		span.AddEvent("exception", apitrace.WithAttributes(label.String("exception.message", "bang")))
+		span.SetStatus(codes.Error, "boom")
+		span.End()
+
+		datasets := make(map[string][]string)
+		for _, ev := range mockHoneycomb.Events() {
+			datasets[ev.Dataset] = append(datasets[ev.Dataset], ev.Data["name"].(string))
+		}
+		assert.Equal([]string{"something", "error", "exception", "myTestSpan"}, datasets["test"])
+		if includeErrorSpans {
+			assert.Equal([]string{"error", "exception", "myTestSpan"}, datasets["errors"])
+		} else {
+			assert.Equal([]string{"error", "exception"}, datasets["errors"])
+		}
+	}
+}
+
+func TestHoneycombOutputWithErrorStack(t *testing.T) {
+	tests := []struct {
+		description string
+		hook        func(*exporttrace.SpanSnapshot) string
+		status      codes.Code
+		stackEvent  string
+		want        string
+	}{
+		{"custom hook", func(s *exporttrace.SpanSnapshot) string { return "stack of " + s.Name }, codes.Error, "", "stack of myTestSpan"},
+		{"default hook", nil, codes.Error, "", "goroutine"},
+		{"not an error", nil, codes.Ok, "", ""},
+		{"stack already recorded", nil, codes.Error, "exception", ""},
+		{"stack recorded with RecordError", nil, codes.Error, "error", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			mockHoneycomb := &transmission.MockSender{}
+			assert := assert.New(t)
+
+			tr, err := setUpTestExporter(mockHoneycomb, WithErrorStack(test.hook))
+			assert.Nil(err)
+
+			_, span := tr.Start(context.TODO(), "myTestSpan")
+			stack := apitrace.WithAttributes(label.String("exception.stacktrace", "main.main()"))
+			switch test.stackEvent {
+			case "exception":
+				span.AddEvent("exception", stack)
+			case "error":
+				span.RecordError(errors.New("boom"), stack)
+			}
+			span.SetStatus(test.status, "")
+			span.End()
+
+			events := mockHoneycomb.Events()
+			mainEventFields := events[len(events)-1].Data
+			if len(test.want) == 0 {
+				assert.NotContains(mainEventFields, "error.stack")
+			} else {
+				assert.Contains(mainEventFields["error.stack"], test.want)
+			}
+		})
+	}
+}
+
+// discardSender is a transmission.Sender that drops every event, so that
+// benchmarks measure the exporter without accumulating events in memory.
+type discardSender struct{}
+
+func (discardSender) Add(*transmission.Event)                 {}
+func (discardSender) Start() error                            { return nil }
+func (discardSender) Stop() error                             { return nil }
+func (discardSender) TxResponses() chan transmission.Response { return nil }
+func (discardSender) SendResponse(transmission.Response) bool { return false }
+
+func BenchmarkExportSpans(b *testing.B) {
+	traceID, _ := apitrace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := apitrace.SpanIDFromHex("0102030405060708")
+	now := time.Now()
+	attrs := []label.KeyValue{
+		label.String("http.method", "GET"),
+		label.String("http.target", "/checkout"),
+		label.Int("http.status_code", 200),
+		label.Bool("cache.hit", true),
+	}
+	data := &exporttrace.SpanSnapshot{
+		SpanContext: apitrace.SpanContext{
+			TraceID: traceID,
+			SpanID:  spanID,
+		},
+		Name:       "/checkout",
+		StartTime:  now,
+		EndTime:    now.Add(time.Millisecond),
+		Attributes: attrs,
+		MessageEvents: []exporttrace.Event{
+			{Name: "cache lookup", Time: now, Attributes: attrs[3:]},
+		},
+		Resource: resource.NewWithAttributes(semconv.ServiceNameKey.String("checkout")),
+	}
+	batch := []*exporttrace.SpanSnapshot{data}
+
+	exporter, err := NewExporter(Config{APIKey: "overridden"}, WithSender(discardSender{}))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		exporter.ExportSpans(context.Background(), batch)
+	}
+}
+
+func TestHoneycombExportResult(t *testing.T) {
+	assert := assert.New(t)
+
+	var results []ExportResult
+	onResult := CallingOnExportResult(func(r ExportResult) {
+		results = append(results, r)
+	})
+
+	exporter, err := makeTestExporter(&transmission.MockSender{}, onResult)
+	assert.Nil(err)
+	sds := []*exporttrace.SpanSnapshot{{Name: "a"}, {Name: "b"}}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Len(results, 1)
+	assert.Equal(2, results[0].Accepted)
+	assert.Empty(results[0].Failed)
+
+	// An asynchronous exporter whose queue is full can't accept spans.
+	release := make(chan struct{})
+	exporter, err = makeTestExporter(&transmission.MockSender{}, onResult, WithAsyncExport(1, 1),
+		CallingOnError(func(error) {}),
+		WithBeforeSend(func(context.Context, *libhoney.Event, *exporttrace.SpanSnapshot) bool {
+			<-release
+			return true
+		}))
+	assert.Nil(err)
+	defer exporter.Shutdown(context.Background())
+	defer close(release)
+	assert.Nil(exporter.ExportSpans(context.Background(), sds[:1]))
+	assert.Eventually(func() bool {
+		return exporter.QueueStats().Depth == 0
+	}, time.Second, time.Millisecond, "the worker takes the first span")
+	assert.Error(exporter.ExportSpans(context.Background(), []*exporttrace.SpanSnapshot{{Name: "c"}, {Name: "d"}, {Name: "e"}}))
+	if assert.Len(results, 3) {
+		assert.Equal(1, results[2].Accepted)
+		assert.Len(results[2].Failed, 2)
+		assert.Len(results[2].Errors, 2)
+	}
+}
+
+func TestLimitLayeredAttributes(t *testing.T) {
+	underlay := []label.KeyValue{label.Int("a", 1), label.Int("b", 1), label.Int("c", 1)}
+	overlay := []label.KeyValue{label.Int("b", 2), label.Int("d", 2)}
+	tests := []struct {
+		n            int
+		wantUnderlay []label.KeyValue
+		wantOverlay  []label.KeyValue
+	}{
+		{1, nil, overlay[:1]},
+		{2, nil, overlay},
+		{3, underlay[:1], overlay},
+		{4, []label.KeyValue{underlay[0], underlay[2]}, overlay},
+		{5, underlay, overlay},
+	}
+	for _, test := range tests {
+		gotUnderlay, gotOverlay := limitLayeredAttributes(underlay, overlay, test.n)
+		assert.Equal(t, test.wantUnderlay, gotUnderlay, "n=%d", test.n)
+		assert.Equal(t, test.wantOverlay, gotOverlay, "n=%d", test.n)
+	}
+}
+
+func TestHoneycombOutputWithMaxEventAttributeCount(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb, WithMaxEventAttributeCount(2))
+	assert.Nil(err)
+	tr, err := setUpTestProvider(exporter,
+		sdktrace.WithResource(resource.NewWithAttributes(
+			label.String("host.name", "xanadu"),
+			label.String("process.owner", "root"),
+		)))
+	assert.Nil(err)
+
+	_, span := tr.Start(context.TODO(), "myTestSpan")
+	span.SetAttributes(label.Int("a", 1), label.Int("b", 2), label.Int("c", 3))
+	span.AddEvent("something", apitrace.WithAttributes(label.Int("d", 4)))
+	span.End()
+
+	assert.Len(mockHoneycomb.Events(), 2)
+	eventFields := mockHoneycomb.Events()[0].Data
+	assert.Equal(int64(4), eventFields["d"])
+	assert.Equal("xanadu", eventFields["host.name"])
+	assert.NotContains(eventFields, "process.owner")
+
+	mainEventFields := mockHoneycomb.Events()[1].Data
+	for _, key := range []string{"a", "b", "c", "host.name", "process.owner"} {
+		assert.Contains(mainEventFields, key)
+	}
+}
+
+func TestHoneycombOutputChildSpanCount(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+	tr, err := setUpTestExporter(mockHoneycomb)
+	assert.Nil(err)
+
+	ctx, parent := tr.Start(context.TODO(), "parent")
+	for i := 0; i < 2; i++ {
+		_, child := tr.Start(ctx, "child")
+		child.End()
+	}
+	parent.End()
+
+	events := mockHoneycomb.Events()
+	assert.Len(events, 3)
+	assert.Equal(0, events[0].Data["meta.child_span_count"])
+	assert.Equal(0, events[1].Data["meta.child_span_count"])
+	assert.Equal(2, events[2].Data["meta.child_span_count"])
+}
@@ -0,0 +1,102 @@
+package honeycomb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// keyVerificationTimeout bounds the time NewExporter spends verifying the
+// API key when configured with WithKeyVerification.
+const keyVerificationTimeout = 10 * time.Second
+
+// InvalidAPIKeyError is returned by NewExporter, when configured with
+// WithKeyVerification, if Honeycomb rejects the API key or the key may not
+// send events. Errors.Is recognizes it as ErrUnauthorized.
+type InvalidAPIKeyError struct {
+	// StatusCode is the HTTP status with which Honeycomb's authentication
+	// endpoint responded.
+	StatusCode int
+	// MissingPermission names the permission the key lacks, such as
+	// "events," if Honeycomb accepted the key.
+	MissingPermission string
+}
+
+func (e *InvalidAPIKeyError) Error() string {
+	if len(e.MissingPermission) != 0 {
+		return fmt.Sprintf("Honeycomb API key lacks the %q permission; enable it for this API key in the team settings",
+			e.MissingPermission)
+	}
+	return fmt.Sprintf("Honeycomb rejected the API key (HTTP status %d); "+
+		"check that the API key is copied correctly and has not been revoked", e.StatusCode)
+}
+
+// Is reports whether target is ErrUnauthorized.
+func (e *InvalidAPIKeyError) Is(target error) bool {
+	return target == ErrUnauthorized
+}
+
+// WithKeyVerification causes NewExporter to verify the API key with
+// Honeycomb's authentication endpoint, returning an *InvalidAPIKeyError if
+// Honeycomb rejects it or it may not send events, so that a bad key is found
+// at startup rather than hours later in the error log. The team and
+// environment to which the key belongs are then reported by Exporter.Team and
+// Exporter.Environment. If the key can't be verified, such as when the API
+// server is unreachable, NewExporter reports the reason to the error hook
+// and proceeds.
+func WithKeyVerification() ExporterOption {
+	return func(c *exporterConfig) error {
+		c.keyVerification = true
+		return nil
+	}
+}
+
+// verifyAPIKey performs the check requested by WithKeyVerification,
+// returning the authentication endpoint's response if it succeeds.
+func verifyAPIKey(apiURL, apiKey string, transport http.RoundTripper, onError func(error)) (authResponse, error) {
+	var auth authResponse
+	authURL, err := apiEndpoint(apiURL, "1", "auth")
+	if err != nil {
+		return auth, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), keyVerificationTimeout)
+	defer cancel()
+	resp, body, err := apiRequest(ctx, &http.Client{Transport: transport}, apiKey, authURL)
+	if err != nil {
+		onError(fmt.Errorf("API key verification failed: %w", err))
+		return auth, nil
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return auth, &InvalidAPIKeyError{StatusCode: resp.StatusCode}
+	default:
+		onError(fmt.Errorf("API key verification failed: unexpected HTTP status %d: %s",
+			resp.StatusCode, strings.TrimSpace(string(body))))
+		return auth, nil
+	}
+	if err := json.Unmarshal(body, &auth); err != nil {
+		onError(fmt.Errorf("API key verification failed to parse the authentication response: %w", err))
+		return authResponse{}, nil
+	}
+	if auth.APIKeyAccess != nil && !auth.APIKeyAccess["events"] {
+		return auth, &InvalidAPIKeyError{StatusCode: resp.StatusCode, MissingPermission: "events"}
+	}
+	return auth, nil
+}
+
+// Team returns the slug of the team to which the exporter's API key belongs,
+// as discovered by WithKeyVerification, or an empty string if unknown.
+func (e *Exporter) Team() string {
+	return e.team
+}
+
+// Environment returns the slug of the environment to which the exporter's API
+// key belongs, as discovered by WithKeyVerification, or an empty string if
+// unknown, as it is for keys of Honeycomb Classic.
+func (e *Exporter) Environment() string {
+	return e.environment
+}
@@ -0,0 +1,69 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyVerification(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("/1/auth", r.URL.Path)
+		switch r.Header.Get("X-Honeycomb-Team") {
+		case "good":
+			io.WriteString(w, `{"api_key_access": {"events": true}, "team": {"slug": "acme"}, "environment": {"slug": "prod"}}`)
+		case "read-only":
+			io.WriteString(w, `{"api_key_access": {"events": false}, "team": {"slug": "acme"}}`)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	exporter, err := NewExporter(Config{APIKey: "good"}, WithAPIURL(server.URL), WithKeyVerification())
+	if assert.Nil(err) {
+		assert.Equal("acme", exporter.Team())
+		assert.Equal("prod", exporter.Environment())
+		assert.Nil(exporter.Shutdown(context.Background()))
+	}
+
+	_, err = NewExporter(Config{APIKey: "bad"}, WithAPIURL(server.URL), WithKeyVerification())
+	var invalid *InvalidAPIKeyError
+	if assert.True(errors.As(err, &invalid)) {
+		assert.Equal(http.StatusUnauthorized, invalid.StatusCode)
+	}
+	assert.True(errors.Is(err, ErrUnauthorized))
+
+	_, err = NewExporter(Config{APIKey: "read-only"}, WithAPIURL(server.URL), WithKeyVerification())
+	if assert.True(errors.As(err, &invalid)) {
+		assert.Equal("events", invalid.MissingPermission)
+	}
+}
+
+func TestKeyVerificationUnreachable(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	var errs []error
+	exporter, err := NewExporter(Config{APIKey: "unverified"},
+		WithAPIURL(server.URL),
+		WithKeyVerification(),
+		WithAutoStart(false),
+		CallingOnError(func(err error) {
+			errs = append(errs, err)
+		}))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Len(errs, 1)
+	assert.Empty(exporter.Team())
+	assert.Empty(exporter.Environment())
+	assert.Nil(exporter.Shutdown(context.Background()))
+}
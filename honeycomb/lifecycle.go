@@ -0,0 +1,192 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+
+	"go.opentelemetry.io/otel"
+)
+
+// errAlreadyStarted is returned by Start when called more than once.
+var errAlreadyStarted = errors.New("exporter already started")
+
+// ErrExporterShutdown is returned by ExportSpans, ForceFlush, and Shutdown
+// when called after the exporter has been shut down.
+var ErrExporterShutdown = errors.New("exporter is shut down")
+
+// beginUse reports whether the exporter is still running, in which case it
+// must not be shut down until the caller calls endUse.
+func (e *Exporter) beginUse() bool {
+	e.shutdownMu.RLock()
+	if atomic.LoadInt32(&e.closing) != 0 {
+		e.shutdownMu.RUnlock()
+		return false
+	}
+	return true
+}
+
+// endUse ends a use of the exporter begun by beginUse.
+func (e *Exporter) endUse() {
+	e.shutdownMu.RUnlock()
+}
+
+// handleError is the default error hook, passing errors to the
+// OpenTelemetry error handler set with otel.SetErrorHandler, which by
+// default logs them.
+func handleError(err error) {
+	otel.Handle(fmt.Errorf("sending spans to Honeycomb: %w", err))
+}
+
+// Start starts a goroutine that consumes the responses to the requests
+// sending events to Honeycomb, reporting failures to the error hook, as
+// RunErrorLogger does, until the exporter is shut down or ctx is canceled.
+// Unlike a goroutine running RunErrorLogger, it survives panics in the error
+// hook, reporting them to the OpenTelemetry error handler, as well as the
+// flushes done for WithMaxBatchBytes and WithDeterministicOrdering, which
+// replace the queue of responses, and Shutdown waits for it to consume the
+// responses to the last requests.
+//
+// Unless configured with WithAutoStart(false), NewExporter starts the
+// goroutine itself, with a context that is never canceled, in which case the
+// first call to Start does nothing and returns nil.
+//
+// Start may be called only once, and not in combination with
+// RunErrorLogger.
+func (e *Exporter) Start(ctx context.Context) error {
+	e.lifecycleMu.Lock()
+	defer e.lifecycleMu.Unlock()
+	if e.started {
+		if e.autoStarted {
+			e.autoStarted = false
+			return nil
+		}
+		return errAlreadyStarted
+	}
+	e.started = true
+	if e.disabled {
+		return nil
+	}
+	e.startLogger(ctx)
+	return nil
+}
+
+// WithAutoStart specifies whether NewExporter starts the goroutine that
+// consumes the responses to the exporter's requests, as Start does, so that
+// failures reach the error hook without further ado. It does by default.
+// Disable it to consume the responses with RunErrorLogger, or to start the
+// goroutine with a context of your own.
+func WithAutoStart(enabled bool) ExporterOption {
+	return func(c *exporterConfig) error {
+		c.manualStart = !enabled
+		return nil
+	}
+}
+
+// autoStart starts the goroutine run by Start on behalf of NewExporter.
+func (e *Exporter) autoStart() {
+	e.lifecycleMu.Lock()
+	defer e.lifecycleMu.Unlock()
+	e.started = true
+	e.autoStarted = true
+	e.startLogger(context.Background())
+}
+
+// startLogger starts the goroutine run by Start. The caller must hold
+// lifecycleMu.
+func (e *Exporter) startLogger(ctx context.Context) {
+	ctx, e.stopLogger = context.WithCancel(ctx)
+	e.loggerDone = make(chan struct{})
+	// Take the queue of responses now, lest a flush replace it, with
+	// responses in it, before the goroutine starts.
+	responses := e.txResponses()
+	go func() {
+		defer close(e.loggerDone)
+		for e.drainResponses(ctx, responses) {
+			responses = e.txResponses()
+		}
+		e.drainQueuedResponses(e.txResponses())
+	}()
+}
+
+// syncResponses waits for the goroutine run by Start, if running, to finish
+// handling any response it has taken and to consume those already queued,
+// so that the hooks have seen the responses to the events flushed before.
+func (e *Exporter) syncResponses() {
+	e.lifecycleMu.Lock()
+	done := e.loggerDone
+	e.lifecycleMu.Unlock()
+	if done == nil {
+		return
+	}
+	synced := make(chan struct{})
+	select {
+	case e.responseSync <- synced:
+		<-synced
+	case <-done:
+	}
+}
+
+// recoverErrorHook reports a panic in the error hook to the OpenTelemetry
+// error handler, reporting whether there was one.
+func recoverErrorHook(r interface{}) bool {
+	if r == nil {
+		return false
+	}
+	otel.Handle(fmt.Errorf("honeycomb error hook panicked: %v", r))
+	return true
+}
+
+// drainResponses consumes responses until the queue of responses is closed
+// or ctx is canceled, reporting whether to resume consuming them, from the
+// queue replacing it, because the exporter is still running.
+func (e *Exporter) drainResponses(ctx context.Context, responses chan transmission.Response) (resume bool) {
+	defer func() {
+		if recoverErrorHook(recover()) {
+			resume = true
+		}
+	}()
+	e.consumeResponses(ctx, responses)
+	return ctx.Err() == nil && atomic.LoadInt32(&e.closing) == 0
+}
+
+// drainQueuedResponses consumes the responses already queued, without
+// waiting for more.
+func (e *Exporter) drainQueuedResponses(responses chan transmission.Response) {
+	defer func() {
+		recoverErrorHook(recover())
+	}()
+	for {
+		select {
+		case r, ok := <-responses:
+			if !ok {
+				return
+			}
+			e.handleResponse(r)
+		default:
+			return
+		}
+	}
+}
+
+// awaitResponses stops the goroutine started by Start, once the exporter's
+// client is closed, and waits until it has consumed the responses to the
+// exporter's requests, or until ctx is done.
+func (e *Exporter) awaitResponses(ctx context.Context) error {
+	e.lifecycleMu.Lock()
+	done, stop := e.loggerDone, e.stopLogger
+	e.lifecycleMu.Unlock()
+	if done == nil {
+		return nil
+	}
+	stop()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
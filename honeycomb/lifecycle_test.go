@@ -0,0 +1,164 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestExporterStart(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{BlockOnResponses: true}
+	errs := make(chan error, 3)
+	panicked := false
+	exporter, err := makeTestExporter(mockHoneycomb, CallingOnError(func(err error) {
+		if !panicked {
+			panicked = true
+			panic("hook failed")
+		}
+		errs <- err
+	}))
+	assert.Nil(err)
+
+	assert.Nil(exporter.Start(context.Background()))
+	assert.Error(exporter.Start(context.Background()))
+
+	mockHoneycomb.SendResponse(transmission.Response{Err: errors.New("first")})
+	mockHoneycomb.SendResponse(transmission.Response{Err: errors.New("second")})
+	select {
+	case err := <-errs:
+		assert.EqualError(err, "second", "the goroutine survives panics in the hook")
+	case <-time.After(time.Second):
+		t.Fatal("expected an error")
+	}
+
+	// Responses queued when the exporter shuts down are still reported.
+	mockHoneycomb.SendResponse(transmission.Response{Err: errors.New("last")})
+	assert.Nil(exporter.Shutdown(context.Background()))
+	select {
+	case err := <-errs:
+		assert.EqualError(err, "last")
+	default:
+		t.Fatal("expected the last error before Shutdown returned")
+	}
+}
+
+func TestAutoStart(t *testing.T) {
+	assert := assert.New(t)
+	for _, autoStart := range []bool{true, false} {
+		mockHoneycomb := &transmission.MockSender{}
+		errs := make(chan error, 1)
+		exporter, err := makeTestExporter(mockHoneycomb,
+			WithAutoStart(autoStart),
+			CallingOnError(func(err error) {
+				errs <- err
+			}))
+		if !assert.Nil(err) {
+			return
+		}
+		mockHoneycomb.SendResponse(transmission.Response{Err: errors.New("rejected")})
+		if autoStart {
+			select {
+			case err := <-errs:
+				assert.EqualError(err, "rejected")
+			case <-time.After(time.Second):
+				t.Fatal("expected an error without calling Start")
+			}
+			// Starting the exporter explicitly as well is harmless.
+			assert.Nil(exporter.Start(context.Background()))
+		} else {
+			select {
+			case err := <-errs:
+				t.Fatalf("unexpected error before calling Start: %v", err)
+			case <-time.After(10 * time.Millisecond):
+			}
+			assert.Nil(exporter.Start(context.Background()))
+			assert.EqualError(<-errs, "rejected")
+		}
+		assert.Error(exporter.Start(context.Background()))
+		assert.Nil(exporter.Shutdown(context.Background()))
+	}
+}
+
+func TestDisabledExporterStart(t *testing.T) {
+	exporter, err := NewExporter(Config{}, WithDisabled(true))
+	assert.Nil(t, err)
+	assert.Nil(t, exporter.Start(context.Background()))
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	release := make(chan struct{})
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithAsyncExport(16, 1),
+		WithBeforeSend(func(context.Context, *libhoney.Event, *trace.SpanSnapshot) bool {
+			<-release
+			return true
+		}))
+	assert.Nil(err)
+	assert.Nil(exporter.Start(context.Background()))
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "slow"}}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(context.DeadlineExceeded, exporter.Shutdown(ctx))
+
+	// The span is still sent in the background.
+	close(release)
+	assert.Eventually(func() bool {
+		return len(mockHoneycomb.Events()) == 1
+	}, time.Second, time.Millisecond)
+}
+
+func TestUseAfterShutdown(t *testing.T) {
+	for _, opts := range [][]ExporterOption{nil, {WithDisabled(true)}} {
+		exporter, err := makeTestExporter(&transmission.MockSender{}, opts...)
+		assert.Nil(t, err)
+		assert.Nil(t, exporter.Shutdown(context.Background()))
+		assert.Equal(t, ErrExporterShutdown, exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "late"}}))
+		assert.Equal(t, ErrExporterShutdown, exporter.ForceFlush(context.Background()))
+		assert.Equal(t, ErrExporterShutdown, exporter.Shutdown(context.Background()))
+	}
+}
+
+func TestExportSpansRacingShutdown(t *testing.T) {
+	// libhoney's own transmission panics if given events once closed.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "[]")
+	}))
+	defer server.Close()
+	exporter, err := NewExporter(Config{APIKey: "overridden"}, WithAPIURL(server.URL))
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				err := exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "racing"}})
+				if err == ErrExporterShutdown {
+					return
+				}
+				assert.Nil(t, err)
+				exporter.ForceFlush(context.Background())
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+	wg.Wait()
+}
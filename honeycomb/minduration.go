@@ -0,0 +1,59 @@
+package honeycomb
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// WithMinSpanDuration causes the exporter to drop spans that last less than
+// d, along with their span events and links, unless they are errors or the
+// roots of their traces. Services that record very many short internal
+// spans, such as those of cache lookups taking a few microseconds, can cut
+// their event volume substantially this way. Because the events for the
+// children of dropped spans still refer to them as parents, traces showing
+// them have gaps, so d should be well below the durations of interest.
+//
+// The exporter counts the spans it drops, reporting the count from
+// ShortSpansDropped.
+func WithMinSpanDuration(d time.Duration) ExporterOption {
+	return func(c *exporterConfig) error {
+		if d <= 0 {
+			return errors.New("minimum span duration must be positive")
+		}
+		c.minSpanDuration = d
+		return nil
+	}
+}
+
+// shortSpanCounter counts the spans dropped for being too short. It's
+// allocated apart from the Exporter so that its count is 64-bit aligned, as
+// the atomic operations on it require on 32-bit platforms.
+type shortSpanCounter struct {
+	dropped uint64
+}
+
+// tooShort reports whether to drop a span for lasting less than the minimum
+// span duration, counting it if so.
+func (e *Exporter) tooShort(data *trace.SpanSnapshot) bool {
+	if e.minSpanDuration <= 0 ||
+		data.EndTime.Sub(data.StartTime) >= e.minSpanDuration ||
+		data.StatusCode == codes.Error ||
+		!data.ParentSpanID.IsValid() {
+		return false
+	}
+	atomic.AddUint64(&e.shortSpans.dropped, 1)
+	return true
+}
+
+// ShortSpansDropped returns the number of spans the exporter has dropped for
+// lasting less than the duration given to WithMinSpanDuration.
+func (e *Exporter) ShortSpansDropped() uint64 {
+	if e.shortSpans == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&e.shortSpans.dropped)
+}
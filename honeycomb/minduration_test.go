@@ -0,0 +1,56 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestMinSpanDuration(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := makeTestExporter(mockHoneycomb, WithMinSpanDuration(time.Millisecond))
+	assert.Nil(err)
+
+	start := time.Now()
+	span := func(name string, d time.Duration, root bool) *trace.SpanSnapshot {
+		s := &trace.SpanSnapshot{
+			SpanContext: apitrace.SpanContext{TraceID: apitrace.TraceID{1}, SpanID: apitrace.SpanID{2}},
+			Name:        name,
+			StartTime:   start,
+			EndTime:     start.Add(d),
+		}
+		if !root {
+			s.ParentSpanID = apitrace.SpanID{3}
+		}
+		return s
+	}
+	failed := span("failed", time.Microsecond, false)
+	failed.StatusCode = codes.Error
+	short := span("short", time.Microsecond, false)
+	short.MessageEvents = []trace.Event{{Name: "event", Time: start}}
+
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{
+		short,
+		span("long", time.Millisecond, false),
+		span("root", time.Microsecond, true),
+		failed,
+	}))
+
+	var names []interface{}
+	for _, ev := range mockHoneycomb.Events() {
+		names = append(names, ev.Data["name"])
+	}
+	assert.Equal([]interface{}{"long", "root", "failed"}, names)
+	assert.Equal(uint64(1), exporter.ShortSpansDropped())
+
+	_, err = makeTestExporter(mockHoneycomb, WithMinSpanDuration(0))
+	assert.Error(err)
+}
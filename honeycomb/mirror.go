@@ -0,0 +1,65 @@
+package honeycomb
+
+import (
+	"errors"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// mirrorDataset is a dataset given to WithMirrorDataset.
+type mirrorDataset struct {
+	dataset    string
+	sampleRate uint
+}
+
+// WithMirrorDataset causes the exporter to send a copy of each event for a
+// span to another dataset as well, sampled at the given rate, so that, for
+// instance, every event can go to a dataset with short retention while a
+// sample goes to one with long retention, without converting the spans
+// twice. A rate of one copies every event. Whether it copies a span's event
+// depends only on its trace ID, the dataset and the rate, independently of
+// any sampler's decision, so traces are copied or not whole. The option may
+// be given more than once to copy events to several datasets.
+//
+// Only the events sent to the span's own dataset, whether the one given by
+// TargetingDataset or one chosen by options such as WithDatasetTemplate, are
+// copied, after any hook given to WithBeforeSend has approved them; those sent
+// to other datasets by options such as WithErrorsDataset are not.
+func WithMirrorDataset(dataset string, sampleRate uint) ExporterOption {
+	return func(c *exporterConfig) error {
+		if len(dataset) == 0 {
+			return errors.New("mirror dataset name must not be empty")
+		}
+		if sampleRate == 0 {
+			return errors.New("mirror dataset sample rate must be positive")
+		}
+		c.mirrorDatasets = append(c.mirrorDatasets, mirrorDataset{dataset, sampleRate})
+		return nil
+	}
+}
+
+// mirror sends copies of a span's event to the datasets given to
+// WithMirrorDataset, returning the first failure to do so.
+func (e *Exporter) mirror(ev *libhoney.Event, data *trace.SpanSnapshot) error {
+	var failure error
+	for _, m := range e.mirrorDatasets {
+		if !exporterSampled(data.SpanContext.TraceID, "mirror:"+m.dataset, m.sampleRate) {
+			continue
+		}
+		c := e.copyEvent(ev, m.dataset)
+		if m.sampleRate > 1 {
+			rate := c.SampleRate
+			if rate == 0 {
+				rate = 1
+			}
+			rate *= m.sampleRate
+			c.SampleRate = rate
+			c.AddField(sampleRateField, rate)
+		}
+		if err := e.deliver(c, data); err != nil && failure == nil {
+			failure = err
+		}
+	}
+	return failure
+}
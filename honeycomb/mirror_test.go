@@ -0,0 +1,57 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestMirrorDataset(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithMirrorDataset("firehose", 1),
+		WithMirrorDataset("archive", 10),
+		WithSampleRate(2))
+	if !assert.Nil(err) {
+		return
+	}
+	var spans []*trace.SpanSnapshot
+	for i := 0; i < 100; i++ {
+		spans = append(spans, &trace.SpanSnapshot{
+			Name:          "mirrored",
+			SpanContext:   apitrace.SpanContext{TraceID: apitrace.TraceID{byte(i), byte(i * 13)}},
+			MessageEvents: []trace.Event{{Name: "annotation"}},
+		})
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), spans))
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	counts := make(map[string]int)
+	for _, ev := range mockHoneycomb.Events() {
+		counts[ev.Dataset]++
+		switch ev.Dataset {
+		case "archive":
+			assert.Equal(uint(20), ev.SampleRate)
+			assert.Equal(uint(20), ev.Data[sampleRateField])
+		default:
+			assert.Equal(uint(2), ev.SampleRate)
+		}
+	}
+	assert.Equal(200, counts["test"])
+	assert.Equal(200, counts["firehose"])
+	assert.True(counts["archive"] > 0 && counts["archive"] < 60, counts["archive"])
+	assert.Equal(0, counts["archive"]%2, "span events are kept with their spans")
+}
+
+func TestMirrorDatasetInvalid(t *testing.T) {
+	_, err := makeTestExporter(&transmission.MockSender{}, WithMirrorDataset("", 1))
+	assert.Error(t, err)
+	_, err = makeTestExporter(&transmission.MockSender{}, WithMirrorDataset("archive", 0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,189 @@
+package honeycomb
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// fieldMapping copies the value of the first present source field to a
+// destination field.
+type fieldMapping struct {
+	to   string
+	from []string
+}
+
+func applyFieldMappings(ev *libhoney.Event, mappings []fieldMapping) {
+	fields := ev.Fields()
+	for _, m := range mappings {
+		for _, name := range m.from {
+			if v, ok := fields[name]; ok {
+				ev.AddField(m.to, v)
+				break
+			}
+		}
+	}
+}
+
+// httpFieldMappings relates the HTTP semantic convention attributes, in both
+// their older and newer forms, to the fields used by the Beelines.
+var httpFieldMappings = []fieldMapping{
+	{"request.method", []string{"http.request.method", "http.method"}},
+	{"request.host", []string{"server.address", "http.host", "net.host.name"}},
+	{"request.url", []string{"url.full", "http.url"}},
+	{"request.query", []string{"url.query"}},
+	{"request.route", []string{"http.route"}},
+	{"request.http_version", []string{"network.protocol.version", "http.flavor"}},
+	{"request.remote_addr", []string{"client.address", "http.client_ip", "net.peer.ip"}},
+	{"request.header.user_agent", []string{"user_agent.original", "http.user_agent"}},
+	{"request.content_length", []string{"http.request.body.size", "http.request_content_length"}},
+	{"response.status_code", []string{"http.response.status_code", "http.status_code"}},
+	{"response.content_length", []string{"http.response.body.size", "http.response_content_length"}},
+}
+
+// normalizeHTTPFields adds Beeline-style request and response fields to an
+// event based on the HTTP semantic convention attributes it carries.
+func normalizeHTTPFields(ev *libhoney.Event, _ *trace.SpanSnapshot) {
+	applyFieldMappings(ev, httpFieldMappings)
+
+	fields := ev.Fields()
+	if _, ok := fields["request.path"]; ok {
+		return
+	}
+	if path, ok := fields["url.path"].(string); ok {
+		ev.AddField("request.path", path)
+	} else if target, ok := fields["http.target"].(string); ok {
+		if i := strings.IndexByte(target, '?'); i >= 0 {
+			if _, ok := fields["request.query"]; !ok {
+				ev.AddField("request.query", target[i+1:])
+			}
+			target = target[:i]
+		}
+		ev.AddField("request.path", target)
+	}
+}
+
+// WithHTTPFieldNormalization causes the exporter to add the fields the
+// Beelines use for HTTP requests, such as "request.method," "request.path,"
+// "request.host," and "response.status_code," to the events for spans with
+// the corresponding OpenTelemetry HTTP semantic convention attributes, such
+// as "http.method" or "http.request.method." The original attributes are
+// sent as well. This keeps boards and triggers built on data from the
+// Beelines working after migrating to OpenTelemetry.
+func WithHTTPFieldNormalization() ExporterOption {
+	return func(c *exporterConfig) error {
+		c.processors = append(c.processors, transformProcessor(normalizeHTTPFields))
+		return nil
+	}
+}
+
+// databaseFieldMappings relates the database semantic convention attributes,
+// in both their older and newer forms, to one consistent set of fields.
+var databaseFieldMappings = []fieldMapping{
+	{"db.system", []string{"db.system.name"}},
+	{"db.query", []string{"db.query.text", "db.statement"}},
+	{"db.name", []string{"db.namespace"}},
+	{"db.operation", []string{"db.operation.name"}},
+	{"db.table", []string{"db.collection.name", "db.sql.table"}},
+}
+
+// normalizeDatabaseFields adds consistent database fields to an event based
+// on the database semantic convention attributes it carries.
+func normalizeDatabaseFields(ev *libhoney.Event, _ *trace.SpanSnapshot) {
+	applyFieldMappings(ev, databaseFieldMappings)
+}
+
+// WithDatabaseFieldNormalization causes the exporter to add a consistent set
+// of fields, "db.system," "db.query," "db.name," "db.operation," and
+// "db.table," to the events for spans with the corresponding OpenTelemetry
+// database semantic convention attributes, in either their older or newer
+// forms, such as "db.statement" or "db.query.text." The original attributes
+// are sent as well.
+func WithDatabaseFieldNormalization() ExporterOption {
+	return func(c *exporterConfig) error {
+		c.processors = append(c.processors, transformProcessor(normalizeDatabaseFields))
+		return nil
+	}
+}
+
+// messagingFieldMappings relates the messaging semantic convention
+// attributes, in both their older and newer forms, to one stable set of
+// fields.
+var messagingFieldMappings = []fieldMapping{
+	{"messaging.system", []string{"messaging.system"}},
+	{"messaging.destination", []string{"messaging.destination.name", "messaging.destination"}},
+	{"messaging.operation", []string{"messaging.operation.name", "messaging.operation.type", "messaging.operation"}},
+}
+
+// queueLatencyField is the field holding the time, in milliseconds, between
+// a message being produced and the start of the span consuming it.
+const queueLatencyField = "messaging.queue_latency_ms"
+
+// defaultProducerTimestampKeys are the attributes that messaging
+// instrumentations commonly use to record when a message was produced.
+var defaultProducerTimestampKeys = []label.Key{
+	"messaging.message.publish_time",
+	"messaging.kafka.message.timestamp",
+	"messaging.aws.sqs.sent_timestamp",
+}
+
+// messagingFieldNormalizer returns a transform that adds the stable
+// messaging fields to an event, along with the queue latency when one of the
+// given attributes records when the message was produced.
+func messagingFieldNormalizer(producerTimestampKeys []label.Key) func(*libhoney.Event, *trace.SpanSnapshot) {
+	return func(ev *libhoney.Event, data *trace.SpanSnapshot) {
+		if isAnnotationEvent(ev) {
+			return
+		}
+		applyFieldMappings(ev, messagingFieldMappings)
+
+		for _, key := range producerTimestampKeys {
+			produced, ok := timestampFromAttributes(data.Attributes, key)
+			if !ok {
+				continue
+			}
+			// Skip latencies that clock skew has made negative.
+			if latency := data.StartTime.Sub(produced); latency >= 0 {
+				ev.AddField(queueLatencyField, float64(latency)/float64(time.Millisecond))
+			}
+			return
+		}
+	}
+}
+
+// WithMessagingFieldNormalization causes the exporter to add a stable set of
+// fields, "messaging.system," "messaging.destination," and
+// "messaging.operation," to the events for spans with the corresponding
+// OpenTelemetry messaging semantic convention attributes, in either their
+// older or newer forms. The original attributes are sent as well.
+//
+// When a span carries an attribute recording when its message was produced,
+// the exporter also adds a "messaging.queue_latency_ms" field with the time
+// between then and the start of the span. The producerTimestampKeys name the
+// attributes to consult, in order of preference; if none are given, the
+// exporter consults "messaging.message.publish_time,"
+// "messaging.kafka.message.timestamp," and
+// "messaging.aws.sqs.sent_timestamp." These attributes may hold either an
+// RFC 3339 timestamp or a number of seconds, milliseconds, microseconds, or
+// nanoseconds since the Unix epoch.
+func WithMessagingFieldNormalization(producerTimestampKeys ...string) ExporterOption {
+	return func(c *exporterConfig) error {
+		keys := defaultProducerTimestampKeys
+		if len(producerTimestampKeys) > 0 {
+			keys = make([]label.Key, len(producerTimestampKeys))
+			for i, k := range producerTimestampKeys {
+				if len(k) == 0 {
+					return errors.New("messaging producer timestamp attribute name must not be empty")
+				}
+				keys[i] = label.Key(k)
+			}
+		}
+		c.processors = append(c.processors, transformProcessor(messagingFieldNormalizer(keys)))
+		return nil
+	}
+}
@@ -0,0 +1,196 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestHoneycombOutputWithHTTPFieldNormalization(t *testing.T) {
+	tests := []struct {
+		description string
+		attrs       []label.KeyValue
+		want        map[string]interface{}
+		unwanted    []string
+	}{
+		{
+			"older conventions",
+			[]label.KeyValue{
+				label.String("http.method", "GET"),
+				label.String("http.target", "/cart?item=42"),
+				label.String("http.host", "shop.example.com"),
+				label.Int("http.status_code", 200),
+				label.String("http.user_agent", "curl/7.64.1"),
+			},
+			map[string]interface{}{
+				"request.method":            "GET",
+				"request.path":              "/cart",
+				"request.query":             "item=42",
+				"request.host":              "shop.example.com",
+				"response.status_code":      int64(200),
+				"request.header.user_agent": "curl/7.64.1",
+				"http.method":               "GET",
+			},
+			[]string{"request.url"},
+		},
+		{
+			"newer conventions",
+			[]label.KeyValue{
+				label.String("http.request.method", "POST"),
+				label.String("url.path", "/checkout"),
+				label.String("url.full", "https://shop.example.com/checkout"),
+				label.Int("http.response.status_code", 502),
+			},
+			map[string]interface{}{
+				"request.method":       "POST",
+				"request.path":         "/checkout",
+				"request.url":          "https://shop.example.com/checkout",
+				"response.status_code": int64(502),
+			},
+			[]string{"request.query", "request.host"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			mockHoneycomb := &transmission.MockSender{}
+			assert := assert.New(t)
+
+			tr, err := setUpTestExporter(mockHoneycomb, WithHTTPFieldNormalization())
+			assert.Nil(err)
+
+			_, span := tr.Start(context.TODO(), "myTestSpan")
+			span.SetAttributes(test.attrs...)
+			span.End()
+
+			assert.Len(mockHoneycomb.Events(), 1)
+			fields := mockHoneycomb.Events()[0].Data
+			for name, value := range test.want {
+				assert.Equal(value, fields[name], name)
+			}
+			for _, name := range test.unwanted {
+				assert.NotContains(fields, name)
+			}
+		})
+	}
+}
+
+func TestHoneycombOutputWithDatabaseFieldNormalization(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	tr, err := setUpTestExporter(mockHoneycomb, WithDatabaseFieldNormalization())
+	assert.Nil(err)
+
+	_, span := tr.Start(context.TODO(), "myTestSpan")
+	span.SetAttributes(
+		label.String("db.system.name", "mysql"),
+		label.String("db.query.text", "SELECT 1"),
+		label.String("db.namespace", "shop"),
+		label.String("db.sql.table", "orders"),
+	)
+	span.End()
+
+	assert.Len(mockHoneycomb.Events(), 1)
+	fields := mockHoneycomb.Events()[0].Data
+	assert.Equal("mysql", fields["db.system"])
+	assert.Equal("SELECT 1", fields["db.query"])
+	assert.Equal("shop", fields["db.name"])
+	assert.Equal("orders", fields["db.table"])
+	assert.Equal("SELECT 1", fields["db.query.text"])
+	assert.NotContains(fields, "db.operation")
+}
+
+func TestHoneycombOutputWithMessagingFieldNormalization(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	tr, err := setUpTestExporter(mockHoneycomb, WithMessagingFieldNormalization())
+	assert.Nil(err)
+
+	start := time.Now()
+	produced := start.Add(-1500 * time.Millisecond)
+	_, span := tr.Start(context.TODO(), "orders receive",
+		apitrace.WithSpanKind(apitrace.SpanKindConsumer),
+		apitrace.WithTimestamp(start))
+	span.SetAttributes(
+		label.String("messaging.system", "kafka"),
+		label.String("messaging.destination.name", "orders"),
+		label.String("messaging.operation.type", "receive"),
+		label.Int64("messaging.kafka.message.timestamp", produced.UnixNano()/int64(time.Millisecond)),
+	)
+	span.End()
+
+	assert.Len(mockHoneycomb.Events(), 1)
+	fields := mockHoneycomb.Events()[0].Data
+	assert.Equal("kafka", fields["messaging.system"])
+	assert.Equal("orders", fields["messaging.destination"])
+	assert.Equal("receive", fields["messaging.operation"])
+	assert.InDelta(1500, fields["messaging.queue_latency_ms"], 1)
+}
+
+func TestHoneycombOutputWithMessagingFieldNormalizationCustomKey(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	_, err := setUpTestExporter(mockHoneycomb, WithMessagingFieldNormalization(""))
+	assert.NotNil(err)
+
+	tr, err := setUpTestExporter(mockHoneycomb, WithMessagingFieldNormalization("app.enqueued_at"))
+	assert.Nil(err)
+
+	start := time.Now()
+	_, span := tr.Start(context.TODO(), "job process", apitrace.WithTimestamp(start))
+	span.SetAttributes(
+		label.String("app.enqueued_at", start.Add(-time.Second).Format(time.RFC3339Nano)),
+		label.Int64("messaging.kafka.message.timestamp", start.Add(-time.Hour).UnixNano()),
+	)
+	span.End()
+
+	assert.Len(mockHoneycomb.Events(), 1)
+	fields := mockHoneycomb.Events()[0].Data
+	assert.InDelta(1000, fields["messaging.queue_latency_ms"], 1)
+	assert.NotContains(fields, "messaging.system")
+}
+
+func TestHoneycombOutputWithMessagingFieldNormalizationAnnotations(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb, WithMessagingFieldNormalization())
+	assert.Nil(err)
+
+	start := time.Now()
+	produced := start.Add(-time.Second)
+	sds := []*exporttrace.SpanSnapshot{{
+		Name:      "orders receive",
+		StartTime: start,
+		EndTime:   start.Add(time.Millisecond),
+		Attributes: []label.KeyValue{
+			label.String("messaging.system", "kafka"),
+			label.Int64("messaging.kafka.message.timestamp", produced.UnixNano()/int64(time.Millisecond)),
+		},
+		MessageEvents: []exporttrace.Event{{Name: "ack", Time: start}},
+		Links:         []apitrace.Link{{}},
+	}}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	events := mockHoneycomb.Events()
+	assert.Len(events, 3)
+	for _, ev := range events {
+		if ev.Data["meta.annotation_type"] != nil {
+			assert.NotContains(ev.Data, queueLatencyField)
+			assert.NotContains(ev.Data, "messaging.system")
+			continue
+		}
+		assert.Equal("kafka", ev.Data["messaging.system"])
+		assert.InDelta(1000, ev.Data[queueLatencyField], 1)
+	}
+}
@@ -0,0 +1,42 @@
+package honeycomb
+
+import (
+	"bytes"
+	"sort"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// WithDeterministicOrdering causes the exporter to send the events for each
+// batch of spans in a stable order, for golden-file and integration tests
+// whose output would otherwise depend on the order in which spans end and on
+// goroutine timing. The exporter sends the events for the spans in a batch
+// ordered by their start times and then by their span IDs, the events for
+// each span's span events and links preceding its own, exports one batch at a
+// time, and flushes the events it has queued before ExportSpans returns.
+//
+// Flushing after every batch is slow, so this option is not meant for
+// production use. It can't be combined with WithAsyncExport or tail
+// sampling, which export spans in the background.
+func WithDeterministicOrdering() ExporterOption {
+	return func(c *exporterConfig) error {
+		c.deterministicOrdering = true
+		return nil
+	}
+}
+
+// sortedSpans returns a copy of spans ordered by start time and then by span
+// ID.
+func sortedSpans(spans []*trace.SpanSnapshot) []*trace.SpanSnapshot {
+	sorted := make([]*trace.SpanSnapshot, len(spans))
+	copy(sorted, spans)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if !a.StartTime.Equal(b.StartTime) {
+			return a.StartTime.Before(b.StartTime)
+		}
+		aID, bID := a.SpanContext.SpanID, b.SpanContext.SpanID
+		return bytes.Compare(aID[:], bID[:]) < 0
+	})
+	return sorted
+}
@@ -0,0 +1,51 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestDeterministicOrdering(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := makeTestExporter(mockHoneycomb, WithDeterministicOrdering())
+	assert.Nil(err)
+
+	start := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	span := func(name string, id byte, offset time.Duration) *trace.SpanSnapshot {
+		return &trace.SpanSnapshot{
+			SpanContext: apitrace.SpanContext{
+				TraceID: apitrace.TraceID{1},
+				SpanID:  apitrace.SpanID{id},
+			},
+			Name:      name,
+			StartTime: start.Add(offset),
+			EndTime:   start.Add(time.Second),
+		}
+	}
+	withEvent := span("first", 9, 0)
+	withEvent.MessageEvents = []trace.Event{{Name: "event", Time: start.Add(time.Millisecond)}}
+	sds := []*trace.SpanSnapshot{
+		span("third", 2, time.Millisecond),
+		span("second", 1, time.Millisecond),
+		withEvent,
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Equal("third", sds[0].Name, "the batch itself is left unsorted")
+
+	var names []interface{}
+	for _, ev := range mockHoneycomb.Events() {
+		names = append(names, ev.Data["name"])
+	}
+	assert.Equal([]interface{}{"event", "first", "second", "third"}, names)
+
+	_, err = makeTestExporter(mockHoneycomb, WithDeterministicOrdering(), WithAsyncExport(10, 1))
+	assert.Error(err)
+}
@@ -0,0 +1,321 @@
+package honeycomb
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+)
+
+// maxEventBytes is the largest serialized event Honeycomb accepts.
+const maxEventBytes = 1000000
+
+// Names of fields the exporter adds to events altered because they were
+// too large.
+const (
+	truncatedFieldsField = "meta.truncated_fields"
+	oversizedBytesField  = "meta.oversized_event_bytes"
+	overflowEventsField  = "meta.overflow_events"
+	overflowIndexField   = "meta.overflow_index"
+)
+
+// truncationMarker ends the values of fields shortened to fit an event
+// within the size limit.
+const truncationMarker = "…"
+
+// OversizedEventPolicy says what the exporter does with an event too large
+// for Honeycomb to accept.
+type OversizedEventPolicy int
+
+const (
+	// TruncateOversizedEvents shortens or removes the event's largest fields
+	// until it fits, listing them in its "meta.truncated_fields" field. The
+	// fields identifying the event's trace, span, and name are kept intact.
+	TruncateOversizedEvents OversizedEventPolicy = iota + 1
+	// DropOversizedEvents sends, in place of the event, a diagnostic event
+	// holding only the fields identifying its trace, span, and name, along
+	// with its size in its "meta.oversized_event_bytes" field.
+	DropOversizedEvents
+	// SplitOversizedEvents moves the event's largest fields into as many
+	// overflow events as needed for it to fit, recording their number in its
+	// "meta.overflow_events" field. Each overflow event is a span event
+	// belonging to the event's span, bearing its name and its position among
+	// the overflow events in its "meta.overflow_index" field. Fields too large
+	// to fit even in an overflow event of their own are truncated.
+	SplitOversizedEvents
+)
+
+// OversizedEventCounts counts the events an exporter found too large for
+// Honeycomb to accept since it was created.
+type OversizedEventCounts struct {
+	// Detected is the number of oversized events.
+	Detected uint64
+	// Truncated is the number of those events sent after being truncated.
+	Truncated uint64
+	// Split is the number of those events sent after being split.
+	Split uint64
+	// Dropped is the number of those events not sent.
+	Dropped uint64
+}
+
+// WithOversizedEventPolicy causes the exporter to check the size of each
+// event before sending it and apply the given policy to those too large for
+// Honeycomb to accept, which would otherwise be rejected by the API with
+// only an opaque error reported to RunErrorLogger.
+func WithOversizedEventPolicy(policy OversizedEventPolicy) ExporterOption {
+	return func(c *exporterConfig) error {
+		switch policy {
+		case TruncateOversizedEvents, DropOversizedEvents, SplitOversizedEvents:
+		default:
+			return errors.New("unknown oversized event policy")
+		}
+		c.oversizedPolicy = policy
+		c.oversizedHook = nil
+		return nil
+	}
+}
+
+// CallingOnOversizedEvent causes the exporter to check the size of each
+// event before sending it and call f with those too large for Honeycomb to
+// accept, along with their serialized sizes in bytes. The function may
+// modify the event. The exporter sends the event if f returns true and
+// drops it otherwise.
+func CallingOnOversizedEvent(f func(ev *libhoney.Event, size int) bool) ExporterOption {
+	return func(c *exporterConfig) error {
+		if f == nil {
+			return errors.New("oversized event hook must not be nil")
+		}
+		c.oversizedPolicy = 0
+		c.oversizedHook = f
+		return nil
+	}
+}
+
+// identifyingFields are those kept intact in truncated events and copied to
+// the diagnostic events sent in place of dropped ones.
+var identifyingFields = []string{
+	"trace.trace_id",
+	"trace.span_id",
+	"trace.parent_id",
+	"name",
+	serviceNameField,
+	annotationTypeField,
+	sampleRateField,
+}
+
+func isIdentifyingField(name string) bool {
+	for _, f := range identifyingFields {
+		if f == name {
+			return true
+		}
+	}
+	switch name {
+	case truncatedFieldsField, overflowEventsField, overflowIndexField:
+		return true
+	}
+	return false
+}
+
+// oversizeGuard applies a policy to events too large to send.
+type oversizeGuard struct {
+	limit    int
+	policy   OversizedEventPolicy
+	hook     func(*libhoney.Event, int) bool
+	newEvent func() *libhoney.Event
+
+	detected  uint64
+	truncated uint64
+	split     uint64
+	dropped   uint64
+}
+
+// serializedSize returns the size of a value once encoded as JSON.
+func serializedSize(v interface{}) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
+
+// check returns the events to send in place of ev, which is ev alone unless
+// it's too large, along with their serialized size if it's ev alone, unchanged,
+// or else zero.
+func (g *oversizeGuard) check(ev *libhoney.Event) ([]*libhoney.Event, int) {
+	size := serializedSize(ev.Fields())
+	if size <= g.limit {
+		return []*libhoney.Event{ev}, size
+	}
+	atomic.AddUint64(&g.detected, 1)
+
+	if g.hook != nil {
+		if !g.hook(ev, size) {
+			atomic.AddUint64(&g.dropped, 1)
+			return nil, 0
+		}
+		// The hook may have changed the event.
+		return []*libhoney.Event{ev}, 0
+	}
+	switch g.policy {
+	case TruncateOversizedEvents:
+		if g.truncate(ev) {
+			atomic.AddUint64(&g.truncated, 1)
+			return []*libhoney.Event{ev}, 0
+		}
+	case SplitOversizedEvents:
+		atomic.AddUint64(&g.split, 1)
+		return g.splitEvent(ev), 0
+	}
+	atomic.AddUint64(&g.dropped, 1)
+	return []*libhoney.Event{g.diagnostic(ev, size)}, 0
+}
+
+// sizedField is a field of an event along with its serialized size.
+type sizedField struct {
+	name string
+	size int
+}
+
+// largestFields returns the fields of an event other than those identifying
+// it, largest first.
+func largestFields(fields map[string]interface{}) []sizedField {
+	var sized []sizedField
+	for name, v := range fields {
+		if !isIdentifyingField(name) {
+			sized = append(sized, sizedField{name, serializedSize(v)})
+		}
+	}
+	sort.Slice(sized, func(i, j int) bool {
+		if sized[i].size != sized[j].size {
+			return sized[i].size > sized[j].size
+		}
+		return sized[i].name < sized[j].name
+	})
+	return sized
+}
+
+// truncate shortens or removes the largest fields of an event until it fits
+// within the limit, reporting whether it succeeded.
+func (g *oversizeGuard) truncate(ev *libhoney.Event) bool {
+	fields := ev.Fields()
+	var truncated []string
+	for _, c := range largestFields(fields) {
+		// List the field before measuring, so that the list counts against
+		// the limit.
+		truncated = append(truncated, c.name)
+		ev.AddField(truncatedFieldsField, strings.Join(truncated, ","))
+		excess := serializedSize(fields) - g.limit
+		if s, ok := fields[c.name].(string); ok && len(s) > excess+len(truncationMarker) {
+			ev.AddField(c.name, truncateString(s, len(s)-excess-len(truncationMarker))+truncationMarker)
+		} else {
+			delete(fields, c.name)
+		}
+		if serializedSize(fields) <= g.limit {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateString returns the longest prefix of s no longer than n bytes that
+// doesn't split a UTF-8 sequence.
+func truncateString(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// splitEvent moves the largest fields of an event into overflow events until it
+// fits within the limit, returning the event followed by the overflow events.
+func (g *oversizeGuard) splitEvent(ev *libhoney.Event) []*libhoney.Event {
+	fields := ev.Fields()
+	// Overflow events belong to the event's span or, if the event is itself
+	// for a span event or link, to the span that holds it.
+	parentID, ok := fields["trace.span_id"]
+	if !ok {
+		parentID = fields["trace.parent_id"]
+	}
+	newOverflow := func(index int) *libhoney.Event {
+		o := g.newEvent()
+		for _, name := range []string{"trace.trace_id", "name", serviceNameField, sampleRateField} {
+			if v, ok := fields[name]; ok {
+				o.AddField(name, v)
+			}
+		}
+		o.AddField("trace.parent_id", parentID)
+		o.AddField(annotationTypeField, "span_event")
+		o.AddField(overflowIndexField, index)
+		o.Dataset = ev.Dataset
+		o.Timestamp = ev.Timestamp
+		o.SampleRate = ev.SampleRate
+		return o
+	}
+
+	events := []*libhoney.Event{ev}
+	// Reserve room for the count of overflow events before measuring.
+	ev.AddField(overflowEventsField, 0)
+	var current *libhoney.Event
+	for _, f := range largestFields(fields) {
+		if serializedSize(fields) <= g.limit {
+			break
+		}
+		v := fields[f.name]
+		delete(fields, f.name)
+		if current != nil {
+			current.AddField(f.name, v)
+			if serializedSize(current.Fields()) <= g.limit {
+				continue
+			}
+			delete(current.Fields(), f.name)
+		}
+		current = newOverflow(len(events))
+		current.AddField(f.name, v)
+		if serializedSize(current.Fields()) > g.limit {
+			g.truncate(current)
+		}
+		events = append(events, current)
+	}
+	ev.AddField(overflowEventsField, len(events)-1)
+	return events
+}
+
+// diagnostic returns an event standing in for an oversized one, holding only
+// its identifying fields and its size.
+func (g *oversizeGuard) diagnostic(ev *libhoney.Event, size int) *libhoney.Event {
+	d := g.newEvent()
+	fields := ev.Fields()
+	for _, name := range identifyingFields {
+		if v, ok := fields[name]; ok {
+			d.AddField(name, v)
+		}
+	}
+	d.AddField(oversizedBytesField, size)
+	d.Dataset = ev.Dataset
+	d.Timestamp = ev.Timestamp
+	d.SampleRate = ev.SampleRate
+	return d
+}
+
+func (g *oversizeGuard) counts() OversizedEventCounts {
+	return OversizedEventCounts{
+		Detected:  atomic.LoadUint64(&g.detected),
+		Truncated: atomic.LoadUint64(&g.truncated),
+		Split:     atomic.LoadUint64(&g.split),
+		Dropped:   atomic.LoadUint64(&g.dropped),
+	}
+}
+
+// OversizedEvents counts the events found too large to send when the
+// exporter is configured with WithOversizedEventPolicy or
+// CallingOnOversizedEvent. It returns the zero value otherwise.
+func (e *Exporter) OversizedEvents() OversizedEventCounts {
+	if e.oversize == nil {
+		return OversizedEventCounts{}
+	}
+	return e.oversize.counts()
+}
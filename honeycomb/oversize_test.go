@@ -0,0 +1,133 @@
+package honeycomb
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestTruncateString(t *testing.T) {
+	assert.Equal(t, "ab", truncateString("abc", 2))
+	assert.Equal(t, "a", truncateString("aé", 2))
+	assert.Equal(t, "", truncateString("é", 1))
+}
+
+func exportOversizedSpan(t *testing.T, opts ...ExporterOption) (*Exporter, *transmission.MockSender) {
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := makeTestExporter(mockHoneycomb, opts...)
+	assert.Nil(t, err)
+	exporter.oversize.limit = 400
+
+	sds := []*trace.SpanSnapshot{{
+		Name: "big",
+		Attributes: []label.KeyValue{
+			label.String("db.statement", strings.Repeat("x", 500)),
+			label.String("small", "kept"),
+		},
+	}}
+	assert.Nil(t, exporter.ExportSpans(context.Background(), sds))
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+	return exporter, mockHoneycomb
+}
+
+func TestHoneycombOversizedEventTruncation(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter, mockHoneycomb := exportOversizedSpan(t, WithOversizedEventPolicy(TruncateOversizedEvents))
+	assert.Len(mockHoneycomb.Events(), 1)
+	fields := mockHoneycomb.Events()[0].Data
+	assert.Equal("big", fields["name"])
+	assert.Equal("kept", fields["small"])
+	assert.Equal("db.statement", fields[truncatedFieldsField])
+	statement := fields["db.statement"].(string)
+	assert.True(strings.HasSuffix(statement, truncationMarker))
+	assert.True(len(statement) < 500)
+	assert.Equal(OversizedEventCounts{Detected: 1, Truncated: 1}, exporter.OversizedEvents())
+}
+
+func TestHoneycombOversizedEventDrop(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter, mockHoneycomb := exportOversizedSpan(t, WithOversizedEventPolicy(DropOversizedEvents))
+	assert.Len(mockHoneycomb.Events(), 1)
+	fields := mockHoneycomb.Events()[0].Data
+	assert.Equal("big", fields["name"])
+	assert.NotContains(fields, "db.statement")
+	assert.NotContains(fields, "small")
+	assert.Contains(fields, oversizedBytesField)
+	assert.Equal(OversizedEventCounts{Detected: 1, Dropped: 1}, exporter.OversizedEvents())
+}
+
+func TestHoneycombOversizedEventHook(t *testing.T) {
+	assert := assert.New(t)
+
+	var sizes []int
+	hook := CallingOnOversizedEvent(func(ev *libhoney.Event, size int) bool {
+		sizes = append(sizes, size)
+		return false
+	})
+	exporter, mockHoneycomb := exportOversizedSpan(t, hook)
+	assert.Empty(mockHoneycomb.Events())
+	assert.Len(sizes, 1)
+	assert.True(sizes[0] > 400)
+	assert.Equal(OversizedEventCounts{Detected: 1, Dropped: 1}, exporter.OversizedEvents())
+
+	_, err := makeTestExporter(&transmission.MockSender{}, WithOversizedEventPolicy(0))
+	assert.Error(err)
+	_, err = makeTestExporter(&transmission.MockSender{}, CallingOnOversizedEvent(nil))
+	assert.Error(err)
+}
+
+func TestOversizeGuardSplit(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(&transmission.MockSender{})
+	assert.Nil(err)
+	defer exporter.Shutdown(context.Background())
+	g := &oversizeGuard{limit: 300, policy: SplitOversizedEvents, newEvent: exporter.client.NewEvent}
+
+	ev := exporter.client.NewEvent()
+	ev.AddField("trace.span_id", "s")
+	ev.AddField("a", strings.Repeat("a", 150))
+	ev.AddField("b", strings.Repeat("b", 140))
+	ev.AddField("c", strings.Repeat("c", 130))
+
+	events, size := g.check(ev)
+	assert.Zero(size)
+	assert.Len(events, 3)
+	assert.Equal(2, events[0].Fields()[overflowEventsField])
+	assert.Equal(strings.Repeat("c", 130), events[0].Fields()["c"])
+	assert.Equal(strings.Repeat("a", 150), events[1].Fields()["a"])
+	assert.Equal(strings.Repeat("b", 140), events[2].Fields()["b"])
+	for i, o := range events {
+		assert.True(serializedSize(o.Fields()) <= g.limit)
+		if i > 0 {
+			assert.Equal("s", o.Fields()["trace.parent_id"])
+			assert.Equal("span_event", o.Fields()[annotationTypeField])
+			assert.Equal(i, o.Fields()[overflowIndexField])
+		}
+	}
+	assert.Equal(OversizedEventCounts{Detected: 1, Split: 1}, g.counts())
+}
+
+func TestHoneycombOversizedEventSplit(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter, mockHoneycomb := exportOversizedSpan(t, WithOversizedEventPolicy(SplitOversizedEvents))
+	assert.Len(mockHoneycomb.Events(), 2)
+	primary, overflow := mockHoneycomb.Events()[0].Data, mockHoneycomb.Events()[1].Data
+	assert.Equal("kept", primary["small"])
+	assert.NotContains(primary, "db.statement")
+	assert.Equal(1, primary[overflowEventsField])
+	assert.Equal("big", overflow["name"])
+	assert.Equal(primary["trace.span_id"], overflow["trace.parent_id"])
+	assert.Contains(overflow, "db.statement")
+	assert.Equal(OversizedEventCounts{Detected: 1, Split: 1}, exporter.OversizedEvents())
+}
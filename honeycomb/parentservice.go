@@ -0,0 +1,140 @@
+package honeycomb
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	traceStateHeader = "tracestate"
+	// parentServiceTraceStateKey is the tracestate member that carries the
+	// name of the calling service.
+	parentServiceTraceStateKey = "hnysvc"
+	// maxTraceStateValueLength is the longest value a tracestate member may
+	// have, per the W3C Trace Context specification.
+	maxTraceStateValueLength = 256
+)
+
+// ParentServiceKey is the attribute ParentServiceSpanProcessor sets on server
+// spans to the name of the service that called them.
+const ParentServiceKey = label.Key("request.parent_service")
+
+type parentServiceContextKey struct{}
+
+// ParentServicePropagator propagates the name of the calling service to the
+// services it calls in a member of the W3C tracestate header, where a
+// ParentServiceSpanProcessor can find it.
+//
+// Because it amends the tracestate header written by the
+// propagation.TraceContext propagator, it must follow that propagator when
+// combined with propagation.NewCompositeTextMapPropagator.
+type ParentServicePropagator struct {
+	// ServiceName is the name of this service, which is sent to the services
+	// it calls.
+	ServiceName string
+}
+
+var _ propagation.TextMapPropagator = ParentServicePropagator{}
+
+// traceStateMembers splits a tracestate header value into its members,
+// omitting empty ones and any with the given key.
+func traceStateMembers(header, omitKey string) []string {
+	var members []string
+	for _, m := range strings.Split(header, ",") {
+		m = strings.TrimSpace(m)
+		if len(m) == 0 || strings.HasPrefix(m, omitKey+"=") {
+			continue
+		}
+		members = append(members, m)
+	}
+	return members
+}
+
+// traceStateValue returns the value of the tracestate member with the given
+// key, if present.
+func traceStateValue(header, key string) (string, bool) {
+	for _, m := range strings.Split(header, ",") {
+		m = strings.TrimSpace(m)
+		if strings.HasPrefix(m, key+"=") {
+			return m[len(key)+1:], true
+		}
+	}
+	return "", false
+}
+
+// validTraceStateValue reports whether s may be used as a tracestate member
+// value: printable ASCII other than ',' and '=', not ending in a space.
+func validTraceStateValue(s string) bool {
+	if len(s) == 0 || len(s) > maxTraceStateValueLength || s[len(s)-1] == ' ' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c > 0x7e || c == ',' || c == '=' {
+			return false
+		}
+	}
+	return true
+}
+
+// Inject adds this service's name to the tracestate header in the carrier.
+func (p ParentServicePropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	if !validTraceStateValue(p.ServiceName) || !apitrace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	members := traceStateMembers(carrier.Get(traceStateHeader), parentServiceTraceStateKey)
+	members = append([]string{parentServiceTraceStateKey + "=" + p.ServiceName}, members...)
+	carrier.Set(traceStateHeader, strings.Join(members, ","))
+}
+
+// Extract records the calling service's name from the tracestate header in
+// the carrier, if present, in the returned context.
+func (p ParentServicePropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	if name, ok := traceStateValue(carrier.Get(traceStateHeader), parentServiceTraceStateKey); ok && validTraceStateValue(name) {
+		return context.WithValue(ctx, parentServiceContextKey{}, name)
+	}
+	return ctx
+}
+
+// Fields returns the keys whose values are set with Inject.
+func (p ParentServicePropagator) Fields() []string {
+	return []string{traceStateHeader}
+}
+
+// ParentServiceFromContext returns the name of the calling service recorded
+// by ParentServicePropagator in the given context, if any.
+func ParentServiceFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(parentServiceContextKey{}).(string)
+	return name, ok
+}
+
+// ParentServiceSpanProcessor is a span processor that sets the
+// "request.parent_service" attribute on server spans to the name of the
+// calling service, as propagated by ParentServicePropagator, so that a service
+// can see who is calling it.
+type ParentServiceSpanProcessor struct{}
+
+var _ sdktrace.SpanProcessor = ParentServiceSpanProcessor{}
+
+// OnStart sets the parent service attribute on starting server spans.
+func (ParentServiceSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	if s.SpanKind() != apitrace.SpanKindServer {
+		return
+	}
+	if name, ok := ParentServiceFromContext(parent); ok {
+		s.SetAttributes(ParentServiceKey.String(name))
+	}
+}
+
+// OnEnd does nothing.
+func (ParentServiceSpanProcessor) OnEnd(sdktrace.ReadOnlySpan) {}
+
+// Shutdown does nothing.
+func (ParentServiceSpanProcessor) Shutdown(context.Context) error { return nil }
+
+// ForceFlush does nothing.
+func (ParentServiceSpanProcessor) ForceFlush() {}
@@ -0,0 +1,76 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+type mapCarrier map[string]string
+
+func (c mapCarrier) Get(key string) string { return c[key] }
+func (c mapCarrier) Set(key, value string) { c[key] = value }
+
+func remoteContext() context.Context {
+	traceID, _ := apitrace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := apitrace.SpanIDFromHex("0102030405060708")
+	return apitrace.ContextWithRemoteSpanContext(context.Background(), apitrace.SpanContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+	})
+}
+
+// clientContext returns a context holding a span started within
+// remoteContext, as when calling another service.
+func clientContext() context.Context {
+	ctx, _ := sdktrace.NewTracerProvider().Tracer("test").Start(remoteContext(), "client")
+	return ctx
+}
+
+func TestParentServicePropagator(t *testing.T) {
+	assert := assert.New(t)
+	ctx := clientContext()
+
+	carrier := mapCarrier{"tracestate": "hnysvc=frontend, vendor=x"}
+	ParentServicePropagator{ServiceName: "checkout"}.Inject(ctx, carrier)
+	assert.Equal("hnysvc=checkout,vendor=x", carrier["tracestate"])
+
+	extracted := ParentServicePropagator{}.Extract(context.Background(), carrier)
+	name, ok := ParentServiceFromContext(extracted)
+	assert.True(ok)
+	assert.Equal("checkout", name)
+
+	// Invalid service names and contexts without spans aren't propagated.
+	carrier = mapCarrier{}
+	ParentServicePropagator{ServiceName: "a,b"}.Inject(ctx, carrier)
+	ParentServicePropagator{ServiceName: "checkout"}.Inject(context.Background(), carrier)
+	assert.Empty(carrier)
+
+	_, ok = ParentServiceFromContext(ParentServicePropagator{}.Extract(context.Background(), carrier))
+	assert.False(ok)
+}
+
+func TestParentServiceSpanProcessor(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb)
+	assert.Nil(err)
+	tr, err := setUpTestProvider(exporter, sdktrace.WithSpanProcessor(ParentServiceSpanProcessor{}))
+	assert.Nil(err)
+
+	ctx := ParentServicePropagator{}.Extract(remoteContext(), mapCarrier{"tracestate": "hnysvc=frontend"})
+	_, server := tr.Start(ctx, "server", apitrace.WithSpanKind(apitrace.SpanKindServer))
+	server.End()
+	_, internal := tr.Start(ctx, "internal")
+	internal.End()
+
+	assert.Len(mockHoneycomb.Events(), 2)
+	assert.Equal("frontend", mockHoneycomb.Events()[0].Data["request.parent_service"])
+	assert.NotContains(mockHoneycomb.Events()[1].Data, "request.parent_service")
+}
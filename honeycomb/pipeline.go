@@ -0,0 +1,92 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// EventProcessor is a stage in the pipeline through which the exporter passes
+// each event after populating it and before sending it: first the event for
+// a span, then those for its span events and links, so that processors
+// scrubbing sensitive values see them all. Processors can tell the events for
+// span events and links apart by their "meta.annotation_type" fields. A
+// processor may modify the event. If it returns ErrDropEvent, the exporter
+// drops the event; if it returns any other error, the exporter drops the
+// event and reports the error as a failure to export the span. Dropping a
+// span's own event drops the events for its span events and links too.
+type EventProcessor func(ctx context.Context, ev *libhoney.Event, s *trace.SpanSnapshot) error
+
+// annotationTypeField distinguishes the events for span events and links from
+// those for spans.
+const annotationTypeField = "meta.annotation_type"
+
+// isAnnotationEvent reports whether an event is for a span event or link
+// rather than for a span.
+func isAnnotationEvent(ev *libhoney.Event) bool {
+	_, ok := ev.Fields()[annotationTypeField]
+	return ok
+}
+
+// ErrDropEvent is returned by an EventProcessor to drop the event it was
+// given without reporting an error.
+var ErrDropEvent = errors.New("drop event")
+
+// transformProcessor adapts a function that modifies an event to an
+// EventProcessor.
+func transformProcessor(f func(*libhoney.Event, *trace.SpanSnapshot)) EventProcessor {
+	return func(_ context.Context, ev *libhoney.Event, s *trace.SpanSnapshot) error {
+		f(ev, s)
+		return nil
+	}
+}
+
+// runProcessors passes an event through the pipeline, reporting whether the
+// event should be sent, along with any error a processor returned.
+func runProcessors(ctx context.Context, processors []EventProcessor, ev *libhoney.Event, s *trace.SpanSnapshot) (bool, error) {
+	for _, process := range processors {
+		if err := process(ctx, ev, s); err != nil {
+			if errors.Is(err, ErrDropEvent) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// WithEventProcessors appends stages to the pipeline through which the
+// exporter passes each event before sending it. Stages run in the order in
+// which they are added, including those added by other options such as
+// WithHTTPFieldNormalization, WithSQLObfuscation, and WithURLQueryScrubbing,
+// so that, for example, a stage added after WithURLQueryScrubbing sees
+// scrubbed URLs.
+func WithEventProcessors(processors ...EventProcessor) ExporterOption {
+	return func(c *exporterConfig) error {
+		for _, p := range processors {
+			if p == nil {
+				return errors.New("event processor must not be nil")
+			}
+		}
+		c.processors = append(c.processors, processors...)
+		return nil
+	}
+}
+
+// WithBeforeSend specifies a hook function to be called with each event just
+// before the exporter sends it, along with the span for which it was created.
+// Unlike the stages of the event pipeline, the hook runs after the exporter
+// has made its sampling decisions and set the event's sample rate, and sees
+// the copies of events sent to the dataset given to WithErrorsDataset, which
+// it can tell apart by their Dataset fields. The hook may modify the event's
+// fields. If it returns false, the exporter drops the event without reporting
+// an error.
+func WithBeforeSend(f func(ctx context.Context, ev *libhoney.Event, s *trace.SpanSnapshot) bool) ExporterOption {
+	return func(c *exporterConfig) error {
+		c.beforeSend = f
+		return nil
+	}
+}
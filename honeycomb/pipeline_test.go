@@ -0,0 +1,153 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestHoneycombOutputWithEventProcessors(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	var results []ExportResult
+	var errs []error
+	failure := errors.New("processor failed")
+	tr, err := setUpTestExporter(mockHoneycomb,
+		WithURLQueryScrubbing("x"),
+		WithEventProcessors(
+			func(_ context.Context, ev *libhoney.Event, s *trace.SpanSnapshot) error {
+				// This stage runs after scrubbing.
+				ev.AddField("seen.url", ev.Fields()["http.url"])
+				return nil
+			},
+			func(_ context.Context, ev *libhoney.Event, s *trace.SpanSnapshot) error {
+				switch s.Name {
+				case "drop":
+					return ErrDropEvent
+				case "fail":
+					return failure
+				}
+				return nil
+			}),
+		CallingOnExportResult(func(r ExportResult) {
+			results = append(results, r)
+		}),
+		CallingOnError(func(err error) {
+			errs = append(errs, err)
+		}))
+	assert.Nil(err)
+
+	for _, name := range []string{"keep", "drop", "fail"} {
+		_, span := tr.Start(context.TODO(), name)
+		span.SetAttributes(label.String("http.url", "/a?token=secret"))
+		span.End()
+	}
+
+	events := mockHoneycomb.Events()
+	assert.Len(events, 1)
+	assert.Equal("keep", events[0].Data["name"])
+	assert.Equal("/a?token=x", events[0].Data["seen.url"])
+
+	assert.Len(results, 3)
+	assert.Equal(1, results[0].Accepted)
+	assert.Equal(1, results[1].Accepted)
+	assert.Equal(0, results[2].Accepted)
+	assert.Equal([]error{failure}, results[2].Errors)
+	assert.Equal([]error{failure}, errs)
+
+	_, err = makeTestExporter(mockHoneycomb, WithEventProcessors(nil))
+	assert.Error(err)
+}
+func TestEventProcessorsSeeSpanEventsAndLinks(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithURLQueryScrubbing("x"),
+		WithEventProcessors(func(_ context.Context, ev *libhoney.Event, s *trace.SpanSnapshot) error {
+			if s.Name == "drop" && !isAnnotationEvent(ev) {
+				return ErrDropEvent
+			}
+			if ev.Fields()["name"] == "noise" {
+				return ErrDropEvent
+			}
+			return nil
+		}))
+	assert.Nil(err)
+
+	secret := label.String("http.url", "/a?token=secret")
+	events := []trace.Event{
+		{Name: "redirect", Attributes: []label.KeyValue{secret}},
+		{Name: "noise"},
+	}
+	sds := []*trace.SpanSnapshot{
+		{Name: "keep", MessageEvents: events, Links: []apitrace.Link{{Attributes: []label.KeyValue{secret}}}},
+		{Name: "drop", MessageEvents: events},
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+
+	// Dropping a span's event drops those for its span events too, and
+	// scrubbing applies to every event.
+	var kinds []interface{}
+	for _, ev := range mockHoneycomb.Events() {
+		kinds = append(kinds, ev.Data["meta.annotation_type"])
+		if url, ok := ev.Data["http.url"]; ok {
+			assert.Equal("/a?token=x", url)
+		}
+	}
+	assert.Equal([]interface{}{"span_event", "link", nil}, kinds)
+}
+
+func TestHoneycombOutputWithBeforeSend(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	type ctxKey struct{}
+	var errs []error
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithSampleRate(4),
+		WithEventProcessors(func(_ context.Context, ev *libhoney.Event, s *trace.SpanSnapshot) error {
+			ev.AddField("processed", true)
+			return nil
+		}),
+		WithBeforeSend(func(ctx context.Context, ev *libhoney.Event, s *trace.SpanSnapshot) bool {
+			// The hook runs after the pipeline and sampling.
+			assert.Equal(true, ev.Fields()["processed"])
+			assert.Equal(uint(4), ev.SampleRate)
+			if s.Name == "veto" {
+				return false
+			}
+			ev.AddField("request.id", ctx.Value(ctxKey{}))
+			return true
+		}),
+		CallingOnError(func(err error) {
+			errs = append(errs, err)
+		}))
+	assert.Nil(err)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "abc123")
+	sds := []*trace.SpanSnapshot{
+		{Name: "keep", MessageEvents: []trace.Event{{Name: "annotation"}}},
+		{Name: "veto", MessageEvents: []trace.Event{{Name: "annotation"}}},
+	}
+	assert.Nil(exporter.ExportSpans(ctx, sds))
+
+	events := mockHoneycomb.Events()
+	if assert.Len(events, 2) {
+		assert.Equal("span_event", events[0].Data["meta.annotation_type"])
+		for _, ev := range events {
+			assert.Equal("abc123", ev.Data["request.id"])
+		}
+		assert.Equal("keep", events[1].Data["name"])
+	}
+	assert.Empty(errs)
+}
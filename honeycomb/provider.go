@@ -0,0 +1,86 @@
+package honeycomb
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TracerProviderConfig configures the tracing pipeline built by
+// NewTracerProvider. Its zero value yields a working pipeline, given the
+// HONEYCOMB_API_KEY and HONEYCOMB_DATASET environment variables.
+type TracerProviderConfig struct {
+	// Config is the exporter's Config.
+	Config Config
+	// ExporterOptions are the options with which to create the exporter.
+	ExporterOptions []ExporterOption
+	// Sampler is the sampler for every span, used as given: wrap it with
+	// sdktrace.ParentBased for spans with parents to follow their parents'
+	// decisions. If nil, the sampler selected by the OTEL_TRACES_SAMPLER and
+	// OTEL_TRACES_SAMPLER_ARG environment variables is used, as by
+	// SamplerFromEnv, or else every root span is sampled and spans with
+	// parents follow their parents' decisions.
+	Sampler sdktrace.Sampler
+	// ResourceAttributes are added to those detected from the host, the SDK,
+	// and the OTEL_RESOURCE_ATTRIBUTES environment variable, taking
+	// precedence over them.
+	ResourceAttributes []label.KeyValue
+	// BatchOptions are the options with which to create the batch span
+	// processor handing spans to the exporter.
+	BatchOptions []sdktrace.BatchSpanProcessorOption
+	// KeepGlobals stops NewTracerProvider from installing the tracer provider
+	// and the W3C trace context and baggage propagators as the global ones.
+	KeepGlobals bool
+}
+
+// NewTracerProvider builds a complete tracing pipeline sending spans to
+// Honeycomb: an exporter, started as by Start, a batch span processor, a
+// sampler, a resource describing the process, and, unless KeepGlobals is
+// set, the global tracer provider and W3C propagators. It returns the tracer
+// provider and a function that flushes the spans still buffered and shuts
+// the pipeline down, to be called before the process exits.
+func NewTracerProvider(ctx context.Context, config TracerProviderConfig) (*sdktrace.TracerProvider, func(context.Context) error, error) {
+	sampler := config.Sampler
+	if sampler == nil {
+		var err error
+		if sampler, err = SamplerFromEnv(sdktrace.ParentBased(sdktrace.AlwaysSample())); err != nil {
+			return nil, nil, err
+		}
+	}
+	res, err := resource.New(ctx, resource.WithAttributes(config.ResourceAttributes...))
+	if err != nil {
+		return nil, nil, err
+	}
+	exporter, err := NewExporter(config.Config, config.ExporterOptions...)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The exporter outlives ctx, which may be meant only for setup.
+	if err := exporter.Start(context.Background()); err != nil {
+		return nil, nil, err
+	}
+
+	bsp := sdktrace.NewBatchSpanProcessor(exporter, config.BatchOptions...)
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sampler}),
+		sdktrace.WithResource(res),
+		sdktrace.WithSpanProcessor(bsp),
+	)
+	if !config.KeepGlobals {
+		otel.SetTracerProvider(tp)
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{}))
+	}
+	shutdown := func(ctx context.Context) error {
+		// Shutting the batch span processor down shuts the exporter down.
+		err := bsp.Shutdown(ctx)
+		tp.Shutdown(ctx)
+		return err
+	}
+	return tp, shutdown, nil
+}
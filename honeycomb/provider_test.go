@@ -0,0 +1,75 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	assert := assert.New(t)
+	setEnv(t, tracesSamplerEnv, "")
+	mockHoneycomb := &transmission.MockSender{}
+	tp, shutdown, err := NewTracerProvider(context.Background(), TracerProviderConfig{
+		Config: Config{APIKey: "overridden"},
+		ExporterOptions: []ExporterOption{
+			TargetingDataset("test"),
+			WithSender(mockHoneycomb),
+		},
+		ResourceAttributes: []label.KeyValue{label.String("team", "storage")},
+		KeepGlobals:        true,
+	})
+	if !assert.Nil(err) {
+		return
+	}
+
+	_, span := tp.Tracer("honeycomb/test").Start(context.Background(), "provided")
+	span.End()
+	assert.Empty(mockHoneycomb.Events(), "spans are batched")
+	assert.Nil(shutdown(context.Background()))
+
+	events := mockHoneycomb.Events()
+	if assert.Len(events, 1) {
+		assert.Equal("provided", events[0].Data["name"])
+		assert.Equal("storage", events[0].Data["team"])
+	}
+}
+
+func TestNewTracerProviderSampler(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	tp, shutdown, err := NewTracerProvider(context.Background(), TracerProviderConfig{
+		Config:          Config{APIKey: "overridden"},
+		ExporterOptions: []ExporterOption{TargetingDataset("test"), WithSender(mockHoneycomb)},
+		Sampler:         sdktrace.NeverSample(),
+		KeepGlobals:     true,
+	})
+	if !assert.Nil(err) {
+		return
+	}
+	_, span := tp.Tracer("honeycomb/test").Start(context.Background(), "unsampled")
+	span.End()
+	// The sampler applies to spans with sampled parents too.
+	parent := apitrace.ContextWithRemoteSpanContext(context.Background(), apitrace.SpanContext{
+		TraceID:    apitrace.TraceID{1},
+		SpanID:     apitrace.SpanID{2},
+		TraceFlags: apitrace.FlagsSampled,
+	})
+	_, span = tp.Tracer("honeycomb/test").Start(parent, "unsampled child")
+	span.End()
+	assert.Nil(shutdown(context.Background()))
+	assert.Empty(mockHoneycomb.Events())
+
+	setEnv(t, tracesSamplerEnv, "sometimes")
+	_, _, err = NewTracerProvider(context.Background(), TracerProviderConfig{
+		Config:      Config{APIKey: "overridden"},
+		KeepGlobals: true,
+	})
+	assert.Error(err)
+}
@@ -0,0 +1,69 @@
+package honeycomb
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// regionAPIHosts maps each Honeycomb region to the host name of its API
+// server.
+var regionAPIHosts = map[string]string{
+	"us": "api.honeycomb.io",
+	"eu": "api.eu1.honeycomb.io",
+}
+
+// checkAPIURLRegion returns an error if apiURL does not address the API server
+// of the given Honeycomb region.
+func checkAPIURLRegion(apiURL, region string) error {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return fmt.Errorf("invalid API URL %q: %v", apiURL, err)
+	}
+	if host := strings.ToLower(u.Hostname()); host != regionAPIHosts[region] {
+		return fmt.Errorf("API URL %q is not in the required Honeycomb region %q; use https://%s/", apiURL, region, regionAPIHosts[region])
+	}
+	return nil
+}
+
+// WithRequiredRegion requires the exporter to send events to the API server of
+// the given Honeycomb region, either "us" or "eu". NewExporter fails if the
+// configured API URL addresses any other server, preventing a misconfigured
+// service from sending data outside the region in which it must reside.
+//
+// Because the region of other servers can't be verified, this option can't be
+// used when sending events through a proxy such as Refinery.
+func WithRequiredRegion(region string) ExporterOption {
+	return func(c *exporterConfig) error {
+		region = strings.ToLower(region)
+		if _, ok := regionAPIHosts[region]; !ok {
+			return fmt.Errorf("unknown Honeycomb region %q", region)
+		}
+		c.requiredRegion = region
+		return nil
+	}
+}
+
+// WithRegion causes the exporter to send events to the API server of the
+// given Honeycomb region, either "us" or "eu," rather than the URL taken
+// from the environment. It sets the API URL as WithAPIURL does, so whichever
+// of the two comes last applies. Naming the region avoids mistyping the
+// server's URL.
+func WithRegion(region string) ExporterOption {
+	return func(c *exporterConfig) error {
+		region = strings.ToLower(region)
+		host, ok := regionAPIHosts[region]
+		if !ok {
+			return fmt.Errorf("unknown Honeycomb region %q", region)
+		}
+		c.apiURL = "https://" + host + "/"
+		return nil
+	}
+}
+
+// WithEUEndpoint causes the exporter to send events to the API server of
+// Honeycomb's EU region, https://api.eu1.honeycomb.io/, as WithRegion("eu")
+// does.
+func WithEUEndpoint() ExporterOption {
+	return WithRegion("eu")
+}
@@ -0,0 +1,57 @@
+package honeycomb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoneycombRequiredRegion(t *testing.T) {
+	tests := []struct {
+		description string
+		region      string
+		opts        []ExporterOption
+		expectError bool
+	}{
+		{"default URL in US", "us", nil, false},
+		{"default URL in EU", "eu", nil, true},
+		{"EU URL in EU", "EU", []ExporterOption{WithAPIURL("https://api.eu1.honeycomb.io/")}, false},
+		{"EU URL in US", "us", []ExporterOption{WithAPIURL("https://api.eu1.honeycomb.io")}, true},
+		{"proxy URL", "eu", []ExporterOption{WithAPIURL("http://refinery.internal:8080")}, true},
+		{"unknown region", "mars", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert := assert.New(t)
+			exporter, err := NewExporter(Config{APIKey: "overridden"},
+				append(test.opts, WithRequiredRegion(test.region))...)
+			if test.expectError {
+				assert.Error(err)
+				assert.Nil(exporter)
+			} else {
+				assert.Nil(err)
+				assert.NotNil(exporter)
+			}
+		})
+	}
+}
+
+func TestWithRegion(t *testing.T) {
+	assert := assert.New(t)
+	for _, test := range []struct {
+		opts []ExporterOption
+		url  string
+	}{
+		{[]ExporterOption{WithEUEndpoint()}, "https://api.eu1.honeycomb.io/"},
+		{[]ExporterOption{WithRegion("US")}, "https://api.honeycomb.io/"},
+		{[]ExporterOption{WithAPIURL("http://refinery.internal:8080"), WithRegion("eu")}, "https://api.eu1.honeycomb.io/"},
+		{[]ExporterOption{WithEUEndpoint(), WithRequiredRegion("eu")}, "https://api.eu1.honeycomb.io/"},
+	} {
+		c, err := configure(test.opts)
+		if assert.Nil(err) {
+			assert.Equal(test.url, c.apiURL)
+		}
+	}
+	assert.Error(ValidateOptions(WithRegion("mars")))
+	assert.Error(ValidateOptions(WithEUEndpoint(), WithRequiredRegion("us")))
+}
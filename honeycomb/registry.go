@@ -0,0 +1,98 @@
+package honeycomb
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/jsonpb"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// Translator converts a span in some wire format, such as an internal
+// protobuf message or a legacy JSON document, into a SpanSnapshot the
+// exporter can send.
+type Translator interface {
+	// Convert converts a span, returning an error if it has a type the
+	// translator doesn't accept or is malformed.
+	Convert(raw interface{}) (*trace.SpanSnapshot, error)
+}
+
+// TranslatorFunc adapts a function to the Translator interface.
+type TranslatorFunc func(raw interface{}) (*trace.SpanSnapshot, error)
+
+// Convert calls f(raw).
+func (f TranslatorFunc) Convert(raw interface{}) (*trace.SpanSnapshot, error) {
+	return f(raw)
+}
+
+var (
+	translatorsMu sync.RWMutex
+	translators   = make(map[string]Translator)
+)
+
+// RegisterTranslator makes a translator available under the given name to
+// LookupTranslator and to the -format flag of cmd/hcsend, which passes it
+// each line of its input as a []byte. Packages providing translators
+// typically register them in their init functions. RegisterTranslator panics
+// if the translator is nil or the name is already registered.
+//
+// The translator named "opencensus" is registered by this package. It
+// converts OpenCensus *tracepb.Span values, as OCProtoSpanToOTelSpanSnapshot
+// does, and []byte values holding such spans in the protobuf JSON encoding.
+func RegisterTranslator(name string, t Translator) {
+	translatorsMu.Lock()
+	defer translatorsMu.Unlock()
+	if t == nil {
+		panic("honeycomb: RegisterTranslator translator is nil")
+	}
+	if _, dup := translators[name]; dup {
+		panic("honeycomb: RegisterTranslator called twice for translator " + name)
+	}
+	translators[name] = t
+}
+
+// LookupTranslator returns the translator registered under the given name,
+// if any.
+func LookupTranslator(name string) (Translator, bool) {
+	translatorsMu.RLock()
+	defer translatorsMu.RUnlock()
+	t, ok := translators[name]
+	return t, ok
+}
+
+// Translators returns the sorted names of the registered translators.
+func Translators() []string {
+	translatorsMu.RLock()
+	defer translatorsMu.RUnlock()
+	names := make([]string, 0, len(translators))
+	for name := range translators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// convertOCSpan converts an OC Span, given either as a *tracepb.Span or in
+// the protobuf JSON encoding.
+func convertOCSpan(raw interface{}) (*trace.SpanSnapshot, error) {
+	switch span := raw.(type) {
+	case *tracepb.Span:
+		return OCProtoSpanToOTelSpanSnapshot(span)
+	case []byte:
+		var decoded tracepb.Span
+		if err := jsonpb.Unmarshal(bytes.NewReader(span), &decoded); err != nil {
+			return nil, fmt.Errorf("failed to decode OpenCensus span: %v", err)
+		}
+		return OCProtoSpanToOTelSpanSnapshot(&decoded)
+	default:
+		return nil, fmt.Errorf("opencensus translator can't convert a %T", raw)
+	}
+}
+
+func init() {
+	RegisterTranslator("opencensus", TranslatorFunc(convertOCSpan))
+}
@@ -0,0 +1,69 @@
+package honeycomb
+
+import (
+	"errors"
+	"testing"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestRegisterTranslator(t *testing.T) {
+	assert := assert.New(t)
+
+	legacy := TranslatorFunc(func(raw interface{}) (*trace.SpanSnapshot, error) {
+		return &trace.SpanSnapshot{Name: string(raw.([]byte))}, nil
+	})
+	RegisterTranslator("test-legacy", legacy)
+
+	translator, ok := LookupTranslator("test-legacy")
+	assert.True(ok)
+	sd, err := translator.Convert([]byte("legacy-span"))
+	assert.Nil(err)
+	assert.Equal("legacy-span", sd.Name)
+	assert.Contains(Translators(), "test-legacy")
+	assert.Contains(Translators(), "opencensus")
+
+	assert.Panics(func() { RegisterTranslator("test-legacy", legacy) })
+	assert.Panics(func() { RegisterTranslator("test-nil", nil) })
+
+	_, ok = LookupTranslator("test-missing")
+	assert.False(ok)
+}
+
+func TestOpenCensusTranslator(t *testing.T) {
+	assert := assert.New(t)
+
+	translator, ok := LookupTranslator("opencensus")
+	if !assert.True(ok) {
+		return
+	}
+
+	sd, err := translator.Convert([]byte(`{"traceId": "CgsMDQ4PEBESExQVFhcYGQ==", "spanId": "AQIDBAUGBwg=", "name": {"value": "json-span"}}`))
+	if assert.Nil(err) {
+		assert.Equal("json-span", sd.Name)
+		assert.Equal("0a0b0c0d0e0f10111213141516171819", sd.SpanContext.TraceID.String())
+		assert.Equal("0102030405060708", sd.SpanContext.SpanID.String())
+	}
+
+	sd, err = translator.Convert(&tracepb.Span{
+		TraceId: []byte{0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19},
+		SpanId:  []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+		Name:    &tracepb.TruncatableString{Value: "proto-span"},
+	})
+	if assert.Nil(err) {
+		assert.Equal("proto-span", sd.Name)
+	}
+
+	_, err = translator.Convert([]byte(`{"traceId": "CgsMDQ4PEBESExQVFhcYGQ==", "spanId": "AQID"}`))
+	var idErr *InvalidIDError
+	assert.True(errors.As(err, &idErr))
+
+	_, err = translator.Convert([]byte(`not json`))
+	assert.Error(err)
+
+	_, err = translator.Convert("a string")
+	assert.Error(err)
+}
@@ -0,0 +1,88 @@
+package honeycomb
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+)
+
+// errNotReloadable is reported by Reload for options setting anything but
+// the settings it can change.
+var errNotReloadable = errors.New("Reload can only change the dataset, sample rate, and debug logging")
+
+// liveSettings holds the settings Reload can change.
+type liveSettings struct {
+	dataset    string
+	sampleRate uint
+}
+
+// debugLogger passes libhoney's debugging output to a libhoney.DefaultLogger
+// while enabled, so that Reload can turn it on and off.
+type debugLogger struct {
+	enabled int32
+	logger  libhoney.DefaultLogger
+}
+
+func (l *debugLogger) Printf(msg string, args ...interface{}) {
+	if atomic.LoadInt32(&l.enabled) != 0 {
+		l.logger.Printf(msg, args...)
+	}
+}
+
+func (l *debugLogger) setEnabled(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&l.enabled, v)
+}
+
+// settings returns the current values of the settings Reload can change.
+func (e *Exporter) settings() *liveSettings {
+	return e.live.Load().(*liveSettings)
+}
+
+// Reload changes the configuration of a running exporter, such as when a
+// process receives SIGHUP or its configuration file changes. It accepts
+// only TargetingDataset, WithSampleRate, and WithDebug, or WithDebugEnabled,
+// which, unlike in NewExporter, turns off debug logging when given false.
+// Settings not given are left as they are. If any option fails or sets
+// anything else, Reload returns an OptionErrors and changes nothing.
+//
+// Reload is safe to call concurrently with ExportSpans; spans already being
+// exported may use either configuration. Events whose dataset was chosen by
+// other options, such as WithErrorsDataset, are unaffected by a change of
+// dataset. After Shutdown, Reload returns ErrExporterShutdown.
+func (e *Exporter) Reload(opts ...ExporterOption) error {
+	if atomic.LoadInt32(&e.closing) != 0 {
+		return ErrExporterShutdown
+	}
+	current := e.settings()
+	debug := e.debugLog != nil && atomic.LoadInt32(&e.debugLog.enabled) != 0
+	c := exporterConfig{
+		dataset:    current.dataset,
+		sampleRate: current.sampleRate,
+		debug:      debug,
+	}
+	var errs OptionErrors
+	for _, o := range opts {
+		if err := o(&c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	rest := c
+	rest.dataset, rest.sampleRate, rest.debug = "", 0, false
+	if !reflect.DeepEqual(rest, exporterConfig{}) {
+		errs = append(errs, errNotReloadable)
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	e.live.Store(&liveSettings{dataset: c.dataset, sampleRate: c.sampleRate})
+	if e.debugLog != nil {
+		e.debugLog.setEnabled(c.debug)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestReload(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := makeTestExporter(mockHoneycomb)
+	if !assert.Nil(err) {
+		return
+	}
+	ctx := context.Background()
+	assert.Nil(exporter.ExportSpans(ctx, []*trace.SpanSnapshot{{Name: "before"}}))
+
+	assert.Nil(exporter.Reload(TargetingDataset("reloaded"), WithSampleRate(5), WithDebugEnabled()))
+	assert.Equal(int32(1), atomic.LoadInt32(&exporter.debugLog.enabled))
+	assert.Nil(exporter.ExportSpans(ctx, []*trace.SpanSnapshot{{Name: "after"}}))
+
+	// Options that can't be reloaded change nothing.
+	err = exporter.Reload(TargetingDataset("ignored"), WithAPIURL("https://example.com"))
+	assert.True(errors.Is(err, errNotReloadable))
+	assert.Error(exporter.Reload(WithSampleRate(0)))
+	assert.Nil(exporter.Reload(WithDebug(false)))
+	assert.Equal(int32(0), atomic.LoadInt32(&exporter.debugLog.enabled))
+	assert.Nil(exporter.ExportSpans(ctx, []*trace.SpanSnapshot{{Name: "last"}}))
+
+	events := mockHoneycomb.Events()
+	if assert.Len(events, 3) {
+		assert.Equal("test", events[0].Dataset)
+		for _, ev := range events[1:] {
+			assert.Equal("reloaded", ev.Dataset)
+			assert.Equal(uint(5), ev.SampleRate)
+		}
+	}
+	assert.Nil(exporter.Shutdown(ctx))
+	assert.Equal(ErrExporterShutdown, exporter.Reload(WithSampleRate(2)))
+}
+
+func TestReloadConcurrently(t *testing.T) {
+	assert := assert.New(t)
+	exporter, err := makeTestExporter(&transmission.MockSender{})
+	if !assert.Nil(err) {
+		return
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "racing"}}))
+			}
+		}()
+	}
+	for rate := uint(1); rate <= 50; rate++ {
+		assert.Nil(exporter.Reload(WithSampleRate(rate)))
+	}
+	wg.Wait()
+	assert.Nil(exporter.Shutdown(context.Background()))
+}
@@ -0,0 +1,224 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// errSamplingStrategyUnchanged is returned when fetching a sampling strategy
+// that has not changed since it was last fetched.
+var errSamplingStrategyUnchanged = errors.New("sampling strategy unchanged")
+
+// defaultInitialProbability is the probability with which a RemoteSampler
+// samples spans until it fetches a strategy, unless configured otherwise, as
+// for Jaeger clients.
+const defaultInitialProbability = 0.001
+
+// samplingStrategy is the subset of the Jaeger sampling strategy response
+// format understood by RemoteSampler.
+type samplingStrategy struct {
+	ProbabilisticSampling *struct {
+		SamplingRate float64 `json:"samplingRate"`
+	} `json:"probabilisticSampling"`
+	OperationSampling *struct {
+		DefaultSamplingProbability float64 `json:"defaultSamplingProbability"`
+		PerOperationStrategies     []struct {
+			Operation             string `json:"operation"`
+			ProbabilisticSampling struct {
+				SamplingRate float64 `json:"samplingRate"`
+			} `json:"probabilisticSampling"`
+		} `json:"perOperationStrategies"`
+	} `json:"operationSampling"`
+}
+
+// remoteRates holds the sample rates of a fetched sampling strategy.
+type remoteRates struct {
+	defaultRate uint
+	operations  map[string]uint
+}
+
+// compile converts the probabilities of a sampling strategy to sample rates.
+func (s *samplingStrategy) compile() (*remoteRates, error) {
+	switch {
+	case s.OperationSampling != nil:
+		defaultRate, err := probabilityRate(s.OperationSampling.DefaultSamplingProbability)
+		if err != nil {
+			return nil, err
+		}
+		rates := &remoteRates{defaultRate: defaultRate, operations: make(map[string]uint)}
+		for _, op := range s.OperationSampling.PerOperationStrategies {
+			rate, err := probabilityRate(op.ProbabilisticSampling.SamplingRate)
+			if err != nil {
+				return nil, fmt.Errorf("operation %q: %w", op.Operation, err)
+			}
+			rates.operations[op.Operation] = rate
+		}
+		return rates, nil
+	case s.ProbabilisticSampling != nil:
+		defaultRate, err := probabilityRate(s.ProbabilisticSampling.SamplingRate)
+		if err != nil {
+			return nil, err
+		}
+		return &remoteRates{defaultRate: defaultRate}, nil
+	default:
+		return nil, errors.New("sampling strategy is neither probabilistic nor per-operation")
+	}
+}
+
+// rate returns the rate at which to sample the spans with the given name,
+// along with the operation that chose it, or "default."
+func (r *remoteRates) rate(name string) (uint, string) {
+	if rate, ok := r.operations[name]; ok {
+		return rate, name
+	}
+	return r.defaultRate, "default"
+}
+
+// RemoteSamplerConfig configures a RemoteSampler.
+type RemoteSamplerConfig struct {
+	// URL is the address of the sampling strategy endpoint, such as that of
+	// a Jaeger agent, "http://localhost:5778/sampling," or any HTTP endpoint
+	// serving strategies in the same JSON format.
+	URL string
+	// ServiceName, if not empty, is added to the URL as the "service" query
+	// parameter, selecting the strategy for this service.
+	ServiceName string
+	// Client is the HTTP client used to fetch strategies. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+	// RefreshInterval is the interval between fetches. If zero, strategies
+	// are fetched once a minute.
+	RefreshInterval time.Duration
+	// InitialProbability is the probability with which spans are sampled
+	// until a strategy has been fetched successfully. If zero, it is 0.001,
+	// as for Jaeger clients.
+	InitialProbability float64
+	// OnError, if not nil, is called with each failure to fetch or apply a
+	// strategy.
+	OnError func(error)
+}
+
+// RemoteSampler is a sampler that samples spans with probabilities set by a
+// sampling strategy fetched periodically from a remote endpoint, such as a
+// Jaeger agent, so that the sample rates of services can be adjusted
+// centrally without redeploying them. It understands probabilistic and
+// per-operation strategies, matching operations against span names. It
+// records the rate it applied in the "SampleRate" attribute of each span it
+// samples, and the operation that chose the rate in the
+// "meta.sample_reason" attribute, such as "remote:GET /cart" or
+// "remote:default."
+//
+// If a fetch fails, or returns a strategy it can't apply, RemoteSampler
+// keeps using its current rates and reports the failure to OnError.
+type RemoteSampler struct {
+	current atomic.Value // *remoteRates
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+var _ sdktrace.Sampler = (*RemoteSampler)(nil)
+
+// NewRemoteSampler returns a RemoteSampler that fetches its strategy
+// immediately and then once per refresh interval, until stopped with Stop.
+func NewRemoteSampler(config RemoteSamplerConfig) (*RemoteSampler, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sampling strategy URL: %w", err)
+	}
+	if len(config.ServiceName) != 0 {
+		q := u.Query()
+		q.Set("service", config.ServiceName)
+		u.RawQuery = q.Encode()
+	}
+	initialProbability := config.InitialProbability
+	if initialProbability == 0 {
+		initialProbability = defaultInitialProbability
+	}
+	initialRate, err := probabilityRate(initialProbability)
+	if err != nil {
+		return nil, err
+	}
+	interval := config.RefreshInterval
+	if interval < 0 {
+		return nil, errors.New("sampling strategy refresh interval must not be negative")
+	}
+	if interval == 0 {
+		interval = time.Minute
+	}
+	onError := config.OnError
+	if onError == nil {
+		onError = func(error) {}
+	}
+
+	s := &RemoteSampler{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	s.current.Store(&remoteRates{defaultRate: initialRate})
+
+	f := newJSONFetcher(u.String(), config.Client, "sampling strategy", errSamplingStrategyUnchanged)
+	fetch := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		defer cancel()
+		var strategy samplingStrategy
+		err := f.fetch(ctx, &strategy)
+		if err == nil {
+			var rates *remoteRates
+			if rates, err = strategy.compile(); err == nil {
+				s.current.Store(rates)
+			}
+		}
+		if err != nil && err != errSamplingStrategyUnchanged {
+			onError(fmt.Errorf("refreshing sampling strategy: %w", err))
+		}
+	}
+	go func() {
+		defer close(s.done)
+		fetch()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fetch()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	return s, nil
+}
+
+// Stop stops fetching strategies, leaving the sampler using the rates it
+// last fetched.
+func (s *RemoteSampler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	<-s.done
+}
+
+// ShouldSample implements sdktrace.Sampler.
+func (s *RemoteSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	rate, operation := s.current.Load().(*remoteRates).rate(p.Name)
+	if !sampledAtRate(p.TraceID, rate) {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop}
+	}
+	return sampledResult(rate, "remote:"+operation)
+}
+
+// Description implements sdktrace.Sampler.
+func (s *RemoteSampler) Description() string {
+	rates := s.current.Load().(*remoteRates)
+	return fmt.Sprintf("RemoteSampler{operations:%d,default:%d}", len(rates.operations), rates.defaultRate)
+}
@@ -0,0 +1,109 @@
+package honeycomb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestRemoteSampler(t *testing.T) {
+	assert := assert.New(t)
+
+	var strategy atomic.Value
+	strategy.Store(`{"strategyType": "PROBABILISTIC", "probabilisticSampling": {"samplingRate": 0.1}}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("service") != "checkout" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(strategy.Load().(string)))
+	}))
+	defer server.Close()
+
+	errs := make(chan error, 1)
+	s, err := NewRemoteSampler(RemoteSamplerConfig{
+		URL:                server.URL + "/sampling",
+		ServiceName:        "checkout",
+		RefreshInterval:    10 * time.Millisecond,
+		InitialProbability: 1,
+		OnError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+	})
+	assert.Nil(err)
+	defer s.Stop()
+
+	assert.Eventually(func() bool {
+		rate, _ := s.current.Load().(*remoteRates).rate("GET /cart")
+		return rate == 10
+	}, time.Second, time.Millisecond)
+
+	strategy.Store(`{"operationSampling": {
+		"defaultSamplingProbability": 0.5,
+		"perOperationStrategies": [
+			{"operation": "GET /cart", "probabilisticSampling": {"samplingRate": 1}},
+			{"operation": "GET /healthz", "probabilisticSampling": {"samplingRate": 0}}
+		]
+	}}`)
+	assert.Eventually(func() bool {
+		rate, _ := s.current.Load().(*remoteRates).rate("GET /other")
+		return rate == 2
+	}, time.Second, time.Millisecond)
+
+	result := s.ShouldSample(sdktrace.SamplingParameters{Name: "GET /cart"})
+	assert.Equal(sdktrace.RecordAndSample, result.Decision)
+	assert.Equal([]label.KeyValue{
+		label.Int64("SampleRate", 1),
+		label.String("meta.sample_reason", "remote:GET /cart"),
+	}, result.Attributes)
+	result = s.ShouldSample(sdktrace.SamplingParameters{Name: "GET /healthz"})
+	assert.Equal(sdktrace.Drop, result.Decision)
+
+	// Strategies that can't be applied are reported, and the current rates
+	// kept.
+	strategy.Store(`{"rateLimitingSampling": {"maxTracesPerSecond": 10}}`)
+	select {
+	case err := <-errs:
+		assert.Error(err)
+	case <-time.After(time.Second):
+		t.Fatal("expected an error")
+	}
+	rate, _ := s.current.Load().(*remoteRates).rate("GET /other")
+	assert.Equal(uint(2), rate)
+}
+
+func TestRemoteSamplerInitialProbability(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	s, err := NewRemoteSampler(RemoteSamplerConfig{URL: server.URL + "/sampling"})
+	if !assert.Nil(err) {
+		return
+	}
+	defer s.Stop()
+	rate, reason := s.current.Load().(*remoteRates).rate("GET /cart")
+	assert.Equal(uint(1000), rate)
+	assert.Equal("default", reason)
+}
+
+func TestNewRemoteSamplerValidation(t *testing.T) {
+	_, err := NewRemoteSampler(RemoteSamplerConfig{URL: "http://localhost:5778/sampling", InitialProbability: 2})
+	assert.Error(t, err)
+	_, err = NewRemoteSampler(RemoteSamplerConfig{URL: "http://localhost:5778/sampling", RefreshInterval: -time.Second})
+	assert.Error(t, err)
+	_, err = NewRemoteSampler(RemoteSamplerConfig{URL: ":"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package honeycomb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// maxResponseBodyExcerpt is the number of bytes of a response body passed to
+// the hook given to CallingOnResponse.
+const maxResponseBodyExcerpt = 256
+
+// SpanReference identifies the span for which the exporter sent an event.
+type SpanReference struct {
+	TraceID apitrace.TraceID
+	SpanID  apitrace.SpanID
+}
+
+// spanReference returns the reference to a span attached to its events.
+func spanReference(s *trace.SpanSnapshot) SpanReference {
+	return SpanReference{TraceID: s.SpanContext.TraceID, SpanID: s.SpanContext.SpanID}
+}
+
+// eventMetadata is attached to each event the exporter sends, and returned
+// with libhoney's response to it.
+type eventMetadata struct {
+	// span identifies the span for which the event was sent.
+	span SpanReference
+	// dataset is the dataset to which the event was sent.
+	dataset string
+}
+
+// SpanError is the error passed to the error hook when Honeycomb doesn't
+// accept an event, identifying the span for which it was sent, so that the
+// traces affected by a failure can be found. Errors.Is and errors.As see
+// through it to the reason for the failure.
+type SpanError struct {
+	// Span identifies the span.
+	Span SpanReference
+	// Err is the reason the event wasn't accepted.
+	Err error
+}
+
+func (e *SpanError) Error() string {
+	return fmt.Sprintf("%v (trace %s, span %s)", e.Err, e.Span.TraceID, e.Span.SpanID)
+}
+
+// Unwrap returns the reason the event wasn't accepted.
+func (e *SpanError) Unwrap() error {
+	return e.Err
+}
+
+// Response describes Honeycomb's response to an event the exporter sent.
+// Libhoney reports the outcome of sending each event separately, even when
+// it sends them in batches, so each Response describes one event.
+type Response struct {
+	// StatusCode is the HTTP status with which Honeycomb responded for the
+	// event, such as 202 when it accepted the event, or zero if the event
+	// wasn't sent.
+	StatusCode int
+	// Duration is the event's share of the time taken by the request that
+	// sent it.
+	Duration time.Duration
+	// Body is the start of the body of Honeycomb's response to a failed
+	// request, up to 256 bytes.
+	Body []byte
+	// Span identifies the span for which the event was sent, if known.
+	Span SpanReference
+	// Err is the reason the event wasn't accepted, as passed to the error
+	// hook, or nil if it was.
+	Err error
+}
+
+// CallingOnResponse specifies a hook function to be called with Honeycomb's
+// response to each event the exporter sends, whether it succeeded or failed,
+// such as for measuring delivery latency and success rates. The hook is
+// called by the goroutine run by Start, or by RunErrorLogger, before any
+// call to the error hook for the same response.
+func CallingOnResponse(f func(Response)) ExporterOption {
+	return func(c *exporterConfig) error {
+		c.onResponse = f
+		return nil
+	}
+}
+
+// handleResponse reports a transmission response to the response hook, and
+// any failure to the error hook.
+func (e *Exporter) handleResponse(r transmission.Response) {
+	meta, known := r.Metadata.(eventMetadata)
+	dataset := meta.dataset
+	if !known {
+		dataset = e.settings().dataset
+	}
+	err := e.responseError(r, dataset)
+	span := meta.span
+	if err != nil && known {
+		err = &SpanError{Span: span, Err: err}
+	}
+	e.recordResponse(span, err)
+	if e.onResponse != nil {
+		body := r.Body
+		if len(body) > maxResponseBodyExcerpt {
+			body = body[:maxResponseBodyExcerpt]
+		}
+		e.onResponse(Response{
+			StatusCode: r.StatusCode,
+			Duration:   r.Duration,
+			Body:       body,
+			Span:       span,
+			Err:        err,
+		})
+	}
+	if err != nil {
+		e.onError(err)
+	}
+}
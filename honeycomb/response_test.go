@@ -0,0 +1,130 @@
+package honeycomb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestOnResponse(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{BlockOnResponses: true}
+	responses := make(chan Response, 2)
+	var errs []error
+	exporter, err := makeTestExporter(mockHoneycomb,
+		CallingOnResponse(func(r Response) {
+			responses <- r
+		}),
+		CallingOnError(func(err error) {
+			errs = append(errs, err)
+		}))
+	assert.Nil(err)
+	assert.Nil(exporter.Start(context.Background()))
+
+	body := bytes.Repeat([]byte("x"), 1000)
+	mockHoneycomb.SendResponse(transmission.Response{StatusCode: http.StatusAccepted, Duration: time.Millisecond})
+	mockHoneycomb.SendResponse(transmission.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Duration:   2 * time.Millisecond,
+		Body:       body,
+		Err:        errors.New("got unexpected HTTP status 429"),
+	})
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	close(responses)
+	var got []Response
+	for r := range responses {
+		got = append(got, r)
+	}
+	if assert.Len(got, 2) {
+		assert.Equal(Response{StatusCode: http.StatusAccepted, Duration: time.Millisecond}, got[0])
+		assert.Equal(http.StatusTooManyRequests, got[1].StatusCode)
+		assert.Equal(2*time.Millisecond, got[1].Duration)
+		assert.Equal(body[:maxResponseBodyExcerpt], got[1].Body)
+		assert.True(errors.Is(got[1].Err, ErrRateLimited))
+	}
+	if assert.Len(errs, 1) {
+		assert.True(errors.Is(errs[0], ErrRateLimited))
+	}
+}
+
+func TestResponseSpanReference(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{BlockOnResponses: true}
+	responses := make(chan Response, 1)
+	errs := make(chan error, 1)
+	exporter, err := makeTestExporter(mockHoneycomb,
+		CallingOnResponse(func(r Response) {
+			responses <- r
+		}),
+		CallingOnError(func(err error) {
+			errs <- err
+		}))
+	assert.Nil(err)
+	assert.Nil(exporter.Start(context.Background()))
+	defer exporter.Shutdown(context.Background())
+
+	ref := SpanReference{TraceID: apitrace.TraceID{1}, SpanID: apitrace.SpanID{2}}
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{
+		SpanContext: apitrace.SpanContext{TraceID: ref.TraceID, SpanID: ref.SpanID},
+		Name:        "lost",
+	}}))
+	events := mockHoneycomb.Events()
+	if !assert.Len(events, 1) {
+		return
+	}
+	assert.Equal(eventMetadata{span: ref, dataset: "test"}, events[0].Metadata)
+
+	mockHoneycomb.SendResponse(transmission.Response{
+		StatusCode: http.StatusUnauthorized,
+		Err:        errors.New("got unexpected HTTP status 401"),
+		Metadata:   events[0].Metadata,
+	})
+	r := <-responses
+	assert.Equal(ref, r.Span)
+	err = <-errs
+	var spanErr *SpanError
+	if assert.True(errors.As(err, &spanErr)) {
+		assert.Equal(ref, spanErr.Span)
+	}
+	assert.True(errors.Is(err, ErrUnauthorized))
+	assert.Contains(err.Error(), ref.TraceID.String())
+}
+
+func TestResponseDatasetNotFound(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{BlockOnResponses: true}
+	errs := make(chan error, 1)
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithMirrorDataset("archive", 1),
+		CallingOnError(func(err error) {
+			errs <- err
+		}))
+	assert.Nil(err)
+	assert.Nil(exporter.Start(context.Background()))
+	defer exporter.Shutdown(context.Background())
+
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "mirrored"}}))
+	events := mockHoneycomb.Events()
+	if !assert.Len(events, 2) || !assert.Equal("archive", events[0].Dataset) {
+		return
+	}
+	mockHoneycomb.SendResponse(transmission.Response{
+		StatusCode: http.StatusNotFound,
+		Err:        errors.New("got unexpected HTTP status 404"),
+		Metadata:   events[0].Metadata,
+	})
+	var notFound *DatasetNotFoundError
+	if assert.True(errors.As(<-errs, &notFound)) {
+		assert.Equal("archive", notFound.Dataset)
+	}
+}
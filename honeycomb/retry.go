@@ -0,0 +1,152 @@
+package honeycomb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Defaults of the fields of RetryPolicy left zero.
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 500 * time.Millisecond
+	defaultRetryMaxBackoff     = 30 * time.Second
+)
+
+// RetryPolicy configures WithRetry.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times to send each batch of events,
+	// including the first. If zero, batches are sent at most three times.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubling before
+	// each further retry. If zero, it is half a second.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay before each retry, including that requested
+	// by a Retry-After header: if Honeycomb asks for a longer wait, the
+	// exporter gives up on the batch instead. If zero, it is 30 seconds.
+	MaxBackoff time.Duration
+	// Jitter is the fraction, from zero to one, by which each delay is
+	// randomly shortened, so that exporters failing together don't retry
+	// together. If zero, delays aren't randomized.
+	Jitter float64
+}
+
+// WithRetry causes the exporter to send each batch of events to Honeycomb
+// again when sending it fails in a way that may be transient: when the
+// request fails without a response, or Honeycomb responds with HTTP status
+// 429 or 5xx. It waits between attempts as policy says, with exponential
+// backoff, or as long as Honeycomb asks with a Retry-After header, and
+// reports the failure to the error hook only once the last attempt fails.
+// Retries delay the batches behind the failing one, and Shutdown, by up to
+// the sum of the delays.
+//
+// Without WithRetry, libhoney retries only requests timing out. WithRetry
+// has no effect on a transmission given to WithSender.
+func WithRetry(policy RetryPolicy) ExporterOption {
+	return func(c *exporterConfig) error {
+		if policy.MaxAttempts < 0 {
+			return errors.New("retry attempts must not be negative")
+		}
+		if policy.InitialBackoff < 0 || policy.MaxBackoff < 0 {
+			return errors.New("retry backoff must not be negative")
+		}
+		if math.IsNaN(policy.Jitter) || policy.Jitter < 0 || policy.Jitter > 1 {
+			return fmt.Errorf("retry jitter must be between 0 and 1, not %v", policy.Jitter)
+		}
+		if policy.MaxAttempts == 0 {
+			policy.MaxAttempts = defaultRetryMaxAttempts
+		}
+		if policy.InitialBackoff == 0 {
+			policy.InitialBackoff = defaultRetryInitialBackoff
+		}
+		if policy.MaxBackoff == 0 {
+			policy.MaxBackoff = defaultRetryMaxBackoff
+		}
+		if policy.InitialBackoff > policy.MaxBackoff {
+			return errors.New("initial retry backoff must not exceed the maximum")
+		}
+		c.retryPolicy = &policy
+		return nil
+	}
+}
+
+// retryTransport retries the requests sending batches of events that fail
+// transiently.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// libhoney's request bodies can't be read twice, so keep a copy.
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for attempt := 1; ; attempt++ {
+		r := req
+		if body != nil {
+			r = req.Clone(req.Context())
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+		resp, err := t.base.RoundTrip(r)
+		if attempt >= t.policy.MaxAttempts || req.Context().Err() != nil || !retryable(resp, err) {
+			return resp, err
+		}
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if d > t.policy.MaxBackoff {
+					return resp, nil
+				}
+				if d > delay {
+					delay = d
+				}
+			}
+			// Drain the body so that the connection may be reused.
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// backoff returns the delay before the retry following the given attempt.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.policy.MaxBackoff
+	if attempt < 32 {
+		if d := t.policy.InitialBackoff << uint(attempt-1); d > 0 && d < delay {
+			delay = d
+		}
+	}
+	if t.policy.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * t.policy.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// retryable reports whether a request failed in a way that may be transient.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
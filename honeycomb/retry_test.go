@@ -0,0 +1,173 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// flakyServer responds to the first requests with the given statuses and
+// headers, and accepts events afterward, recording the bodies of the
+// requests.
+type flakyServer struct {
+	mu       sync.Mutex
+	failures []int
+	header   http.Header
+	bodies   [][]byte
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	s.mu.Lock()
+	s.bodies = append(s.bodies, body)
+	var status int
+	if len(s.failures) != 0 {
+		status, s.failures = s.failures[0], s.failures[1:]
+	}
+	s.mu.Unlock()
+	if status != 0 {
+		for name, values := range s.header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(status)
+		return
+	}
+	io.WriteString(w, `[{"status": 202}]`)
+}
+
+func (s *flakyServer) requests() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bodies
+}
+
+// exportThroughRetries exports a span to server through an exporter
+// configured with policy, returning the statuses of the responses and the
+// errors reported.
+func exportThroughRetries(t *testing.T, server *httptest.Server, policy RetryPolicy) (statuses []int, errs []error) {
+	var mu sync.Mutex
+	exporter, err := NewExporter(Config{APIKey: "overridden"},
+		TargetingDataset("test"),
+		WithAPIURL(server.URL),
+		WithRetry(policy),
+		CallingOnResponse(func(r Response) {
+			mu.Lock()
+			statuses = append(statuses, r.StatusCode)
+			mu.Unlock()
+		}),
+		CallingOnError(func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}))
+	if !assert.Nil(t, err) {
+		return nil, nil
+	}
+	assert.Nil(t, exporter.Start(context.Background()))
+	assert.Nil(t, exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "retried"}}))
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+	return statuses, errs
+}
+
+func TestRetry(t *testing.T) {
+	assert := assert.New(t)
+	flaky := &flakyServer{failures: []int{http.StatusServiceUnavailable, http.StatusBadGateway}}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	statuses, errs := exportThroughRetries(t, server, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Jitter: 0.5})
+	assert.Equal([]int{http.StatusAccepted}, statuses)
+	assert.Empty(errs)
+	bodies := flaky.requests()
+	if assert.Len(bodies, 3) {
+		assert.NotEmpty(bodies[0])
+		assert.Equal(bodies[0], bodies[1])
+		assert.Equal(bodies[0], bodies[2])
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	assert := assert.New(t)
+	flaky := &flakyServer{
+		failures: []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests},
+		header:   http.Header{"Retry-After": []string{"0"}},
+	}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	statuses, errs := exportThroughRetries(t, server, RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+	assert.Equal([]int{http.StatusTooManyRequests}, statuses)
+	if assert.Len(errs, 1) {
+		assert.True(errors.Is(errs[0], ErrRateLimited))
+	}
+	assert.Len(flaky.requests(), 2)
+}
+
+func TestRetryAfterBeyondMaxBackoff(t *testing.T) {
+	assert := assert.New(t)
+	flaky := &flakyServer{
+		failures: []int{http.StatusTooManyRequests},
+		header:   http.Header{"Retry-After": []string{"120"}},
+	}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	statuses, errs := exportThroughRetries(t, server, RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Second})
+	assert.Equal([]int{http.StatusTooManyRequests}, statuses)
+	if assert.Len(errs, 1) {
+		var te *TransmissionError
+		if assert.True(errors.As(errs[0], &te)) {
+			assert.InDelta(float64(120*time.Second), float64(te.RetryAfter), float64(10*time.Second))
+		}
+	}
+	assert.Len(flaky.requests(), 1)
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	assert := assert.New(t)
+	flaky := &flakyServer{failures: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	statuses, errs := exportThroughRetries(t, server, RetryPolicy{InitialBackoff: time.Millisecond})
+	assert.Equal([]int{http.StatusBadRequest}, statuses)
+	assert.Len(errs, 1)
+	assert.Len(flaky.requests(), 1)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	assert := assert.New(t)
+	rt := &retryTransport{policy: RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}}
+	assert.Equal(time.Second, rt.backoff(1))
+	assert.Equal(2*time.Second, rt.backoff(2))
+	assert.Equal(4*time.Second, rt.backoff(3))
+	assert.Equal(5*time.Second, rt.backoff(4))
+	assert.Equal(5*time.Second, rt.backoff(100))
+
+	rt.policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := rt.backoff(2)
+		assert.True(d > time.Second && d <= 2*time.Second, "backoff %v out of range", d)
+	}
+}
+
+func TestWithRetryInvalid(t *testing.T) {
+	for _, policy := range []RetryPolicy{
+		{MaxAttempts: -1},
+		{InitialBackoff: -time.Second},
+		{Jitter: 1.5},
+		{InitialBackoff: time.Minute, MaxBackoff: time.Second},
+	} {
+		assert.Error(t, ValidateOptions(WithRetry(policy)), "%+v", policy)
+	}
+}
@@ -0,0 +1,123 @@
+package honeycomb
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+
+	"go.opentelemetry.io/otel/label"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// httpRouteKey is the attribute holding the route template of an HTTP
+// request.
+const httpRouteKey = label.Key("http.route")
+
+// routePattern associates a route pattern with the rate at which to sample
+// the spans it matches.
+type routePattern struct {
+	pattern string
+	rate    uint
+}
+
+// RouteSampler is a sampler that samples spans with probabilities chosen by
+// their routes: the value of their "http.route" attribute if present when
+// they start, or else their names. It records the rate it applied in the
+// "SampleRate" attribute of each span it samples, and the pattern that chose
+// the rate in the "meta.sample_reason" attribute, such as "routes:/api/*" for
+// the pattern "/api/*" or "routes:default" for the default probability.
+type RouteSampler struct {
+	exact       map[string]uint
+	patterns    []routePattern
+	defaultRate uint
+}
+
+var _ sdktrace.Sampler = (*RouteSampler)(nil)
+
+// probabilityRate converts a sampling probability to the nearest whole sample
+// rate, such that a probability of 0.01 keeps one span in 100.
+func probabilityRate(p float64) (uint, error) {
+	if math.IsNaN(p) || p < 0 || p > 1 {
+		return 0, fmt.Errorf("sampling probability must be between 0 and 1, not %v", p)
+	}
+	if p == 0 {
+		return 0, nil
+	}
+	return uint(math.Round(1 / p)), nil
+}
+
+// NewRouteSampler returns a RouteSampler that samples the spans for each
+// route with the probability given for the first matching pattern in routes,
+// or defaultProbability if none match. Probabilities range from 0, dropping
+// every span, to 1, keeping every span, and are rounded to the nearest whole
+// sample rate.
+//
+// Patterns use the syntax of path.Match, in which "*" matches any sequence
+// of characters other than "/". A pattern without wildcards that equals a
+// route takes precedence over those with wildcards, of which longer patterns
+// take precedence over shorter ones.
+func NewRouteSampler(defaultProbability float64, routes map[string]float64) (*RouteSampler, error) {
+	defaultRate, err := probabilityRate(defaultProbability)
+	if err != nil {
+		return nil, err
+	}
+	s := &RouteSampler{
+		exact:       make(map[string]uint),
+		defaultRate: defaultRate,
+	}
+	for pattern, p := range routes {
+		rate, err := probabilityRate(p)
+		if err != nil {
+			return nil, fmt.Errorf("route %q: %w", pattern, err)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("route %q: %w", pattern, err)
+		}
+		s.exact[pattern] = rate
+		s.patterns = append(s.patterns, routePattern{pattern, rate})
+	}
+	sort.Slice(s.patterns, func(i, j int) bool {
+		pi, pj := s.patterns[i].pattern, s.patterns[j].pattern
+		if len(pi) != len(pj) {
+			return len(pi) > len(pj)
+		}
+		return pi < pj
+	})
+	return s, nil
+}
+
+// rate returns the rate at which to sample the spans for a route, along with
+// the pattern that chose it, or "default."
+func (s *RouteSampler) rate(route string) (uint, string) {
+	if rate, ok := s.exact[route]; ok {
+		return rate, route
+	}
+	for _, p := range s.patterns {
+		if ok, _ := path.Match(p.pattern, route); ok {
+			return p.rate, p.pattern
+		}
+	}
+	return s.defaultRate, "default"
+}
+
+// ShouldSample implements sdktrace.Sampler.
+func (s *RouteSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	route := p.Name
+	for _, kv := range p.Attributes {
+		if kv.Key == httpRouteKey {
+			route = kv.Value.Emit()
+			break
+		}
+	}
+	rate, pattern := s.rate(route)
+	if !sampledAtRate(p.TraceID, rate) {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop}
+	}
+	return sampledResult(rate, "routes:"+pattern)
+}
+
+// Description implements sdktrace.Sampler.
+func (s *RouteSampler) Description() string {
+	return fmt.Sprintf("RouteSampler{routes:%d,default:%d}", len(s.patterns), s.defaultRate)
+}
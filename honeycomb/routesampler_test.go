@@ -0,0 +1,63 @@
+package honeycomb
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestRouteSampler(t *testing.T) {
+	s, err := NewRouteSampler(0.1, map[string]float64{
+		"/healthz":      0,
+		"/checkout":     1,
+		"/api/*":        0.5,
+		"/api/*/orders": 0.25,
+		"/api/v1":       0.01,
+	})
+	assert.Nil(t, err)
+
+	tests := []struct {
+		route       string
+		want        uint
+		wantPattern string
+	}{
+		{"/healthz", 0, "/healthz"},
+		{"/checkout", 1, "/checkout"},
+		{"/api/v1", 100, "/api/v1"},
+		{"/api/v2", 2, "/api/*"},
+		{"/api/v2/orders", 4, "/api/*/orders"},
+		{"/api/v2/orders/7", 10, "default"},
+		{"/other", 10, "default"},
+	}
+	for _, test := range tests {
+		rate, pattern := s.rate(test.route)
+		assert.Equal(t, test.want, rate, test.route)
+		assert.Equal(t, test.wantPattern, pattern, test.route)
+	}
+
+	result := s.ShouldSample(sdktrace.SamplingParameters{
+		Name:       "GET",
+		Attributes: []label.KeyValue{label.String("http.route", "/api/v2")},
+	})
+	assert.Equal(t, sdktrace.RecordAndSample, result.Decision)
+	assert.Equal(t, []label.KeyValue{
+		label.Int64("SampleRate", 2),
+		label.String("meta.sample_reason", "routes:/api/*"),
+	}, result.Attributes)
+
+	result = s.ShouldSample(sdktrace.SamplingParameters{Name: "/healthz"})
+	assert.Equal(t, sdktrace.Drop, result.Decision)
+}
+
+func TestNewRouteSamplerValidation(t *testing.T) {
+	_, err := NewRouteSampler(1.5, nil)
+	assert.Error(t, err)
+	_, err = NewRouteSampler(math.NaN(), nil)
+	assert.Error(t, err)
+	_, err = NewRouteSampler(1, map[string]float64{"/a": -1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+package honeycomb
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// RoutingRule decides, at export time, what the exporter does with the spans
+// it matches: drop them, send their events to another dataset, sample them,
+// or both of the latter. A span matches a rule if it satisfies all of the
+// rule's conditions.
+type RoutingRule struct {
+	// Name identifies the rule in errors about it. If empty, the rule is
+	// identified by its position, such as "#2."
+	Name string
+	// SpanName, if not empty, is the name a span must have.
+	SpanName string
+	// SpanKind, if not SpanKindUnspecified, is the kind a span must have.
+	SpanKind apitrace.SpanKind
+	// Attributes are the attributes a span must have, with their values in
+	// their string form, such as "200" or "true."
+	Attributes map[string]string
+	// ResourceAttributes are the attributes a span's resource must have,
+	// with their values in their string form.
+	ResourceAttributes map[string]string
+
+	// Drop, if set, causes the exporter to drop the matching spans.
+	Drop bool
+	// Dataset, if not empty, is the dataset to which the exporter sends the
+	// events for the matching spans.
+	Dataset string
+	// SampleRate, if nonzero, is the rate at which the exporter samples the
+	// matching spans, keeping those of one trace in SampleRate independently
+	// of any sampler's decision, and records it in their events. A rate of
+	// one keeps them all, exempting them from later rules.
+	SampleRate uint
+}
+
+func (r *RoutingRule) matches(data *trace.SpanSnapshot) bool {
+	if len(r.SpanName) != 0 && r.SpanName != data.Name {
+		return false
+	}
+	if r.SpanKind != apitrace.SpanKindUnspecified && r.SpanKind != data.SpanKind {
+		return false
+	}
+	for name, want := range r.Attributes {
+		found := false
+		for _, kv := range data.Attributes {
+			if string(kv.Key) == name {
+				found = kv.Value.Emit() == want
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(r.ResourceAttributes) == 0 {
+		return true
+	}
+	if data.Resource == nil {
+		return false
+	}
+	set := data.Resource.LabelSet()
+	for name, want := range r.ResourceAttributes {
+		if v, ok := set.Value(label.Key(name)); !ok || v.Emit() != want {
+			return false
+		}
+	}
+	return true
+}
+
+// WithRoutingRules causes the exporter to apply the first of an ordered list
+// of rules that matches each span, such as this one, which sends the spans of
+// synthetic monitoring to a dataset of their own:
+//
+//	honeycomb.WithRoutingRules(honeycomb.RoutingRule{
+//		Name:       "synthetics",
+//		Attributes: map[string]string{"http.user_agent": "HoneycombSynthetics/1.0"},
+//		Dataset:    "synthetics",
+//	})
+//
+// Spans matching none of the rules are exported as usual. A rule's dataset
+// takes precedence over those chosen by WithDatasetTemplate or for an
+// Environments & Services API key, but events sent to other datasets by
+// options such as WithErrorsDataset are unaffected. A rule's sample rate
+// multiplies any other rate at which the span is sampled.
+//
+// Each rule must drop the spans it matches, or else send them to a dataset or
+// sample them.
+func WithRoutingRules(rules ...RoutingRule) ExporterOption {
+	return func(c *exporterConfig) error {
+		if len(rules) == 0 {
+			return errors.New("routing rules must not be empty")
+		}
+		for i := range rules {
+			r := &rules[i]
+			name := r.Name
+			if len(name) == 0 {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			switch {
+			case r.Drop && (len(r.Dataset) != 0 || r.SampleRate != 0):
+				return fmt.Errorf("routing rule %s must not both drop spans and send them", name)
+			case !r.Drop && len(r.Dataset) == 0 && r.SampleRate == 0:
+				return fmt.Errorf("routing rule %s must drop spans, or name a dataset or sample rate", name)
+			}
+		}
+		c.routingRules = append([]RoutingRule(nil), rules...)
+		return nil
+	}
+}
+
+// routingRule returns the first of the exporter's routing rules that matches
+// a span, or nil if none match.
+func (e *Exporter) routingRule(data *trace.SpanSnapshot) *RoutingRule {
+	for i := range e.routingRules {
+		if r := &e.routingRules[i]; r.matches(data) {
+			return r
+		}
+	}
+	return nil
+}
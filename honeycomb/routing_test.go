@@ -0,0 +1,97 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestRoutingRules(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := makeTestExporter(mockHoneycomb, WithRoutingRules(
+		RoutingRule{SpanName: "/healthz", Drop: true},
+		RoutingRule{
+			Name:       "synthetics",
+			Attributes: map[string]string{"synthetic": "true"},
+			Dataset:    "synthetics",
+		},
+		RoutingRule{
+			SpanKind:           apitrace.SpanKindClient,
+			ResourceAttributes: map[string]string{"deployment.environment": "staging"},
+			SampleRate:         1,
+		},
+		RoutingRule{ResourceAttributes: map[string]string{"deployment.environment": "staging"}, Drop: true},
+	))
+	if !assert.Nil(err) {
+		return
+	}
+	staging := resource.NewWithAttributes(label.String("deployment.environment", "staging"))
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{
+		{Name: "/healthz"},
+		{
+			Name:          "synthetic",
+			Attributes:    []label.KeyValue{label.Bool("synthetic", true)},
+			MessageEvents: []trace.Event{{Name: "annotation"}},
+		},
+		{Name: "staging client", SpanKind: apitrace.SpanKindClient, Resource: staging},
+		{Name: "staging server", SpanKind: apitrace.SpanKindServer, Resource: staging},
+		{Name: "plain"},
+	}))
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	datasets := make(map[string]string)
+	for _, ev := range mockHoneycomb.Events() {
+		datasets[ev.Data["name"].(string)] = ev.Dataset
+	}
+	assert.Equal(map[string]string{
+		"synthetic":      "synthetics",
+		"annotation":     "synthetics",
+		"staging client": "test",
+		"plain":          "test",
+	}, datasets)
+}
+
+func TestRoutingRuleSampleRate(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := makeTestExporter(mockHoneycomb, WithRoutingRules(
+		RoutingRule{SpanName: "sampled", SampleRate: 4},
+	))
+	if !assert.Nil(err) {
+		return
+	}
+	var spans []*trace.SpanSnapshot
+	for i := 0; i < 200; i++ {
+		spans = append(spans, &trace.SpanSnapshot{
+			Name:        "sampled",
+			SpanContext: apitrace.SpanContext{TraceID: apitrace.TraceID{byte(i), byte(i * 7)}},
+		})
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), spans))
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	events := mockHoneycomb.Events()
+	assert.True(len(events) > 20 && len(events) < 80, len(events))
+	for _, ev := range events {
+		assert.Equal(uint(4), ev.SampleRate)
+	}
+}
+
+func TestRoutingRulesInvalid(t *testing.T) {
+	for _, rules := range [][]RoutingRule{
+		nil,
+		{{SpanName: "nothing"}},
+		{{SpanName: "both", Drop: true, Dataset: "elsewhere"}},
+	} {
+		_, err := makeTestExporter(&transmission.MockSender{}, WithRoutingRules(rules...))
+		assert.Error(t, err)
+	}
+}
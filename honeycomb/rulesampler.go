@@ -0,0 +1,272 @@
+package honeycomb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"go.opentelemetry.io/otel/label"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// sampleRateKey is the span attribute through which samplers record the rate
+// at which they sampled a span, and sampleReasonKey the one through which they
+// record why, which the exporter sends as a field of the same name.
+const (
+	sampleRateKey   = label.Key("SampleRate")
+	sampleReasonKey = label.Key(sampleReasonField)
+)
+
+// sampledResult returns the result of sampling a span at the given rate for
+// the given reason.
+func sampledResult(rate uint, reason string) sdktrace.SamplingResult {
+	return sdktrace.SamplingResult{
+		Decision: sdktrace.RecordAndSample,
+		Attributes: []label.KeyValue{
+			sampleRateKey.Int64(int64(rate)),
+			sampleReasonKey.String(reason),
+		},
+	}
+}
+
+// spanSampleRate returns the rate recorded in a span's "SampleRate"
+// attribute, if present and positive.
+func spanSampleRate(attrs []label.KeyValue) (uint, bool) {
+	for _, kv := range attrs {
+		if kv.Key != sampleRateKey {
+			continue
+		}
+		var rate int64
+		switch kv.Value.Type() {
+		case label.INT64:
+			rate = kv.Value.AsInt64()
+		case label.INT32:
+			rate = int64(kv.Value.AsInt32())
+		default:
+			return 0, false
+		}
+		if rate <= 0 {
+			return 0, false
+		}
+		return uint(rate), true
+	}
+	return 0, false
+}
+
+// SamplingRule selects the rate at which a RuleSampler samples the spans it
+// matches. A span matches a rule if it satisfies all of the rule's
+// conditions.
+type SamplingRule struct {
+	// Name identifies the rule in the "meta.sample_reason" field of the
+	// events for the spans it samples. If empty, the rule is identified by
+	// its position, such as "#2."
+	Name string
+	// SpanName, if not empty, is the name a span must have.
+	SpanName string
+	// SpanKind, if not SpanKindUnspecified, is the kind a span must have.
+	SpanKind apitrace.SpanKind
+	// Attributes are the attributes a span must have when it starts, with
+	// their values in their string form, such as "200" or "true."
+	Attributes map[string]string
+	// SampleRate is the rate at which to sample the matching spans, keeping
+	// one in SampleRate of them. A rate of zero drops them all.
+	SampleRate uint
+}
+
+func (r *SamplingRule) matches(p sdktrace.SamplingParameters) bool {
+	if len(r.SpanName) != 0 && r.SpanName != p.Name {
+		return false
+	}
+	if r.SpanKind != apitrace.SpanKindUnspecified && r.SpanKind != p.Kind {
+		return false
+	}
+	for name, want := range r.Attributes {
+		found := false
+		for _, kv := range p.Attributes {
+			if string(kv.Key) == name {
+				found = kv.Value.Emit() == want
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// RuleSampler is a sampler that chooses the rate at which to sample each span
+// from the first of an ordered list of rules that matches it, falling back to
+// a default rate. It records the rate it applied in the "SampleRate"
+// attribute of each span it samples, which the exporter sends as the sample
+// rate of the span's events, and the rule it applied in the
+// "meta.sample_reason" attribute, such as "rules:checkout" for a rule named
+// "checkout" or "rules:default" for the default rate.
+//
+// Whether a RuleSampler samples a span depends only on its trace ID and the
+// applicable rate, so spans of the same trace sampled at the same rate are
+// kept or dropped together. Spans of a trace that rules sample at different
+// rates may be kept or dropped apart. Wrapping a RuleSampler with
+// sdktrace.ParentBased keeps whole traces together, but the child spans it
+// then samples lack the "SampleRate" attribute, so Honeycomb weights their
+// events as if every such span had been kept; prefer rules that sample all
+// the spans of a trace at one rate instead.
+type RuleSampler struct {
+	rules       []SamplingRule
+	defaultRate uint
+}
+
+var _ sdktrace.Sampler = (*RuleSampler)(nil)
+
+// NewRuleSampler returns a RuleSampler that applies the given rules in order,
+// sampling spans that match none of them at defaultRate.
+func NewRuleSampler(defaultRate uint, rules ...SamplingRule) *RuleSampler {
+	return &RuleSampler{
+		rules:       rules,
+		defaultRate: defaultRate,
+	}
+}
+
+// ShouldSample implements sdktrace.Sampler.
+func (s *RuleSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	rate, reason := s.defaultRate, "rules:default"
+	for i := range s.rules {
+		if r := &s.rules[i]; r.matches(p) {
+			rate = r.SampleRate
+			if len(r.Name) != 0 {
+				reason = "rules:" + r.Name
+			} else {
+				reason = fmt.Sprintf("rules:#%d", i+1)
+			}
+			break
+		}
+	}
+	if !sampledAtRate(p.TraceID, rate) {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop}
+	}
+	return sampledResult(rate, reason)
+}
+
+// Description implements sdktrace.Sampler.
+func (s *RuleSampler) Description() string {
+	return fmt.Sprintf("RuleSampler{rules:%d,default:%d}", len(s.rules), s.defaultRate)
+}
+
+// sampledAtRate reports whether to keep the trace with the given ID when
+// keeping one trace in rate, basing the decision on the ID as
+// TraceIDRatioBased does.
+func sampledAtRate(traceID apitrace.TraceID, rate uint) bool {
+	if rate == 0 {
+		return false
+	}
+	bound := uint64(1<<63) / uint64(rate)
+	return binary.BigEndian.Uint64(traceID[0:8])>>1 < bound
+}
+
+// exporterSampled reports whether the exporter's sampling stage with the
+// given name keeps the trace with the given ID when keeping one trace in
+// rate. Unlike sampledAtRate, it bases the decision on a hash of the ID
+// salted with the stage's name, so that it's independent both of any head
+// sampler's decision and of the exporter's other stages. Otherwise, a stage
+// stacked on a sampler would keep a subset of the traces the sampler kept,
+// and the sample rates multiplied for both would overstate the traces they
+// represent.
+func exporterSampled(traceID apitrace.TraceID, stage string, rate uint) bool {
+	if rate == 0 {
+		return false
+	}
+	h := fnv.New64a()
+	io.WriteString(h, stage)
+	h.Write(traceID[:])
+	// Finish with SplitMix64's mixer, as FNV hashes mix their last bytes
+	// poorly.
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	bound := uint64(1<<63) / uint64(rate)
+	return x>>1 < bound
+}
+
+// ruleSamplerFile is the format of the files read by RuleSamplerFromFile.
+type ruleSamplerFile struct {
+	DefaultSampleRate *uint `json:"default_sample_rate"`
+	Rules             []struct {
+		Name       string            `json:"name"`
+		SpanName   string            `json:"span_name"`
+		SpanKind   string            `json:"span_kind"`
+		Attributes map[string]string `json:"attributes"`
+		SampleRate *uint             `json:"sample_rate"`
+	} `json:"rules"`
+}
+
+// parseSpanKind returns the span kind with the given name, such as "server."
+func parseSpanKind(name string) (apitrace.SpanKind, error) {
+	for _, kind := range []apitrace.SpanKind{
+		apitrace.SpanKindInternal,
+		apitrace.SpanKindServer,
+		apitrace.SpanKindClient,
+		apitrace.SpanKindProducer,
+		apitrace.SpanKindConsumer,
+	} {
+		if strings.EqualFold(name, kind.String()) {
+			return kind, nil
+		}
+	}
+	return apitrace.SpanKindUnspecified, fmt.Errorf("unknown span kind %q", name)
+}
+
+// RuleSamplerFromFile returns a RuleSampler configured by the JSON file at
+// path, such as this one, which drops health checks, keeps every checkout
+// span, and keeps one in a hundred of the other spans:
+//
+//	{
+//	  "default_sample_rate": 100,
+//	  "rules": [
+//	    {"name": "health", "span_name": "/healthz", "sample_rate": 0},
+//	    {"name": "checkout", "span_kind": "server", "attributes": {"http.route": "/checkout"}, "sample_rate": 1}
+//	  ]
+//	}
+//
+// Each rule must specify its sample rate. The span kinds are "internal,"
+// "server," "client," "producer," and "consumer." If the file does not
+// specify a default sample rate, it is 1.
+func RuleSamplerFromFile(path string) (*RuleSampler, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f ruleSamplerFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse sampling rules in %s: %w", path, err)
+	}
+	defaultRate := uint(1)
+	if f.DefaultSampleRate != nil {
+		defaultRate = *f.DefaultSampleRate
+	}
+	rules := make([]SamplingRule, len(f.Rules))
+	for i, r := range f.Rules {
+		if r.SampleRate == nil {
+			return nil, fmt.Errorf("sampling rule %d in %s has no sample rate", i+1, path)
+		}
+		rules[i] = SamplingRule{
+			Name:       r.Name,
+			SpanName:   r.SpanName,
+			Attributes: r.Attributes,
+			SampleRate: *r.SampleRate,
+		}
+		if len(r.SpanKind) != 0 {
+			if rules[i].SpanKind, err = parseSpanKind(r.SpanKind); err != nil {
+				return nil, fmt.Errorf("sampling rule %d in %s: %w", i+1, path, err)
+			}
+		}
+	}
+	return NewRuleSampler(defaultRate, rules...), nil
+}
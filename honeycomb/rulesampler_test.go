@@ -0,0 +1,239 @@
+package honeycomb
+
+import (
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func testRuleSampler() *RuleSampler {
+	return NewRuleSampler(100,
+		SamplingRule{SpanName: "/healthz", SampleRate: 0},
+		SamplingRule{
+			Name:       "checkout",
+			SpanKind:   apitrace.SpanKindServer,
+			Attributes: map[string]string{"http.route": "/checkout"},
+			SampleRate: 1,
+		},
+		SamplingRule{
+			Attributes: map[string]string{"http.status_code": "500"},
+			SampleRate: 2,
+		})
+}
+
+func TestRuleSamplerShouldSample(t *testing.T) {
+	s := testRuleSampler()
+	// The lowest trace ID is sampled at any positive rate.
+	var traceID apitrace.TraceID
+
+	tests := []struct {
+		description string
+		params      sdktrace.SamplingParameters
+		want        sdktrace.SamplingDecision
+		wantRate    int64
+		wantReason  string
+	}{
+		{
+			"drop rule",
+			sdktrace.SamplingParameters{TraceID: traceID, Name: "/healthz", Kind: apitrace.SpanKindServer},
+			sdktrace.Drop,
+			0,
+			"",
+		},
+		{
+			"kind and attribute rule",
+			sdktrace.SamplingParameters{
+				TraceID:    traceID,
+				Name:       "POST",
+				Kind:       apitrace.SpanKindServer,
+				Attributes: []label.KeyValue{label.String("http.route", "/checkout")},
+			},
+			sdktrace.RecordAndSample,
+			1,
+			"rules:checkout",
+		},
+		{
+			"kind mismatch",
+			sdktrace.SamplingParameters{
+				TraceID:    traceID,
+				Name:       "POST",
+				Kind:       apitrace.SpanKindClient,
+				Attributes: []label.KeyValue{label.String("http.route", "/checkout")},
+			},
+			sdktrace.RecordAndSample,
+			100,
+			"rules:default",
+		},
+		{
+			"attribute in string form",
+			sdktrace.SamplingParameters{
+				TraceID:    traceID,
+				Name:       "GET",
+				Attributes: []label.KeyValue{label.Int("http.status_code", 500)},
+			},
+			sdktrace.RecordAndSample,
+			2,
+			"rules:#3",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			result := s.ShouldSample(test.params)
+			assert.Equal(t, test.want, result.Decision)
+			if test.wantRate != 0 {
+				assert.Equal(t, []label.KeyValue{
+					label.Int64("SampleRate", test.wantRate),
+					label.String("meta.sample_reason", test.wantReason),
+				}, result.Attributes)
+			}
+		})
+	}
+}
+
+func TestSampledAtRate(t *testing.T) {
+	low := apitrace.TraceID{}
+	high := apitrace.TraceID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	mid := apitrace.TraceID{0x40}
+
+	assert.False(t, sampledAtRate(low, 0))
+	assert.True(t, sampledAtRate(low, 1))
+	assert.True(t, sampledAtRate(high, 1))
+	assert.False(t, sampledAtRate(high, 2))
+	assert.True(t, sampledAtRate(mid, 2))
+	assert.False(t, sampledAtRate(mid, 4))
+}
+
+// randomTraceIDs returns n trace IDs drawn from a fixed seed.
+func randomTraceIDs(n int) []apitrace.TraceID {
+	r := rand.New(rand.NewSource(1))
+	ids := make([]apitrace.TraceID, n)
+	for i := range ids {
+		r.Read(ids[i][:])
+	}
+	return ids
+}
+
+// headSampled returns those of ids that sdktrace.TraceIDRatioBased keeps
+// with probability 1/rate.
+func headSampled(ids []apitrace.TraceID, rate uint) []apitrace.TraceID {
+	sampler := sdktrace.TraceIDRatioBased(1 / float64(rate))
+	var kept []apitrace.TraceID
+	for _, id := range ids {
+		if sampler.ShouldSample(sdktrace.SamplingParameters{TraceID: id}).Decision == sdktrace.RecordAndSample {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
+// exporterSampledIDs returns a trace ID the exporter's sampling stage keeps
+// at rate and one it drops.
+func exporterSampledIDs(stage string, rate uint) (kept, dropped apitrace.TraceID) {
+	var foundKept, foundDropped bool
+	for _, id := range randomTraceIDs(1000) {
+		if exporterSampled(id, stage, rate) {
+			kept, foundKept = id, true
+		} else {
+			dropped, foundDropped = id, true
+		}
+		if foundKept && foundDropped {
+			break
+		}
+	}
+	return kept, dropped
+}
+
+func TestExporterSampledIndependently(t *testing.T) {
+	assert := assert.New(t)
+	survivors := headSampled(randomTraceIDs(20000), 10)
+	counts := make(map[string]int)
+	both := 0
+	for _, id := range survivors {
+		a, b := exporterSampled(id, "a", 4), exporterSampled(id, "b", 4)
+		if a {
+			counts["a"]++
+		}
+		if b {
+			counts["b"]++
+		}
+		if a && b {
+			both++
+		}
+	}
+	// Each stage keeps about a quarter of the traces the head sampler kept,
+	// and about a quarter of those the other kept.
+	n := float64(len(survivors))
+	assert.InDelta(0.25, float64(counts["a"])/n, 0.03)
+	assert.InDelta(0.25, float64(counts["b"])/n, 0.03)
+	assert.InDelta(0.25, float64(both)/float64(counts["a"]), 0.04)
+	assert.False(exporterSampled(survivors[0], "a", 0))
+}
+
+func TestRuleSamplerFromFile(t *testing.T) {
+	writeRules := func(t *testing.T, contents string) string {
+		f, err := ioutil.TempFile("", "rules*.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Remove(f.Name()) })
+		if _, err := f.WriteString(contents); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		return f.Name()
+	}
+
+	s, err := RuleSamplerFromFile(writeRules(t, `{
+		"default_sample_rate": 100,
+		"rules": [
+			{"span_name": "/healthz", "sample_rate": 0},
+			{"name": "checkout", "span_kind": "server", "attributes": {"http.route": "/checkout"}, "sample_rate": 1},
+			{"attributes": {"http.status_code": "500"}, "sample_rate": 2}
+		]
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, testRuleSampler(), s)
+
+	s, err = RuleSamplerFromFile(writeRules(t, `{"rules": []}`))
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), s.defaultRate)
+
+	_, err = RuleSamplerFromFile(writeRules(t, `{"rules": [{"span_name": "x"}]}`))
+	assert.Error(t, err)
+
+	_, err = RuleSamplerFromFile(writeRules(t, `{"rules": [{"span_kind": "sideways", "sample_rate": 1}]}`))
+	assert.Error(t, err)
+
+	_, err = RuleSamplerFromFile(writeRules(t, `{`))
+	assert.Error(t, err)
+}
+
+func TestHoneycombOutputWithRuleSampler(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb, WithSampleRate(3))
+	assert.Nil(err)
+	tr, err := setUpTestProvider(exporter,
+		sdktrace.WithConfig(sdktrace.Config{
+			DefaultSampler: NewRuleSampler(1, SamplingRule{SpanName: "sampled", SampleRate: 1}),
+		}))
+	assert.Nil(err)
+
+	_, span := tr.Start(context.TODO(), "sampled")
+	span.End()
+
+	assert.Len(mockHoneycomb.Events(), 1)
+	ev := mockHoneycomb.Events()[0]
+	assert.Equal(uint(1), ev.SampleRate)
+	assert.Equal(uint(1), ev.Data["meta.sample_rate"])
+}
@@ -0,0 +1,64 @@
+package honeycomb
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	tracesSamplerEnv    = "OTEL_TRACES_SAMPLER"
+	tracesSamplerArgEnv = "OTEL_TRACES_SAMPLER_ARG"
+)
+
+// SamplerFromEnv returns the sampler selected by the standard
+// OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG environment variables, so
+// that operators can tune sampling the same way they would for any other
+// OpenTelemetry SDK. The supported samplers are "always_on," "always_off,"
+// "traceidratio," "parentbased_always_on," "parentbased_always_off," and
+// "parentbased_traceidratio," whose argument is the fraction of traces to
+// sample, defaulting to 1.
+//
+// If OTEL_TRACES_SAMPLER is unset or empty, SamplerFromEnv returns fallback.
+func SamplerFromEnv(fallback sdktrace.Sampler) (sdktrace.Sampler, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv(tracesSamplerEnv)))
+	if len(name) == 0 {
+		return fallback, nil
+	}
+
+	ratio := func() (float64, error) {
+		arg := strings.TrimSpace(os.Getenv(tracesSamplerArgEnv))
+		if len(arg) == 0 {
+			return 1, nil
+		}
+		f, err := strconv.ParseFloat(arg, 64)
+		if err != nil || f < 0 || f > 1 {
+			return 0, fmt.Errorf("%s must be a number between 0 and 1, not %q", tracesSamplerArgEnv, arg)
+		}
+		return f, nil
+	}
+
+	parentBased := strings.HasPrefix(name, "parentbased_")
+	var root sdktrace.Sampler
+	switch strings.TrimPrefix(name, "parentbased_") {
+	case "always_on":
+		root = sdktrace.AlwaysSample()
+	case "always_off":
+		root = sdktrace.NeverSample()
+	case "traceidratio":
+		f, err := ratio()
+		if err != nil {
+			return nil, err
+		}
+		root = sdktrace.TraceIDRatioBased(f)
+	default:
+		return nil, fmt.Errorf("unsupported %s value %q", tracesSamplerEnv, name)
+	}
+	if parentBased {
+		return sdktrace.ParentBased(root), nil
+	}
+	return root, nil
+}
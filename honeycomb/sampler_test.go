@@ -0,0 +1,61 @@
+package honeycomb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func setEnv(t *testing.T, name, value string) {
+	old, present := os.LookupEnv(name)
+	if len(value) == 0 {
+		os.Unsetenv(name)
+	} else {
+		os.Setenv(name, value)
+	}
+	t.Cleanup(func() {
+		if present {
+			os.Setenv(name, old)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
+}
+
+func TestSamplerFromEnv(t *testing.T) {
+	fallback := sdktrace.AlwaysSample()
+	tests := []struct {
+		sampler     string
+		arg         string
+		want        sdktrace.Sampler
+		expectError bool
+	}{
+		{"", "", fallback, false},
+		{"always_on", "", sdktrace.AlwaysSample(), false},
+		{"always_off", "", sdktrace.NeverSample(), false},
+		{"traceidratio", "0.25", sdktrace.TraceIDRatioBased(0.25), false},
+		{"traceidratio", "", sdktrace.TraceIDRatioBased(1), false},
+		{"ParentBased_TraceIDRatio", "0.5", sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0.5)), false},
+		{"parentbased_always_off", "", sdktrace.ParentBased(sdktrace.NeverSample()), false},
+		{"traceidratio", "1.5", nil, true},
+		{"traceidratio", "half", nil, true},
+		{"jaeger_remote", "", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.sampler+"/"+test.arg, func(t *testing.T) {
+			assert := assert.New(t)
+			setEnv(t, tracesSamplerEnv, test.sampler)
+			setEnv(t, tracesSamplerArgEnv, test.arg)
+			got, err := SamplerFromEnv(fallback)
+			if test.expectError {
+				assert.Error(err)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(test.want.Description(), got.Description())
+		})
+	}
+}
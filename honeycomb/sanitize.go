@@ -0,0 +1,49 @@
+package honeycomb
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+
+	"go.opentelemetry.io/otel/label"
+)
+
+// sanitizedField marks events carrying attribute values altered to make them
+// safe to encode.
+const sanitizedField = "meta.sanitized"
+
+// sanitizeString replaces each invalid UTF-8 sequence and NUL byte in s with
+// U+FFFD, reporting whether it replaced any.
+func sanitizeString(s string) (string, bool) {
+	if utf8.ValidString(s) && strings.IndexByte(s, 0) < 0 {
+		return s, false
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == 0 || (r == utf8.RuneError && size == 1) {
+			b.WriteRune(utf8.RuneError)
+		} else {
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String(), true
+}
+
+// attributeValue returns the value of an attribute to add to an event. Since
+// a single malformed string can cause the API to reject the whole batch
+// containing it, strings are sanitized first, with the event marked as
+// sanitized if that changed them.
+func attributeValue(ev *libhoney.Event, v label.Value) interface{} {
+	if v.Type() != label.STRING {
+		return v.AsInterface()
+	}
+	s, changed := sanitizeString(v.AsString())
+	if changed {
+		ev.AddField(sanitizedField, true)
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestSanitizeString(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		changed bool
+	}{
+		{"plain", "plain", false},
+		{"héllo", "héllo", false},
+		{"a\x00b", "a�b", true},
+		{"a\xffb", "a�b", true},
+		{"\xe2\x82", "��", true},
+	}
+	for _, tt := range tests {
+		got, changed := sanitizeString(tt.in)
+		assert.Equal(t, tt.want, got, "%q", tt.in)
+		assert.Equal(t, tt.changed, changed, "%q", tt.in)
+	}
+}
+
+func TestHoneycombSanitizesAttributes(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb)
+	assert.Nil(err)
+
+	sds := []*trace.SpanSnapshot{
+		{Name: "bad", Attributes: []label.KeyValue{label.String("payload", "a\x00\xffb"), label.Int64("n", 1)}},
+		{Name: "good", Attributes: []label.KeyValue{label.String("payload", "ab")}},
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	events := mockHoneycomb.Events()
+	assert.Len(events, 2)
+	assert.Equal("a��b", events[0].Data["payload"])
+	assert.Equal(true, events[0].Data[sanitizedField])
+	assert.Equal(int64(1), events[0].Data["n"])
+	assert.NotContains(events[1].Data, sanitizedField)
+}
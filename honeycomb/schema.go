@@ -0,0 +1,178 @@
+package honeycomb
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// SchemaURLKey is the resource attribute from which the exporter reads the
+// URL of the semantic convention schema, such as
+// "https://opentelemetry.io/schemas/1.21.0," that a span's attributes
+// follow. The version of the SDK the exporter supports doesn't record schema
+// URLs itself, so set this attribute on the resource to enable
+// WithSchemaTransformation. The OTLP/JSON reader used by cmd/hcagent and
+// cmd/hcsend sets it from the schema URLs in the spans it reads.
+const SchemaURLKey = label.Key("otel.schema_url")
+
+// schemaVersion is a semantic convention schema version.
+type schemaVersion [3]int
+
+func (v schemaVersion) less(w schemaVersion) bool {
+	for i := range v {
+		if v[i] != w[i] {
+			return v[i] < w[i]
+		}
+	}
+	return false
+}
+
+func (v schemaVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// parseSchemaVersion parses a version such as "1.21.0" or the schema URL
+// ending with one.
+func parseSchemaVersion(s string) (schemaVersion, error) {
+	if i := strings.LastIndexByte(s, '/'); i >= 0 {
+		s = s[i+1:]
+	}
+	var v schemaVersion
+	parts := strings.Split(s, ".")
+	if len(parts) > len(v) {
+		return v, fmt.Errorf("invalid schema version %q", s)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid schema version %q", s)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// schemaChange is the set of attributes renamed by one version of the
+// semantic convention schema, mapping their old names to their new ones.
+type schemaChange struct {
+	version schemaVersion
+	renames map[string]string
+}
+
+// schemaChanges are the attribute renames of the semantic convention
+// schemas, oldest first, as published at https://opentelemetry.io/schemas/.
+var schemaChanges = []schemaChange{
+	{schemaVersion{1, 17, 0}, map[string]string{
+		"messaging.destination": "messaging.destination.name",
+	}},
+	{schemaVersion{1, 19, 0}, map[string]string{
+		"http.user_agent": "user_agent.original",
+	}},
+	{schemaVersion{1, 21, 0}, map[string]string{
+		"http.method":                  "http.request.method",
+		"http.status_code":             "http.response.status_code",
+		"http.url":                     "url.full",
+		"http.scheme":                  "url.scheme",
+		"http.client_ip":               "client.address",
+		"http.request_content_length":  "http.request.body.size",
+		"http.response_content_length": "http.response.body.size",
+		"net.host.name":                "server.address",
+		"net.host.port":                "server.port",
+		"net.protocol.version":         "network.protocol.version",
+		"net.sock.peer.addr":           "network.peer.address",
+		"net.sock.peer.port":           "network.peer.port",
+	}},
+	{schemaVersion{1, 25, 0}, map[string]string{
+		"db.statement": "db.query.text",
+	}},
+	{schemaVersion{1, 26, 0}, map[string]string{
+		"db.name":      "db.namespace",
+		"db.operation": "db.operation.name",
+		"db.sql.table": "db.collection.name",
+	}},
+}
+
+// renameField moves the value of one field of an event to another, unless
+// the event already has the other.
+func renameField(ev *libhoney.Event, from, to string) {
+	fields := ev.Fields()
+	v, ok := fields[from]
+	if !ok {
+		return
+	}
+	if _, ok := fields[to]; !ok {
+		ev.AddField(to, v)
+	}
+	delete(fields, from)
+}
+
+// transformSchema renames the fields of an event following one version of
+// the schema to those of another.
+func transformSchema(ev *libhoney.Event, from, to schemaVersion) {
+	if from.less(to) {
+		for _, c := range schemaChanges {
+			if from.less(c.version) && !to.less(c.version) {
+				for old, renamed := range c.renames {
+					renameField(ev, old, renamed)
+				}
+			}
+		}
+		return
+	}
+	for i := len(schemaChanges) - 1; i >= 0; i-- {
+		c := schemaChanges[i]
+		if to.less(c.version) && !from.less(c.version) {
+			for old, renamed := range c.renames {
+				renameField(ev, renamed, old)
+			}
+		}
+	}
+}
+
+// spanSchemaVersion returns the version of the schema a span follows,
+// according to its resource.
+func spanSchemaVersion(data *trace.SpanSnapshot) (schemaVersion, bool) {
+	if data.Resource == nil {
+		return schemaVersion{}, false
+	}
+	url, ok := data.Resource.LabelSet().Value(SchemaURLKey)
+	if !ok {
+		return schemaVersion{}, false
+	}
+	v, err := parseSchemaVersion(url.AsString())
+	return v, err == nil
+}
+
+// WithSchemaTransformation causes the exporter to rename the attributes of
+// spans following any other version of the OpenTelemetry semantic
+// convention schema, as given by the SchemaURLKey resource attribute, to
+// those of the target version, such as "1.21.0," applying the changes
+// published for each version in between. This keeps fields consistent
+// across services instrumented with different versions of the semantic
+// conventions, moving, for example, "http.method" to "http.request.method"
+// for spans following versions before 1.21.0 when the target is 1.21.0 or
+// later, and back again when the target is earlier. Spans without a schema
+// URL are sent unchanged.
+func WithSchemaTransformation(targetVersion string) ExporterOption {
+	return func(c *exporterConfig) error {
+		if len(targetVersion) == 0 {
+			return errors.New("target schema version must not be empty")
+		}
+		target, err := parseSchemaVersion(targetVersion)
+		if err != nil {
+			return err
+		}
+		c.processors = append(c.processors, transformProcessor(func(ev *libhoney.Event, data *trace.SpanSnapshot) {
+			if from, ok := spanSchemaVersion(data); ok && from != target {
+				transformSchema(ev, from, target)
+			}
+		}))
+		return nil
+	}
+}
@@ -0,0 +1,90 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestParseSchemaVersion(t *testing.T) {
+	v, err := parseSchemaVersion("https://opentelemetry.io/schemas/1.21.0")
+	assert.Nil(t, err)
+	assert.Equal(t, schemaVersion{1, 21, 0}, v)
+	v, err = parseSchemaVersion("1.9")
+	assert.Nil(t, err)
+	assert.Equal(t, schemaVersion{1, 9, 0}, v)
+	_, err = parseSchemaVersion("https://opentelemetry.io/schemas/latest")
+	assert.Error(t, err)
+}
+
+func TestHoneycombSchemaTransformation(t *testing.T) {
+	spanFollowing := func(schemaURL string, attrs ...label.KeyValue) *trace.SpanSnapshot {
+		s := &trace.SpanSnapshot{Name: "request", Attributes: attrs}
+		if len(schemaURL) != 0 {
+			s.Resource = resource.NewWithAttributes(SchemaURLKey.String(schemaURL))
+		}
+		return s
+	}
+	tests := []struct {
+		description string
+		target      string
+		span        *trace.SpanSnapshot
+		want        map[string]interface{}
+		absent      []string
+	}{
+		{
+			"upgrade",
+			"1.26.0",
+			spanFollowing("https://opentelemetry.io/schemas/1.20.0", label.String("http.method", "GET"), label.String("db.name", "orders")),
+			map[string]interface{}{"http.request.method": "GET", "db.namespace": "orders"},
+			[]string{"http.method", "db.name"},
+		},
+		{
+			"partial upgrade",
+			"1.21.0",
+			spanFollowing("https://opentelemetry.io/schemas/1.20.0", label.String("http.method", "GET"), label.String("db.name", "orders")),
+			map[string]interface{}{"http.request.method": "GET", "db.name": "orders"},
+			[]string{"http.method"},
+		},
+		{
+			"downgrade",
+			"1.20.0",
+			spanFollowing("https://opentelemetry.io/schemas/1.21.0", label.String("http.request.method", "GET")),
+			map[string]interface{}{"http.method": "GET"},
+			[]string{"http.request.method"},
+		},
+		{
+			"no schema",
+			"1.21.0",
+			spanFollowing("", label.String("http.method", "GET")),
+			map[string]interface{}{"http.method": "GET"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			mockHoneycomb := &transmission.MockSender{}
+			exporter, err := makeTestExporter(mockHoneycomb, WithSchemaTransformation(tt.target))
+			assert.Nil(t, err)
+			assert.Nil(t, exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{tt.span}))
+			assert.Nil(t, exporter.Shutdown(context.Background()))
+
+			fields := mockHoneycomb.Events()[0].Data
+			for name, want := range tt.want {
+				assert.Equal(t, want, fields[name], name)
+			}
+			for _, name := range tt.absent {
+				assert.NotContains(t, fields, name)
+			}
+		})
+	}
+
+	_, err := makeTestExporter(&transmission.MockSender{}, WithSchemaTransformation("next"))
+	assert.Error(t, err)
+}
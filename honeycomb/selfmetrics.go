@@ -0,0 +1,202 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/unit"
+)
+
+// Names of the instruments with which the exporter records metrics about its
+// own work.
+const (
+	selfSpansMetric         = "honeycomb.exporter.spans"
+	selfEventsMetric        = "honeycomb.exporter.events"
+	selfSendErrorsMetric    = "honeycomb.exporter.send_errors"
+	selfBatchDurationMetric = "honeycomb.exporter.batch_duration"
+	selfQueueDepthMetric    = "honeycomb.exporter.queue_depth"
+	selfDatasetEventsMetric = "honeycomb.exporter.dataset_events"
+	selfDatasetBytesMetric  = "honeycomb.exporter.dataset_bytes"
+)
+
+// Labels of the metrics the exporter records about its own work.
+const (
+	selfResultKey = label.Key("honeycomb.result")
+	selfReasonKey = label.Key("honeycomb.reason")
+	selfQueueKey  = label.Key("honeycomb.queue")
+)
+
+// WithSelfMetrics causes the exporter to record metrics describing its own
+// work with a meter from mp, so that it can be monitored by the same
+// pipeline as the application:
+//
+// - "honeycomb.exporter.spans," counting the spans given to ExportSpans,
+// labeled with a "honeycomb.result" of "exported" or "failed";
+//
+// - "honeycomb.exporter.events," counting the events handed to libhoney
+// for transmission;
+//
+// - "honeycomb.exporter.send_errors," counting the events Honeycomb didn't
+// accept, labeled with a "honeycomb.reason," such as "unauthorized,"
+// "rate_limited," or "queue_overflow";
+//
+// - "honeycomb.exporter.batch_duration," recording the milliseconds taken
+// by each HTTP request sending a batch of events, labeled with its HTTP
+// status, unless the exporter is configured with WithSender;
+//
+// - "honeycomb.exporter.queue_depth," observing the number of events in
+// libhoney's queue or being sent, labeled with a "honeycomb.queue" of
+// "transmission," and, with WithAsyncExport or WithTailSampling, the number
+// of spans yet to be converted into events, labeled "export"; and
+//
+// - with WithVolumeAccounting, "honeycomb.exporter.dataset_events" and
+// "honeycomb.exporter.dataset_bytes," observing the number and total size
+// of the events sent to each dataset, labeled with its name as
+// "honeycomb.dataset," as reported by DatasetVolumes.
+//
+// Send errors and the depth of libhoney's queue are learned from libhoney's
+// responses, which only the goroutine run by Start, or RunErrorLogger,
+// consumes.
+func WithSelfMetrics(mp metric.MeterProvider) ExporterOption {
+	return func(c *exporterConfig) error {
+		if mp == nil {
+			return errors.New("self-metrics meter provider must not be nil")
+		}
+		c.meterProvider = mp
+		return nil
+	}
+}
+
+// selfMetrics holds the instruments with which the exporter records metrics
+// about its own work.
+type selfMetrics struct {
+	meter         metric.Meter
+	spans         metric.Int64Counter
+	events        metric.Int64Counter
+	sendErrors    metric.Int64Counter
+	batchDuration metric.Float64ValueRecorder
+}
+
+func newSelfMetrics(mp metric.MeterProvider) (*selfMetrics, error) {
+	m := &selfMetrics{meter: mp.Meter(selfTracerName)}
+	var err error
+	if m.spans, err = m.meter.NewInt64Counter(selfSpansMetric,
+		metric.WithDescription("Spans given to the exporter"),
+		metric.WithUnit(unit.Dimensionless)); err != nil {
+		return nil, err
+	}
+	if m.events, err = m.meter.NewInt64Counter(selfEventsMetric,
+		metric.WithDescription("Events handed to libhoney for transmission"),
+		metric.WithUnit(unit.Dimensionless)); err != nil {
+		return nil, err
+	}
+	if m.sendErrors, err = m.meter.NewInt64Counter(selfSendErrorsMetric,
+		metric.WithDescription("Events Honeycomb didn't accept"),
+		metric.WithUnit(unit.Dimensionless)); err != nil {
+		return nil, err
+	}
+	if m.batchDuration, err = m.meter.NewFloat64ValueRecorder(selfBatchDurationMetric,
+		metric.WithDescription("Duration of the requests sending batches of events"),
+		metric.WithUnit(unit.Milliseconds)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// observeQueues starts observing the depths of the exporter's queues.
+func (m *selfMetrics) observeQueues(e *Exporter) error {
+	transmissionQueue := []label.KeyValue{selfQueueKey.String("transmission")}
+	exportQueue := []label.KeyValue{selfQueueKey.String("export")}
+	_, err := m.meter.NewInt64ValueObserver(selfQueueDepthMetric,
+		func(_ context.Context, result metric.Int64ObserverResult) {
+			result.Observe(e.TransmissionStats().Depth, transmissionQueue...)
+			if e.queue != nil || e.tail != nil {
+				result.Observe(int64(e.pendingSpans()), exportQueue...)
+			}
+		},
+		metric.WithDescription("Events or spans awaiting transmission"),
+		metric.WithUnit(unit.Dimensionless))
+	return err
+}
+
+// observeVolumes starts observing the events sent to each dataset.
+func (m *selfMetrics) observeVolumes(e *Exporter) error {
+	// Both instruments are observed by one callback, reading one snapshot.
+	var events, bytes metric.Int64SumObserver
+	batch := m.meter.NewBatchObserver(func(_ context.Context, result metric.BatchObserverResult) {
+		for dataset, v := range e.DatasetVolumes() {
+			result.Observe([]label.KeyValue{selfDatasetKey.String(dataset)},
+				events.Observation(int64(v.Events)),
+				bytes.Observation(int64(v.Bytes)))
+		}
+	})
+	var err error
+	if events, err = batch.NewInt64SumObserver(selfDatasetEventsMetric,
+		metric.WithDescription("Events sent to each dataset"),
+		metric.WithUnit(unit.Dimensionless)); err != nil {
+		return err
+	}
+	bytes, err = batch.NewInt64SumObserver(selfDatasetBytesMetric,
+		metric.WithDescription("Total size of the events sent to each dataset"),
+		metric.WithUnit(unit.Bytes))
+	return err
+}
+
+// recordExport records the outcome of a call to ExportSpans.
+func (m *selfMetrics) recordExport(ctx context.Context, result ExportResult) {
+	if result.Accepted != 0 {
+		m.spans.Add(ctx, int64(result.Accepted), selfResultKey.String("exported"))
+	}
+	if len(result.Failed) != 0 {
+		m.spans.Add(ctx, int64(len(result.Failed)), selfResultKey.String("failed"))
+	}
+}
+
+// recordSendError records an event Honeycomb didn't accept.
+func (m *selfMetrics) recordSendError(err error) {
+	m.sendErrors.Add(context.Background(), 1, selfReasonKey.String(errorReason(err)))
+}
+
+// errorReason returns the label value describing why Honeycomb didn't accept
+// an event.
+func errorReason(err error) string {
+	var notFound *DatasetNotFoundError
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrPayloadTooLarge):
+		return "payload_too_large"
+	case errors.Is(err, ErrQueueOverflow):
+		return "queue_overflow"
+	case errors.As(err, &notFound):
+		return "dataset_not_found"
+	default:
+		return "other"
+	}
+}
+
+// meteringTransport records the duration of each HTTP request that sends
+// events to Honeycomb.
+type meteringTransport struct {
+	base    http.RoundTripper
+	metrics *selfMetrics
+}
+
+func (t *meteringTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := float64(time.Since(start)) / float64(time.Millisecond)
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+	}
+	t.metrics.batchDuration.Record(req.Context(), elapsed, semconv.HTTPStatusCodeKey.Int(status))
+	return resp, err
+}
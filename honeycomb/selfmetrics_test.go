@@ -0,0 +1,81 @@
+package honeycomb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// sumMeasurements sums the measurements of the named integer instrument with
+// the given label, counting them as well.
+func sumMeasurements(measured []oteltest.Measured, name string, kv label.KeyValue) (sum float64, count int) {
+	for _, m := range measured {
+		if m.Name != name || m.Labels[kv.Key] != kv.Value {
+			continue
+		}
+		count++
+		sum += float64(m.Number.AsInt64())
+	}
+	return sum, count
+}
+
+func TestSelfMetrics(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[{"status": 202}, {"status": 400, "error": "bad event"}]`)
+	}))
+	defer server.Close()
+
+	meter, mp := oteltest.NewMeterProvider()
+	exporter, err := NewExporter(Config{APIKey: "overridden"},
+		TargetingDataset("test"),
+		WithAPIURL(server.URL),
+		WithSelfMetrics(mp),
+		WithVolumeAccounting(),
+		CallingOnError(func(error) {}))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Nil(exporter.Start(context.Background()))
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "first"}, {Name: "second"}}))
+	assert.Nil(exporter.Shutdown(context.Background()))
+	meter.RunAsyncInstruments()
+
+	measured := oteltest.AsStructs(meter.MeasurementBatches)
+	sum, _ := sumMeasurements(measured, selfSpansMetric, selfResultKey.String("exported"))
+	assert.Equal(2.0, sum)
+	sum, _ = sumMeasurements(measured, selfEventsMetric, label.KeyValue{})
+	assert.Equal(2.0, sum)
+	sum, _ = sumMeasurements(measured, selfSendErrorsMetric, selfReasonKey.String("other"))
+	assert.Equal(1.0, sum)
+	_, count := sumMeasurements(measured, selfBatchDurationMetric, semconv.HTTPStatusCodeKey.Int(http.StatusOK))
+	assert.Equal(1, count)
+	sum, count = sumMeasurements(measured, selfQueueDepthMetric, selfQueueKey.String("transmission"))
+	assert.Equal(1, count)
+	assert.Zero(sum)
+	_, count = sumMeasurements(measured, selfQueueDepthMetric, selfQueueKey.String("export"))
+	assert.Zero(count)
+	sum, _ = sumMeasurements(measured, selfDatasetEventsMetric, selfDatasetKey.String("test"))
+	assert.Equal(2.0, sum)
+	sum, _ = sumMeasurements(measured, selfDatasetBytesMetric, selfDatasetKey.String("test"))
+	assert.Equal(float64(exporter.DatasetVolumes()["test"].Bytes), sum)
+}
+
+func TestErrorReason(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("unauthorized", errorReason(&SpanError{Err: &TransmissionError{Reason: ErrUnauthorized}}))
+	assert.Equal("rate_limited", errorReason(&TransmissionError{Reason: ErrRateLimited}))
+	assert.Equal("queue_overflow", errorReason(&TransmissionError{Reason: ErrQueueOverflow}))
+	assert.Equal("dataset_not_found", errorReason(&DatasetNotFoundError{Dataset: "test"}))
+	assert.Equal("other", errorReason(io.EOF))
+	assert.Error(ValidateOptions(WithSelfMetrics(nil)))
+}
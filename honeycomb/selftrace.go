@@ -0,0 +1,104 @@
+package honeycomb
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/semconv"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// selfTracerName is the instrumentation name of the tracer with which the
+// exporter records spans describing its own work.
+const selfTracerName = "github.com/honeycombio/opentelemetry-exporter-go/honeycomb"
+
+// Attributes of the spans the exporter records describing its own work.
+const (
+	selfSpanCountKey       = label.Key("honeycomb.span_count")
+	selfFailedSpanCountKey = label.Key("honeycomb.failed_span_count")
+	selfDatasetKey         = label.Key("honeycomb.dataset")
+)
+
+// errSelfSpansDropped is reported when an exporter without a self-tracing
+// dataset receives the spans it recorded about itself.
+var errSelfSpansDropped = errors.New("dropping the exporter's own spans; export them with another exporter or give WithSelfTracing a dataset for them")
+
+// WithSelfTracing causes the exporter to record spans describing its own
+// work with tracers from tp, so that the latency of exports can be examined
+// in Honeycomb itself. The exporter records a "honeycomb.ExportSpans" span
+// for each call to ExportSpans, holding the numbers of spans given and of
+// those that failed, and, unless configured with WithSender, a
+// "honeycomb.transmit" span for each HTTP request that sends a batch of
+// events to Honeycomb.
+//
+// The spans are best exported by a secondary exporter, such as another
+// Exporter targeting a debugging dataset. If tp sends them back to this
+// exporter instead, it sends them to debugDataset, or drops them if
+// debugDataset is empty, rather than mixing them with the spans of the
+// application. Either way, the exporter doesn't record spans about exporting
+// or transmitting only its own spans, so tracing itself can't make it export
+// spans endlessly.
+func WithSelfTracing(tp apitrace.TracerProvider, debugDataset string) ExporterOption {
+	return func(c *exporterConfig) error {
+		if tp == nil {
+			return errors.New("self-tracing tracer provider must not be nil")
+		}
+		c.selfTracer = tp.Tracer(selfTracerName)
+		c.selfTracingDataset = debugDataset
+		return nil
+	}
+}
+
+// isSelfSpan reports whether a span was recorded by the exporter about its
+// own work.
+func isSelfSpan(data *trace.SpanSnapshot) bool {
+	return data.InstrumentationLibrary.Name == selfTracerName
+}
+
+// onlySelfSpans reports whether a batch holds only spans the exporter
+// recorded about its own work.
+func onlySelfSpans(sds []*trace.SpanSnapshot) bool {
+	for _, data := range sds {
+		if !isSelfSpan(data) {
+			return false
+		}
+	}
+	return true
+}
+
+// tracingTransport records a span for each HTTP request that sends events to
+// any dataset other than skipDataset.
+type tracingTransport struct {
+	base        http.RoundTripper
+	tracer      apitrace.Tracer
+	skipDataset string
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	dataset := path.Base(req.URL.Path)
+	if len(t.skipDataset) != 0 && dataset == t.skipDataset {
+		return t.base.RoundTrip(req)
+	}
+	ctx, span := t.tracer.Start(req.Context(), "honeycomb.transmit",
+		apitrace.WithSpanKind(apitrace.SpanKindClient),
+		apitrace.WithAttributes(
+			semconv.HTTPMethodKey.String(req.Method),
+			semconv.HTTPURLKey.String(req.URL.String()),
+			selfDatasetKey.String(dataset)))
+	defer span.End()
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,135 @@
+package honeycomb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// forwardingExporter passes spans to an exporter created after it.
+type forwardingExporter struct {
+	exporter *Exporter
+}
+
+func (f *forwardingExporter) ExportSpans(ctx context.Context, sds []*trace.SpanSnapshot) error {
+	return f.exporter.ExportSpans(ctx, sds)
+}
+
+func (f *forwardingExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func newSelfTracingProvider(exporter trace.SpanExporter) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+		sdktrace.WithSyncer(exporter))
+}
+
+func TestSelfTracingWithSecondaryExporter(t *testing.T) {
+	assert := assert.New(t)
+
+	debugHoneycomb := &transmission.MockSender{}
+	secondary, err := makeTestExporter(debugHoneycomb)
+	assert.Nil(err)
+
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := makeTestExporter(mockHoneycomb, WithSelfTracing(newSelfTracingProvider(secondary), ""))
+	assert.Nil(err)
+
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "app"}, {Name: "app"}}))
+
+	assert.Len(mockHoneycomb.Events(), 2)
+	events := debugHoneycomb.Events()
+	if assert.Len(events, 1) {
+		assert.Equal("honeycomb.ExportSpans", events[0].Data["name"])
+		assert.Equal(int64(2), events[0].Data["honeycomb.span_count"])
+		assert.Equal(int64(0), events[0].Data["honeycomb.failed_span_count"])
+	}
+}
+
+func TestSelfTracingWithSameExporter(t *testing.T) {
+	assert := assert.New(t)
+
+	mockHoneycomb := &transmission.MockSender{}
+	forwarder := &forwardingExporter{}
+	exporter, err := makeTestExporter(mockHoneycomb, WithSelfTracing(newSelfTracingProvider(forwarder), "debug"))
+	assert.Nil(err)
+	forwarder.exporter = exporter
+
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "app"}}))
+
+	// Exporting the exporter's own span records no further spans.
+	events := mockHoneycomb.Events()
+	if assert.Len(events, 2) {
+		assert.Equal("app", events[0].Data["name"])
+		assert.Equal("test", events[0].Dataset)
+		assert.Equal("honeycomb.ExportSpans", events[1].Data["name"])
+		assert.Equal("debug", events[1].Dataset)
+	}
+}
+
+func TestSelfTracingDropsOwnSpansWithoutDataset(t *testing.T) {
+	assert := assert.New(t)
+
+	mockHoneycomb := &transmission.MockSender{}
+	forwarder := &forwardingExporter{}
+	var errs []error
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithSelfTracing(newSelfTracingProvider(forwarder), ""),
+		CallingOnError(func(err error) {
+			errs = append(errs, err)
+		}))
+	assert.Nil(err)
+	forwarder.exporter = exporter
+
+	for i := 0; i < 2; i++ {
+		assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "app"}}))
+	}
+
+	assert.Len(mockHoneycomb.Events(), 2)
+	assert.Equal([]error{errSelfSpansDropped}, errs)
+
+	_, err = makeTestExporter(mockHoneycomb, WithSelfTracing(nil, ""))
+	assert.Error(err)
+}
+
+func TestSelfTracingTransport(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	mockHoneycomb := &transmission.MockSender{}
+	recorder, err := makeTestExporter(mockHoneycomb)
+	assert.Nil(err)
+	tp := newSelfTracingProvider(recorder)
+
+	transport := &tracingTransport{
+		base:        http.DefaultTransport,
+		tracer:      tp.Tracer(selfTracerName),
+		skipDataset: "debug",
+	}
+	client := &http.Client{Transport: transport}
+	for _, dataset := range []string{"app", "debug"} {
+		resp, err := client.Post(server.URL+"/1/batch/"+dataset, "application/json", nil)
+		if assert.Nil(err) {
+			resp.Body.Close()
+		}
+	}
+
+	events := mockHoneycomb.Events()
+	if assert.Len(events, 1) {
+		assert.Equal("honeycomb.transmit", events[0].Data["name"])
+		assert.Equal("app", events[0].Data["honeycomb.dataset"])
+		assert.Equal(int64(http.StatusAccepted), events[0].Data["http.status_code"])
+	}
+}
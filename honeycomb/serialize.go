@@ -0,0 +1,75 @@
+package honeycomb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+)
+
+// ValueSerializer converts a field value into one that Honeycomb can store,
+// such as a string or number.
+type ValueSerializer func(v interface{}) interface{}
+
+// WithValueSerializer registers a function for converting field values of
+// the given type before the exporter sends them, taking precedence over the
+// exporter's default conversions. By default, the exporter sends errors as
+// their messages and values implementing fmt.Stringer as their strings,
+// except those implementing json.Marshaler, which it encodes as they specify.
+// Values of other types are encoded as JSON, which can yield arrays or
+// objects Honeycomb won't store.
+//
+// This function replaces any serializer registered previously for the same
+// type.
+func WithValueSerializer(t reflect.Type, f ValueSerializer) ExporterOption {
+	return func(c *exporterConfig) error {
+		if t == nil {
+			return errors.New("value serializer type must not be nil")
+		}
+		if f == nil {
+			return errors.New("value serializer must not be nil")
+		}
+		if c.valueSerializers == nil {
+			c.valueSerializers = make(map[reflect.Type]ValueSerializer)
+		}
+		c.valueSerializers[t] = f
+		return nil
+	}
+}
+
+// serializeValue converts a field value using the serializer registered for
+// its type, if any, or the default conversions otherwise, reporting whether
+// it changed the value.
+func serializeValue(serializers map[reflect.Type]ValueSerializer, v interface{}) (interface{}, bool) {
+	switch v.(type) {
+	case nil, bool, string,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return v, false
+	}
+	if f, ok := serializers[reflect.TypeOf(v)]; ok {
+		return f(v), true
+	}
+	switch x := v.(type) {
+	case error:
+		return x.Error(), true
+	case json.Marshaler:
+		return v, false
+	case fmt.Stringer:
+		return x.String(), true
+	}
+	return v, false
+}
+
+// serializeFields converts the values of an event's fields that Honeycomb
+// can't store directly.
+func serializeFields(ev *libhoney.Event, serializers map[reflect.Type]ValueSerializer) {
+	for name, v := range ev.Fields() {
+		if converted, ok := serializeValue(serializers, v); ok {
+			ev.AddField(name, converted)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+type point struct{ X, Y int }
+
+func TestSerializeValue(t *testing.T) {
+	now := time.Now()
+	serializers := map[reflect.Type]ValueSerializer{
+		reflect.TypeOf(point{}): func(v interface{}) interface{} {
+			p := v.(point)
+			return fmt.Sprintf("%d,%d", p.X, p.Y)
+		},
+	}
+	tests := []struct {
+		description string
+		value       interface{}
+		want        interface{}
+		changed     bool
+	}{
+		{"string", "s", "s", false},
+		{"int", 3, 3, false},
+		{"error", errors.New("failed"), "failed", true},
+		{"stringer", net.IPv4(10, 0, 0, 1), "10.0.0.1", true},
+		{"json marshaler", now, now, false},
+		{"registered", point{1, 2}, "1,2", true},
+		{"other", []int{1}, []int{1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			got, changed := serializeValue(serializers, tt.value)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.changed, changed)
+		})
+	}
+}
+
+func TestHoneycombValueSerializer(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithField("origin", point{3, 4}),
+		WithField("last_error", errors.New("timeout")),
+		WithValueSerializer(reflect.TypeOf(point{}), func(v interface{}) interface{} {
+			return v.(point).X + v.(point).Y
+		}))
+	assert.Nil(err)
+
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "a"}}))
+	assert.Nil(exporter.Shutdown(context.Background()))
+	fields := mockHoneycomb.Events()[0].Data
+	assert.Equal(7, fields["origin"])
+	assert.Equal("timeout", fields["last_error"])
+
+	_, err = makeTestExporter(&transmission.MockSender{}, WithValueSerializer(nil, func(v interface{}) interface{} { return v }))
+	assert.Error(err)
+	_, err = makeTestExporter(&transmission.MockSender{}, WithValueSerializer(reflect.TypeOf(point{}), nil))
+	assert.Error(err)
+}
@@ -0,0 +1,92 @@
+package honeycomb
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// ServiceNamePrecedence says which name the exporter sends as a span's
+// service when its "service.name" resource attribute differs from the name
+// given to WithServiceName.
+type ServiceNamePrecedence int
+
+const (
+	// PreferServiceNameOption sends the name given to WithServiceName.
+	PreferServiceNameOption ServiceNamePrecedence = iota + 1
+	// PreferResourceServiceName sends the "service.name" resource attribute.
+	PreferResourceServiceName
+	// RejectServiceNameConflicts fails to export the span, reporting a
+	// *ServiceNameConflictError.
+	RejectServiceNameConflicts
+)
+
+// ServiceNameConflictError reports a span whose "service.name" resource
+// attribute differs from the name given to WithServiceName.
+type ServiceNameConflictError struct {
+	// Option is the name given to WithServiceName.
+	Option string
+	// Resource is the span's "service.name" resource attribute.
+	Resource string
+}
+
+func (e *ServiceNameConflictError) Error() string {
+	return fmt.Sprintf("span's resource service name %q conflicts with exporter service name %q", e.Resource, e.Option)
+}
+
+// WithServiceNamePrecedence causes the exporter to send each span's service
+// name in exactly one field, "service_name," choosing between its
+// "service.name" resource attribute and the name given to WithServiceName,
+// when both are present and differ, according to the given precedence. The
+// "service.name" resource attribute isn't sent separately. When only one
+// name is present, the exporter sends that one. The chosen name also selects
+// the fields added by WithServiceFields.
+//
+// Without this option, the exporter sends the name given to WithServiceName,
+// if any, as "service_name," and the resource attribute, if any, as
+// "service.name," even if they differ.
+func WithServiceNamePrecedence(p ServiceNamePrecedence) ExporterOption {
+	return func(c *exporterConfig) error {
+		switch p {
+		case PreferServiceNameOption, PreferResourceServiceName, RejectServiceNameConflicts:
+		default:
+			return errors.New("unknown service name precedence")
+		}
+		c.serviceNamePrecedence = p
+		return nil
+	}
+}
+
+// resolveServiceName returns the service name to send for a span, according
+// to the exporter's service name precedence.
+func (e *Exporter) resolveServiceName(data *trace.SpanSnapshot) (string, error) {
+	resourceName := spanServiceName(data, "")
+	switch {
+	case len(resourceName) == 0:
+		return e.serviceName, nil
+	case len(e.serviceName) == 0, resourceName == e.serviceName:
+		return resourceName, nil
+	}
+	switch e.serviceNamePrecedence {
+	case PreferServiceNameOption:
+		return e.serviceName, nil
+	case PreferResourceServiceName:
+		return resourceName, nil
+	default:
+		return "", &ServiceNameConflictError{Option: e.serviceName, Resource: resourceName}
+	}
+}
+
+// withoutServiceName returns resource attributes other than "service.name."
+func withoutServiceName(attrs []label.KeyValue) []label.KeyValue {
+	filtered := make([]label.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		if kv.Key != semconv.ServiceNameKey {
+			filtered = append(filtered, kv)
+		}
+	}
+	return filtered
+}
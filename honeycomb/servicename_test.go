@@ -0,0 +1,84 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestServiceNamePrecedence(t *testing.T) {
+	withService := func(name string) *trace.SpanSnapshot {
+		return &trace.SpanSnapshot{
+			Name:     "span",
+			Resource: resource.NewWithAttributes(label.String("service.name", name), label.String("host.name", "xanadu")),
+		}
+	}
+	tests := []struct {
+		description string
+		precedence  ServiceNamePrecedence
+		span        *trace.SpanSnapshot
+		want        interface{}
+		wantErr     bool
+	}{
+		{"option wins", PreferServiceNameOption, withService("from-resource"), "opentelemetry-test", false},
+		{"resource wins", PreferResourceServiceName, withService("from-resource"), "from-resource", false},
+		{"conflict", RejectServiceNameConflicts, withService("from-resource"), nil, true},
+		{"agreement", RejectServiceNameConflicts, withService("opentelemetry-test"), "opentelemetry-test", false},
+		{"no resource name", RejectServiceNameConflicts, &trace.SpanSnapshot{Name: "span"}, "opentelemetry-test", false},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert := assert.New(t)
+			mockHoneycomb := &transmission.MockSender{}
+			var results []ExportResult
+			exporter, err := makeTestExporter(mockHoneycomb,
+				WithServiceNamePrecedence(test.precedence),
+				WithServiceFields(map[string]map[string]interface{}{
+					"from-resource": {"team": "resource-team"},
+				}),
+				CallingOnError(func(error) {}),
+				CallingOnExportResult(func(r ExportResult) {
+					results = append(results, r)
+				}))
+			assert.Nil(err)
+
+			err = exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{test.span})
+
+			if test.wantErr {
+				var conflict *ServiceNameConflictError
+				assert.True(errors.As(err, &conflict), "ExportSpans reports why spans failed")
+				assert.Empty(mockHoneycomb.Events())
+				if assert.Len(results, 1) && assert.Len(results[0].Errors, 1) {
+					var conflict *ServiceNameConflictError
+					assert.True(errors.As(results[0].Errors[0], &conflict))
+					assert.Equal(&ServiceNameConflictError{Option: "opentelemetry-test", Resource: "from-resource"}, conflict)
+				}
+				return
+			}
+			assert.Nil(err)
+			if assert.Len(mockHoneycomb.Events(), 1) {
+				fields := mockHoneycomb.Events()[0].Data
+				assert.Equal(test.want, fields["service_name"])
+				assert.NotContains(fields, "service.name")
+				if test.span.Resource != nil {
+					assert.Equal("xanadu", fields["host.name"])
+				}
+				if test.want == "from-resource" {
+					assert.Equal("resource-team", fields["team"])
+				} else {
+					assert.NotContains(fields, "team")
+				}
+			}
+		})
+	}
+
+	_, err := makeTestExporter(&transmission.MockSender{}, WithServiceNamePrecedence(0))
+	assert.Error(t, err)
+}
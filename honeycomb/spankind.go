@@ -0,0 +1,96 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// WithSpanKindFields adds a set of fields to the events for spans of the given
+// kind, such as direction=ingress for server spans. Same-named span
+// attributes take precedence over these fields.
+//
+// This function replaces any field registered previously with the same name
+// for the same kind.
+func WithSpanKindFields(kind apitrace.SpanKind, m map[string]interface{}) ExporterOption {
+	return func(c *exporterConfig) error {
+		if c.spanKindFields == nil {
+			c.spanKindFields = make(map[apitrace.SpanKind]map[string]interface{})
+		}
+		fields := c.spanKindFields[kind]
+		if fields == nil {
+			fields = make(map[string]interface{}, len(m))
+			c.spanKindFields[kind] = fields
+		}
+		for name, value := range m {
+			if err := validateField(name); err != nil {
+				return err
+			}
+			fields[name] = value
+		}
+		return nil
+	}
+}
+
+// WithSpanKindTransform adds a stage to the exporter's event pipeline that
+// calls a function that may modify the event for each span of the given kind.
+// See WithEventProcessors for how the stages are ordered.
+func WithSpanKindTransform(kind apitrace.SpanKind, f func(ev *libhoney.Event, s *trace.SpanSnapshot)) ExporterOption {
+	return func(c *exporterConfig) error {
+		if f == nil {
+			return errors.New("span kind transform must not be nil")
+		}
+		c.processors = append(c.processors, func(_ context.Context, ev *libhoney.Event, s *trace.SpanSnapshot) error {
+			if s.SpanKind == kind && !isAnnotationEvent(ev) {
+				f(ev, s)
+			}
+			return nil
+		})
+		return nil
+	}
+}
+
+// WithExportedSpanKinds causes the exporter to send only the spans of the
+// given kinds, along with their span events and links, dropping the others.
+// For example, a service with deep trees of internal spans can send only
+// the spans for the requests and messages it handles:
+//
+//	WithExportedSpanKinds(trace.SpanKindServer, trace.SpanKindConsumer)
+//
+// Spans whose kind is unspecified are treated as internal spans.
+func WithExportedSpanKinds(kinds ...apitrace.SpanKind) ExporterOption {
+	return func(c *exporterConfig) error {
+		if len(kinds) == 0 {
+			return errors.New("exported span kinds must not be empty")
+		}
+		exported := make(map[apitrace.SpanKind]bool, len(kinds))
+		for _, kind := range kinds {
+			switch kind {
+			case apitrace.SpanKindInternal, apitrace.SpanKindServer, apitrace.SpanKindClient,
+				apitrace.SpanKindProducer, apitrace.SpanKindConsumer:
+			default:
+				return fmt.Errorf("unknown span kind %v", kind)
+			}
+			exported[kind] = true
+		}
+		c.exportedSpanKinds = exported
+		return nil
+	}
+}
+
+// kindExported reports whether the exporter sends spans of a span's kind.
+func (e *Exporter) kindExported(data *trace.SpanSnapshot) bool {
+	if e.exportedSpanKinds == nil {
+		return true
+	}
+	kind := data.SpanKind
+	if kind == apitrace.SpanKindUnspecified {
+		kind = apitrace.SpanKindInternal
+	}
+	return e.exportedSpanKinds[kind]
+}
@@ -0,0 +1,84 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestHoneycombOutputWithSpanKindPresets(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	tr, err := setUpTestExporter(mockHoneycomb,
+		WithSpanKindFields(apitrace.SpanKindServer, map[string]interface{}{
+			"direction": "ingress",
+			"tier":      "edge",
+		}),
+		WithSpanKindFields(apitrace.SpanKindClient, map[string]interface{}{
+			"direction": "egress",
+		}),
+		WithSpanKindTransform(apitrace.SpanKindClient, func(ev *libhoney.Event, s *trace.SpanSnapshot) {
+			ev.AddField("client.name", s.Name)
+		}))
+	assert.Nil(err)
+
+	ctx, server := tr.Start(context.TODO(), "server", apitrace.WithSpanKind(apitrace.SpanKindServer))
+	server.SetAttributes(label.String("tier", "api"))
+	_, client := tr.Start(ctx, "client", apitrace.WithSpanKind(apitrace.SpanKindClient))
+	client.End()
+	_, internal := tr.Start(ctx, "internal")
+	internal.End()
+	server.End()
+
+	events := mockHoneycomb.Events()
+	assert.Len(events, 3)
+
+	clientFields := events[0].Data
+	assert.Equal("egress", clientFields["direction"])
+	assert.Equal("client", clientFields["client.name"])
+
+	internalFields := events[1].Data
+	assert.NotContains(internalFields, "direction")
+	assert.NotContains(internalFields, "client.name")
+
+	serverFields := events[2].Data
+	assert.Equal("ingress", serverFields["direction"])
+	assert.Equal("api", serverFields["tier"])
+	assert.NotContains(serverFields, "client.name")
+
+	_, err = makeTestExporter(mockHoneycomb, WithSpanKindTransform(apitrace.SpanKindServer, nil))
+	assert.Error(err)
+}
+
+func TestExportedSpanKinds(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb, WithExportedSpanKinds(apitrace.SpanKindServer, apitrace.SpanKindInternal))
+	assert.Nil(err)
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{
+		{Name: "server", SpanKind: apitrace.SpanKindServer},
+		{Name: "client", SpanKind: apitrace.SpanKindClient},
+		{Name: "unspecified"},
+		{Name: "consumer", SpanKind: apitrace.SpanKindConsumer},
+	}))
+
+	var names []interface{}
+	for _, ev := range mockHoneycomb.Events() {
+		names = append(names, ev.Data["name"])
+	}
+	assert.Equal([]interface{}{"server", "unspecified"}, names)
+
+	_, err = makeTestExporter(mockHoneycomb, WithExportedSpanKinds())
+	assert.Error(err)
+	_, err = makeTestExporter(mockHoneycomb, WithExportedSpanKinds(apitrace.SpanKindUnspecified))
+	assert.Error(err)
+}
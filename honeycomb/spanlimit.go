@@ -0,0 +1,117 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// Names of the event the exporter sends when it begins dropping the spans of
+// a trace with too many spans, and of its field holding the limit.
+const (
+	traceTruncatedName    = "trace truncated"
+	maxSpansPerTraceField = "meta.max_spans_per_trace"
+)
+
+// traceSpanCountCacheSize is the number of traces whose spans the exporter
+// counts at once.
+const traceSpanCountCacheSize = 10000
+
+// WithMaxSpansPerTrace causes the exporter to send at most n spans of each
+// trace, dropping the rest, protecting against instrumentation run amok,
+// such as that of a runaway recursion producing traces of hundreds of
+// thousands of spans. In place of the first span it drops from a trace, the
+// exporter sends an event named "trace truncated" in the same position in
+// the trace, whose "meta.max_spans_per_trace" field holds n.
+//
+// The exporter counts the spans of the traces it has seen most recently, of
+// which it remembers a fixed number, so a trace whose spans arrive over a
+// long period among many other traces may exceed the limit. It reports the
+// number of spans dropped from TruncatedTraceSpansDropped.
+func WithMaxSpansPerTrace(n int) ExporterOption {
+	return func(c *exporterConfig) error {
+		if n <= 0 {
+			return errors.New("maximum spans per trace must be positive")
+		}
+		c.maxSpansPerTrace = n
+		return nil
+	}
+}
+
+// traceSpanCounter counts the spans of recent traces.
+type traceSpanCounter struct {
+	// dropped comes first so that it's 64-bit aligned, as the atomic
+	// operations on it require on 32-bit platforms.
+	dropped uint64
+	limit   int
+
+	mu     sync.Mutex
+	counts map[apitrace.TraceID]int
+	seen   []apitrace.TraceID
+	next   int
+}
+
+func newTraceSpanCounter(limit int) *traceSpanCounter {
+	return &traceSpanCounter{
+		limit:  limit,
+		counts: make(map[apitrace.TraceID]int),
+		seen:   make([]apitrace.TraceID, 0, traceSpanCountCacheSize),
+	}
+}
+
+// admit counts a span of the trace with the given ID, reporting whether it
+// is within the limit and, if not, whether it is the first span past it.
+func (c *traceSpanCounter) admit(id apitrace.TraceID) (ok, first bool) {
+	c.mu.Lock()
+	n, known := c.counts[id]
+	if !known {
+		if len(c.seen) < traceSpanCountCacheSize {
+			c.seen = append(c.seen, id)
+		} else {
+			delete(c.counts, c.seen[c.next])
+			c.seen[c.next] = id
+			c.next = (c.next + 1) % traceSpanCountCacheSize
+		}
+	}
+	n++
+	c.counts[id] = n
+	c.mu.Unlock()
+
+	if n <= c.limit {
+		return true, false
+	}
+	atomic.AddUint64(&c.dropped, 1)
+	return false, n == c.limit+1
+}
+
+// sendTraceTruncated sends the event standing in for the first span dropped
+// from a trace for exceeding the limit on spans per trace.
+func (e *Exporter) sendTraceTruncated(ctx context.Context, data *trace.SpanSnapshot, sampleRate uint) error {
+	hs := honeycombSpan(data)
+	ev := e.client.NewEvent()
+	ev.Timestamp = data.StartTime
+	ev.AddField("name", traceTruncatedName)
+	ev.AddField("trace.trace_id", hs.TraceID)
+	ev.AddField("trace.span_id", hs.ID)
+	if len(hs.ParentID) != 0 {
+		ev.AddField("trace.parent_id", hs.ParentID)
+	}
+	if len(e.serviceName) != 0 {
+		ev.AddField(serviceNameField, e.serviceName)
+	}
+	ev.AddField(maxSpansPerTraceField, e.spanCounter.limit)
+	return e.send(ctx, ev, data, sampleRate)
+}
+
+// TruncatedTraceSpansDropped returns the number of spans the exporter has
+// dropped from traces exceeding the limit given to WithMaxSpansPerTrace.
+func (e *Exporter) TruncatedTraceSpansDropped() uint64 {
+	if e.spanCounter == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&e.spanCounter.dropped)
+}
@@ -0,0 +1,51 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestMaxSpansPerTrace(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := makeTestExporter(mockHoneycomb, WithMaxSpansPerTrace(2))
+	assert.Nil(err)
+
+	span := func(traceID byte, id byte) *trace.SpanSnapshot {
+		return &trace.SpanSnapshot{
+			SpanContext:  apitrace.SpanContext{TraceID: apitrace.TraceID{traceID}, SpanID: apitrace.SpanID{id}},
+			ParentSpanID: apitrace.SpanID{1},
+			Name:         "recurse",
+		}
+	}
+	var sds []*trace.SpanSnapshot
+	for id := byte(2); id < 7; id++ {
+		sds = append(sds, span(1, id))
+	}
+	sds = append(sds, span(2, 2))
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+
+	events := mockHoneycomb.Events()
+	if assert.Len(events, 4) {
+		assert.Equal("recurse", events[0].Data["name"])
+		assert.Equal("recurse", events[1].Data["name"])
+		truncated := events[2].Data
+		assert.Equal("trace truncated", truncated["name"])
+		assert.Equal("0400000000000000", truncated["trace.span_id"])
+		assert.Equal("0100000000000000", truncated["trace.parent_id"])
+		assert.Equal("opentelemetry-test", truncated["service_name"])
+		assert.Equal(2, truncated["meta.max_spans_per_trace"])
+		assert.Equal("recurse", events[3].Data["name"])
+		assert.Equal("02000000000000000000000000000000", events[3].Data["trace.trace_id"])
+	}
+	assert.Equal(uint64(3), exporter.TruncatedTraceSpansDropped())
+
+	_, err = makeTestExporter(mockHoneycomb, WithMaxSpansPerTrace(0))
+	assert.Error(err)
+}
@@ -0,0 +1,194 @@
+package honeycomb
+
+import (
+	"strings"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// sqlFields are the fields that may hold SQL text.
+var sqlFields = []string{"db.statement", "db.query.text", "db.query"}
+
+func obfuscateSQLFields(ev *libhoney.Event, _ *trace.SpanSnapshot) {
+	fields := ev.Fields()
+	system, _ := fields["db.system"].(string)
+	dialect := sqlDialectOf(system)
+	for _, name := range sqlFields {
+		if s, ok := fields[name].(string); ok {
+			ev.AddField(name, obfuscateSQL(s, dialect))
+		}
+	}
+}
+
+// sqlDialect selects the lexical rules by which obfuscateSQL finds literals.
+type sqlDialect int
+
+const (
+	// sqlGeneric suits statements of an unknown dialect: strings are quoted
+	// with single quotes, in which backslashes escape the next character.
+	sqlGeneric sqlDialect = iota
+	// sqlStandard follows the SQL standard, in which backslashes are
+	// ordinary characters in strings.
+	sqlStandard
+	// sqlMySQL also quotes strings with double quotes, as MySQL and MariaDB
+	// do unless the ANSI_QUOTES mode is enabled.
+	sqlMySQL
+	// sqlPostgres also recognizes PostgreSQL's dollar-quoted strings and its
+	// E'...' strings, in which backslashes escape the next character.
+	sqlPostgres
+)
+
+// sqlDialectOf returns the dialect of the statements sent to a database with
+// the given "db.system" attribute.
+func sqlDialectOf(system string) sqlDialect {
+	switch system {
+	case "":
+		return sqlGeneric
+	case "mysql", "mariadb":
+		return sqlMySQL
+	case "postgresql", "cockroachdb", "redshift":
+		return sqlPostgres
+	}
+	return sqlStandard
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c >= 0x80
+}
+
+// skipQuoted returns the index just past the string starting with the quote
+// at s[i], in which a doubled quote is an escaped quote and, if backslashes is
+// set, a backslash escapes the next character.
+func skipQuoted(s string, i int, backslashes bool) int {
+	quote := s[i]
+	i++
+	for i < len(s) {
+		if backslashes && s[i] == '\\' && i+1 < len(s) {
+			i += 2
+			continue
+		}
+		if s[i] == quote {
+			if i+1 < len(s) && s[i+1] == quote {
+				i += 2
+				continue
+			}
+			return i + 1
+		}
+		i++
+	}
+	return i
+}
+
+// dollarQuoteTag returns the tag, such as "$$" or "$body$", opening the
+// dollar-quoted string at s[i], or "" if there is none.
+func dollarQuoteTag(s string, i int) string {
+	j := i + 1
+	if j < len(s) && isDigit(s[j]) {
+		// A positional parameter such as $1.
+		return ""
+	}
+	for j < len(s) && s[j] != '$' && isIdentifierByte(s[j]) {
+		j++
+	}
+	if j < len(s) && s[j] == '$' {
+		return s[i : j+1]
+	}
+	return ""
+}
+
+// obfuscateSQL replaces the string and numeric literals in a SQL statement
+// of the given dialect with "?" and removes its comments, leaving keywords,
+// identifiers, and placeholders intact so that statements differing only in
+// their values can be grouped together.
+func obfuscateSQL(s string, dialect sqlDialect) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\'':
+			i = skipQuoted(s, i, dialect == sqlGeneric || dialect == sqlMySQL)
+			b.WriteByte('?')
+		case c == '"' && dialect == sqlMySQL:
+			i = skipQuoted(s, i, true)
+			b.WriteByte('?')
+		case (c == 'E' || c == 'e') && dialect == sqlPostgres && i+1 < len(s) && s[i+1] == '\'' &&
+			(i == 0 || !isIdentifierByte(s[i-1])):
+			// A string with C-style escapes.
+			i = skipQuoted(s, i+1, true)
+			b.WriteByte('?')
+		case c == '$' && dialect != sqlMySQL && len(dollarQuoteTag(s, i)) != 0:
+			tag := dollarQuoteTag(s, i)
+			end := strings.Index(s[i+len(tag):], tag)
+			b.WriteByte('?')
+			if end < 0 {
+				return b.String()
+			}
+			i += len(tag) + end + len(tag)
+		case c == '"' || c == '`':
+			// A quoted identifier.
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				b.WriteString(s[i:])
+				return b.String()
+			}
+			b.WriteString(s[i : i+end+2])
+			i += end + 2
+		case c == '-' && i+1 < len(s) && s[i+1] == '-':
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				return strings.TrimRight(b.String(), " \t")
+			}
+			i += end
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return strings.TrimRight(b.String(), " \t")
+			}
+			i += end + 4
+		case isDigit(c) || c == '.' && i+1 < len(s) && isDigit(s[i+1]):
+			// A numeric literal, including hexadecimal and exponent forms.
+			i++
+			for i < len(s) && (isIdentifierByte(s[i]) || s[i] == '.' ||
+				(s[i] == '+' || s[i] == '-') && (s[i-1] == 'e' || s[i-1] == 'E')) {
+				i++
+			}
+			b.WriteByte('?')
+		case isIdentifierByte(c):
+			// An identifier or keyword, which may contain digits.
+			start := i
+			for i < len(s) && isIdentifierByte(s[i]) {
+				i++
+			}
+			b.WriteString(s[start:i])
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// WithSQLObfuscation causes the exporter to replace the string and numeric
+// literals in the SQL statements it sends, in the "db.statement,"
+// "db.query.text," and "db.query" fields, with "?" and to remove their
+// comments, so that the statements are useful for grouping queries without
+// revealing the data they contain. The literals are found according to the
+// dialect named by the span's "db.system" attribute: MySQL and MariaDB quote
+// strings with double quotes as well as single quotes, PostgreSQL also has
+// dollar-quoted strings, and only MySQL, MariaDB, and PostgreSQL's E'...'
+// strings treat backslashes as escapes. Statements without the attribute
+// are treated as using single-quoted strings with backslash escapes, and
+// dollar-quoted strings.
+func WithSQLObfuscation() ExporterOption {
+	return func(c *exporterConfig) error {
+		c.processors = append(c.processors, transformProcessor(obfuscateSQLFields))
+		return nil
+	}
+}
@@ -0,0 +1,61 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+)
+
+func TestObfuscateSQL(t *testing.T) {
+	tests := []struct {
+		dialect sqlDialect
+		in      string
+		want    string
+	}{
+		{sqlGeneric, "SELECT * FROM users WHERE id = 42", "SELECT * FROM users WHERE id = ?"},
+		{sqlGeneric, "SELECT * FROM users WHERE name = 'O''Brien' AND age > 3.5e+1", "SELECT * FROM users WHERE name = ? AND age > ?"},
+		{sqlGeneric, `SELECT "col1", t2.x FROM t2 WHERE x IN (1, 2, 0x1F)`, `SELECT "col1", t2.x FROM t2 WHERE x IN (?, ?, ?)`},
+		{sqlGeneric, "UPDATE accounts SET balance = $1 WHERE id = ?", "UPDATE accounts SET balance = $1 WHERE id = ?"},
+		{sqlGeneric, "SELECT 1 /* customer 1234 */ FROM dual -- trailing", "SELECT ?  FROM dual"},
+		{sqlGeneric, "INSERT INTO `logs` VALUES ('it\\'s', .5)", "INSERT INTO `logs` VALUES (?, ?)"},
+		{sqlMySQL, `SELECT * FROM users WHERE name = "jo \"the\" ""rock""" AND id = 1`, "SELECT * FROM users WHERE name = ? AND id = ?"},
+		{sqlMySQL, "INSERT INTO `logs` VALUES ('it\\'s', .5)", "INSERT INTO `logs` VALUES (?, ?)"},
+		{sqlPostgres, "SELECT $$it's a secret$$, $body$nested $$ quotes$body$ FROM t WHERE a = $1", "SELECT ?, ? FROM t WHERE a = $1"},
+		{sqlPostgres, `SELECT * FROM t WHERE path = 'C:\' AND name = 'secret'`, "SELECT * FROM t WHERE path = ? AND name = ?"},
+		{sqlPostgres, `SELECT * FROM t WHERE a = E'it\'s' AND "e" = 'x'`, `SELECT * FROM t WHERE a = ? AND "e" = ?`},
+		{sqlStandard, `SELECT * FROM t WHERE path = 'C:\' AND name = 'secret'`, "SELECT * FROM t WHERE path = ? AND name = ?"},
+		{sqlStandard, `SELECT "name" FROM t WHERE id = 7`, `SELECT "name" FROM t WHERE id = ?`},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, obfuscateSQL(test.in, test.dialect), test.in)
+	}
+	assert.Equal(t, sqlMySQL, sqlDialectOf("mariadb"))
+	assert.Equal(t, sqlPostgres, sqlDialectOf("postgresql"))
+	assert.Equal(t, sqlStandard, sqlDialectOf("mssql"))
+	assert.Equal(t, sqlGeneric, sqlDialectOf(""))
+}
+
+func TestHoneycombOutputWithSQLObfuscation(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	tr, err := setUpTestExporter(mockHoneycomb, WithDatabaseFieldNormalization(), WithSQLObfuscation())
+	assert.Nil(err)
+
+	_, span := tr.Start(context.TODO(), "myTestSpan")
+	span.SetAttributes(
+		label.String("db.system", "postgresql"),
+		label.String("db.statement", "SELECT * FROM orders WHERE email = 'jo@example.com'"),
+	)
+	span.End()
+
+	assert.Len(mockHoneycomb.Events(), 1)
+	fields := mockHoneycomb.Events()[0].Data
+	assert.Equal("SELECT * FROM orders WHERE email = ?", fields["db.statement"])
+	assert.Equal("SELECT * FROM orders WHERE email = ?", fields["db.query"])
+	assert.Equal("postgresql", fields["db.system"])
+}
@@ -0,0 +1,90 @@
+package honeycomb
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of cumulative counts describing the exporter's work
+// since it was created, such as for reporting by a debugging endpoint.
+type Stats struct {
+	// SpansConverted counts the spans converted into events for sending.
+	SpansConverted uint64
+	// SpanEventsEmitted counts the events produced for span events.
+	SpanEventsEmitted uint64
+	// LinksEmitted counts the events produced for links.
+	LinksEmitted uint64
+	// SendsAttempted counts the events the exporter tried to hand to
+	// libhoney for transmission.
+	SendsAttempted uint64
+	// SendsFailed counts the events libhoney refused to queue, or that
+	// Honeycomb didn't accept.
+	SendsFailed uint64
+	// LastError is the reason for the most recent failure counted by
+	// SendsFailed, or nil if there has been none, and LastErrorTime is when
+	// it happened.
+	LastError     error
+	LastErrorTime time.Time
+	// LastSuccessTime is when Honeycomb most recently accepted an event, or
+	// the zero time if it hasn't yet.
+	LastSuccessTime time.Time
+	// DatasetVolumes holds the events sent to each dataset, as reported by
+	// DatasetVolumes, when the exporter is configured with
+	// WithVolumeAccounting. It is nil otherwise.
+	DatasetVolumes map[string]DatasetVolume
+}
+
+// exportStats accumulates the counts reported by Stats.
+type exportStats struct {
+	spansConverted    uint64
+	spanEventsEmitted uint64
+	linksEmitted      uint64
+	sendsAttempted    uint64
+	sendsFailed       uint64
+
+	mu            sync.Mutex
+	lastError     error
+	lastErrorTime time.Time
+	lastSuccess   time.Time
+}
+
+// recordFailure counts an event that failed to be sent.
+func (s *exportStats) recordFailure(err error) {
+	atomic.AddUint64(&s.sendsFailed, 1)
+	now := time.Now()
+	s.mu.Lock()
+	s.lastError = err
+	s.lastErrorTime = now
+	s.mu.Unlock()
+}
+
+// recordSuccess notes that Honeycomb accepted an event.
+func (s *exportStats) recordSuccess() {
+	now := time.Now()
+	s.mu.Lock()
+	s.lastSuccess = now
+	s.mu.Unlock()
+}
+
+// Stats returns a snapshot of the exporter's cumulative counts. Failures
+// reported by Honeycomb, and its acceptance of events, are learned from
+// libhoney's responses, which only the goroutine run by Start, or
+// RunErrorLogger, consumes.
+func (e *Exporter) Stats() Stats {
+	s := &e.stats
+	stats := Stats{
+		SpansConverted:    atomic.LoadUint64(&s.spansConverted),
+		SpanEventsEmitted: atomic.LoadUint64(&s.spanEventsEmitted),
+		LinksEmitted:      atomic.LoadUint64(&s.linksEmitted),
+		SendsAttempted:    atomic.LoadUint64(&s.sendsAttempted),
+		SendsFailed:       atomic.LoadUint64(&s.sendsFailed),
+	}
+	s.mu.Lock()
+	stats.LastError = s.lastError
+	stats.LastErrorTime = s.lastErrorTime
+	stats.LastSuccessTime = s.lastSuccess
+	s.mu.Unlock()
+	stats.DatasetVolumes = e.DatasetVolumes()
+	return stats
+}
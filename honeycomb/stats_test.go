@@ -0,0 +1,55 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestStats(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{BlockOnResponses: true}
+	exporter, err := makeTestExporter(mockHoneycomb, CallingOnError(func(error) {}))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Equal(Stats{}, exporter.Stats())
+	assert.Nil(exporter.Start(context.Background()))
+
+	start := time.Now()
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{
+		Name:          "span",
+		MessageEvents: []trace.Event{{Name: "first"}, {Name: "second"}},
+		Links:         []apitrace.Link{{}},
+	}}))
+	events := mockHoneycomb.Events()
+	if !assert.Len(events, 4) {
+		return
+	}
+	mockHoneycomb.SendResponse(transmission.Response{StatusCode: http.StatusAccepted, Metadata: events[0].Metadata})
+	mockHoneycomb.SendResponse(transmission.Response{StatusCode: http.StatusAccepted, Metadata: events[1].Metadata})
+	mockHoneycomb.SendResponse(transmission.Response{StatusCode: http.StatusAccepted, Metadata: events[2].Metadata})
+	mockHoneycomb.SendResponse(transmission.Response{StatusCode: http.StatusBadRequest, Err: errors.New("got unexpected HTTP status 400"), Metadata: events[3].Metadata})
+	assert.Eventually(func() bool {
+		return exporter.Stats().SendsFailed == 1
+	}, time.Second, time.Millisecond)
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	stats := exporter.Stats()
+	assert.Equal(uint64(1), stats.SpansConverted)
+	assert.Equal(uint64(2), stats.SpanEventsEmitted)
+	assert.Equal(uint64(1), stats.LinksEmitted)
+	assert.Equal(uint64(4), stats.SendsAttempted)
+	assert.Equal(uint64(1), stats.SendsFailed)
+	assert.Contains(stats.LastError.Error(), "400")
+	assert.False(stats.LastErrorTime.Before(start))
+	assert.False(stats.LastSuccessTime.Before(start))
+}
@@ -0,0 +1,314 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// TailSamplingRule identifies a trace that the exporter should always keep
+// when tail sampling. It is given the trace's root span, or nil if the root
+// span hadn't ended within the decision wait, and all of the trace's spans
+// that had ended by then.
+type TailSamplingRule func(root *trace.SpanSnapshot, spans []*trace.SpanSnapshot) bool
+
+// KeepSlowTraces returns a TailSamplingRule that keeps the traces whose root
+// span lasted at least as long as the threshold given for its name in
+// byRootName or, for other names, defaultThreshold. A threshold of zero
+// disables the rule for the corresponding traces.
+//
+// If a trace's root span hasn't ended when the exporter decides whether to
+// keep it, the rule considers the time spanned by the trace's other spans,
+// using the name of the earliest one.
+func KeepSlowTraces(defaultThreshold time.Duration, byRootName map[string]time.Duration) TailSamplingRule {
+	return func(root *trace.SpanSnapshot, spans []*trace.SpanSnapshot) bool {
+		var name string
+		var duration time.Duration
+		if root != nil {
+			name = root.Name
+			duration = root.EndTime.Sub(root.StartTime)
+		} else {
+			var start, end time.Time
+			for _, s := range spans {
+				if start.IsZero() || s.StartTime.Before(start) {
+					start = s.StartTime
+					name = s.Name
+				}
+				if s.EndTime.After(end) {
+					end = s.EndTime
+				}
+			}
+			duration = end.Sub(start)
+		}
+		threshold, ok := byRootName[name]
+		if !ok {
+			threshold = defaultThreshold
+		}
+		return threshold > 0 && duration >= threshold
+	}
+}
+
+// KeepErrorTraces returns a TailSamplingRule that keeps the traces containing
+// a span whose status is Error.
+func KeepErrorTraces() TailSamplingRule {
+	return func(_ *trace.SpanSnapshot, spans []*trace.SpanSnapshot) bool {
+		for _, s := range spans {
+			if s.StatusCode == codes.Error {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithTailSampling causes the exporter to hold the spans of each trace until
+// its root span ends, or until decisionWait has passed since the first of its
+// spans arrived, and then to decide whether to send the whole trace. The
+// exporter keeps every trace matched by one of the rules and samples the
+// others, keeping one in rate of them independently of any sampler's decision,
+// or none if rate is zero. It sends the spans of sampled traces with their
+// sample rates multiplied by rate, so that each kept span stands for the spans
+// discarded by both head and tail sampling, while sending the spans of traces
+// kept by a rule with their sample rates unchanged. The exporter records the
+// reason for keeping each trace in the "meta.sample_reason" field of its
+// events: "tail:#1" for the first rule, "tail:#2" for the second, and so on, or
+// "tail:rate" for traces kept by sampling. For example, to keep every trace
+// containing an error along with one in twenty of the others:
+//
+//	WithTailSampling(20, 30*time.Second, KeepErrorTraces())
+//
+// Spans arriving after the exporter decided the fate of their trace follow
+// that decision, as long as the decision is among the most recent ones, of
+// which the exporter remembers a fixed number.
+func WithTailSampling(rate uint, decisionWait time.Duration, rules ...TailSamplingRule) ExporterOption {
+	return func(c *exporterConfig) error {
+		if decisionWait <= 0 {
+			return errors.New("tail sampling decision wait must be positive")
+		}
+		for _, r := range rules {
+			if r == nil {
+				return errors.New("tail sampling rule must not be nil")
+			}
+		}
+		c.tailSampling = &tailSamplingConfig{
+			rate:         rate,
+			decisionWait: decisionWait,
+			rules:        rules,
+		}
+		return nil
+	}
+}
+
+type tailSamplingConfig struct {
+	rate         uint
+	decisionWait time.Duration
+	rules        []TailSamplingRule
+}
+
+// tailDecisionCacheSize is the number of trace decisions the tail sampler
+// remembers for spans that arrive late.
+const tailDecisionCacheSize = 10000
+
+// tailDecision records whether the tail sampler kept a trace, and why.
+type tailDecision struct {
+	// rate is the rate at which the trace was sampled, or zero if it was
+	// dropped.
+	rate uint
+	// reason is the value of the "meta.sample_reason" field describing the
+	// decision.
+	reason string
+}
+
+// pendingTrace holds the spans of a trace awaiting a decision.
+type pendingTrace struct {
+	arrived time.Time
+	spans   []*trace.SpanSnapshot
+}
+
+// tailSampler buffers spans by trace and decides whether to export each
+// trace once it is complete.
+type tailSampler struct {
+	tailSamplingConfig
+	export func(s *trace.SpanSnapshot, d *tailDecision)
+
+	mu      sync.Mutex
+	pending map[apitrace.TraceID]*pendingTrace
+	// pendingSpans is the number of spans held in pending.
+	pendingSpans int
+	decisions    map[apitrace.TraceID]*tailDecision
+	decided      []apitrace.TraceID
+	next         int
+	closed       bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// tailExport is a span the tail sampler has decided to export.
+type tailExport struct {
+	span     *trace.SpanSnapshot
+	decision *tailDecision
+}
+
+func newTailSampler(conf tailSamplingConfig, export func(*trace.SpanSnapshot, *tailDecision)) *tailSampler {
+	t := &tailSampler{
+		tailSamplingConfig: conf,
+		export:             export,
+		pending:            make(map[apitrace.TraceID]*pendingTrace),
+		decisions:          make(map[apitrace.TraceID]*tailDecision),
+		decided:            make([]apitrace.TraceID, 0, tailDecisionCacheSize),
+		done:               make(chan struct{}),
+	}
+	t.wg.Add(1)
+	go t.sweep()
+	return t
+}
+
+// isLocalRoot reports whether a span is the first span of its trace in this
+// process.
+func isLocalRoot(s *trace.SpanSnapshot) bool {
+	return !s.ParentSpanID.IsValid() || s.HasRemoteParent
+}
+
+// add accepts spans, exporting those whose traces are decided.
+func (t *tailSampler) add(spans []*trace.SpanSnapshot) {
+	var ready []tailExport
+	t.mu.Lock()
+	for _, s := range spans {
+		id := s.SpanContext.TraceID
+		if d, ok := t.decisions[id]; ok || t.closed {
+			// After closing, the sampler drops spans of undecided traces.
+			if ok && d.rate != 0 {
+				ready = append(ready, tailExport{s, d})
+			}
+			continue
+		}
+		p := t.pending[id]
+		if p == nil {
+			p = &pendingTrace{arrived: time.Now()}
+			t.pending[id] = p
+		}
+		p.spans = append(p.spans, s)
+		t.pendingSpans++
+		if isLocalRoot(s) {
+			ready = t.decide(id, s, ready)
+		}
+	}
+	t.mu.Unlock()
+	t.exportAll(ready)
+}
+
+// decide chooses whether to keep a pending trace, appending its spans to
+// ready if so. t.mu must be held.
+func (t *tailSampler) decide(id apitrace.TraceID, root *trace.SpanSnapshot, ready []tailExport) []tailExport {
+	p := t.pending[id]
+	delete(t.pending, id)
+	t.pendingSpans -= len(p.spans)
+
+	d := &tailDecision{rate: t.rate, reason: "tail:rate"}
+	for i, rule := range t.rules {
+		if rule(root, p.spans) {
+			d.rate = 1
+			d.reason = fmt.Sprintf("tail:#%d", i+1)
+			break
+		}
+	}
+	if d.rate != 1 && !exporterSampled(id, "tail", d.rate) {
+		d.rate = 0
+	}
+	t.remember(id, d)
+
+	if d.rate != 0 {
+		for _, s := range p.spans {
+			ready = append(ready, tailExport{s, d})
+		}
+	}
+	return ready
+}
+
+// remember records the decision for a trace, forgetting the oldest decision
+// if the cache is full. t.mu must be held.
+func (t *tailSampler) remember(id apitrace.TraceID, d *tailDecision) {
+	if len(t.decided) < tailDecisionCacheSize {
+		t.decided = append(t.decided, id)
+	} else {
+		delete(t.decisions, t.decided[t.next])
+		t.decided[t.next] = id
+		t.next = (t.next + 1) % tailDecisionCacheSize
+	}
+	t.decisions[id] = d
+}
+
+func (t *tailSampler) exportAll(ready []tailExport) {
+	for _, r := range ready {
+		t.export(r.span, r.decision)
+	}
+}
+
+// sweep decides the traces whose root spans haven't arrived within the
+// decision wait.
+func (t *tailSampler) sweep() {
+	defer t.wg.Done()
+	interval := t.decisionWait / 2
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.done:
+			return
+		case now := <-ticker.C:
+			t.flush(now.Add(-t.decisionWait))
+		}
+	}
+}
+
+// flush decides the pending traces whose first spans arrived no later than
+// the given time.
+func (t *tailSampler) flush(cutoff time.Time) {
+	var ready []tailExport
+	t.mu.Lock()
+	for id, p := range t.pending {
+		if !p.arrived.After(cutoff) {
+			ready = t.decide(id, nil, ready)
+		}
+	}
+	t.mu.Unlock()
+	t.exportAll(ready)
+}
+
+// pendingCount returns the number of spans awaiting a decision.
+func (t *tailSampler) pendingCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.pendingSpans
+}
+
+// close stops the sampler, deciding all pending traces.
+func (t *tailSampler) close() {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+	t.mu.Unlock()
+
+	close(t.done)
+	t.wg.Wait()
+	t.flush(time.Now())
+}
+
+// exportTailSampled exports a span kept by the tail sampler. Any failure has
+// already been reported to the error hook.
+func (e *Exporter) exportTailSampled(s *trace.SpanSnapshot, d *tailDecision) {
+	_ = e.exportSpan(context.Background(), s, d)
+}
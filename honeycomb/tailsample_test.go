@@ -0,0 +1,176 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func tailTestSpan(traceID byte, spanID, parentID byte, name string, d time.Duration) *trace.SpanSnapshot {
+	start := time.Unix(1600000000, 0)
+	s := &trace.SpanSnapshot{
+		SpanContext: apitrace.SpanContext{
+			TraceID: apitrace.TraceID{traceID},
+			SpanID:  apitrace.SpanID{spanID},
+		},
+		Name:      name,
+		StartTime: start,
+		EndTime:   start.Add(d),
+	}
+	if parentID != 0 {
+		s.ParentSpanID = apitrace.SpanID{parentID}
+	}
+	return s
+}
+
+type tailExports map[string]uint
+
+func newTestTailSampler(rate uint, rules ...TailSamplingRule) (*tailSampler, tailExports) {
+	exported := make(tailExports)
+	t := newTailSampler(tailSamplingConfig{
+		rate:         rate,
+		decisionWait: time.Hour,
+		rules:        rules,
+	}, func(s *trace.SpanSnapshot, d *tailDecision) {
+		exported[s.Name] = d.rate
+	})
+	return t, exported
+}
+
+func TestTailSamplerKeepSlowTraces(t *testing.T) {
+	sampler, exported := newTestTailSampler(0, KeepSlowTraces(time.Second, map[string]time.Duration{
+		"batch": time.Minute,
+	}))
+	defer sampler.close()
+
+	sampler.add([]*trace.SpanSnapshot{
+		tailTestSpan(1, 2, 1, "slow child", time.Millisecond),
+		tailTestSpan(2, 2, 1, "fast child", time.Millisecond),
+	})
+	assert.Empty(t, exported)
+
+	sampler.add([]*trace.SpanSnapshot{
+		tailTestSpan(1, 1, 0, "slow root", 2*time.Second),
+		tailTestSpan(2, 1, 0, "fast root", 10*time.Millisecond),
+		tailTestSpan(3, 1, 0, "batch", 2*time.Second),
+	})
+	assert.Equal(t, tailExports{"slow child": 1, "slow root": 1}, exported)
+
+	// Late spans follow the decision for their trace.
+	sampler.add([]*trace.SpanSnapshot{
+		tailTestSpan(1, 3, 1, "late slow", time.Millisecond),
+		tailTestSpan(2, 3, 1, "late fast", time.Millisecond),
+	})
+	assert.Equal(t, tailExports{"slow child": 1, "slow root": 1, "late slow": 1}, exported)
+}
+
+func TestTailSamplerRate(t *testing.T) {
+	sampler, exported := newTestTailSampler(2)
+	defer sampler.close()
+
+	// Tail sampling keeps the trace with ID 06 at this rate, and drops the
+	// one with ID 01.
+	sampler.add([]*trace.SpanSnapshot{
+		tailTestSpan(0x06, 1, 0, "kept", time.Millisecond),
+		tailTestSpan(0x01, 1, 0, "dropped", time.Millisecond),
+	})
+	assert.Equal(t, tailExports{"kept": 2}, exported)
+}
+
+func TestTailSamplerFlush(t *testing.T) {
+	sampler, exported := newTestTailSampler(0, KeepSlowTraces(time.Second, nil))
+
+	sampler.add([]*trace.SpanSnapshot{
+		tailTestSpan(1, 2, 1, "orphan", 2*time.Second),
+		tailTestSpan(2, 2, 1, "fast orphan", time.Millisecond),
+	})
+	assert.Empty(t, exported)
+
+	sampler.flush(time.Now().Add(-time.Minute))
+	assert.Empty(t, exported)
+
+	sampler.close()
+	assert.Equal(t, tailExports{"orphan": 1}, exported)
+	assert.Empty(t, sampler.pending)
+}
+
+func TestHoneycombOutputWithTailSampling(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithTailSampling(0, time.Hour, KeepSlowTraces(time.Second, nil)))
+	assert.Nil(err)
+	tr, err := setUpTestProvider(exporter)
+	assert.Nil(err)
+
+	start := time.Now()
+	ctx, root := tr.Start(context.TODO(), "root", apitrace.WithTimestamp(start))
+	_, child := tr.Start(ctx, "child", apitrace.WithTimestamp(start))
+	child.End(apitrace.WithTimestamp(start.Add(time.Millisecond)))
+	assert.Len(mockHoneycomb.Events(), 0)
+	root.End(apitrace.WithTimestamp(start.Add(2 * time.Second)))
+
+	_, fast := tr.Start(context.TODO(), "fast")
+	fast.End()
+
+	events := mockHoneycomb.Events()
+	assert.Len(events, 2)
+	assert.Equal("child", events[0].Data["name"])
+	assert.Equal("root", events[1].Data["name"])
+
+	assert.Nil(exporter.Shutdown(context.TODO()))
+
+	_, err = makeTestExporter(mockHoneycomb, WithTailSampling(1, 0))
+	assert.Error(err)
+}
+
+func TestTailSamplerKeepErrorTraces(t *testing.T) {
+	sampler, exported := newTestTailSampler(4, KeepErrorTraces())
+	defer sampler.close()
+
+	failed := tailTestSpan(0xf0, 2, 1, "failed child", time.Millisecond)
+	failed.StatusCode = codes.Error
+	sampler.add([]*trace.SpanSnapshot{
+		failed,
+		tailTestSpan(0xf0, 1, 0, "error root", time.Millisecond),
+		tailTestSpan(0x01, 1, 0, "ok root", time.Millisecond),
+		tailTestSpan(0x06, 1, 0, "sampled root", time.Millisecond),
+	})
+	assert.Equal(t, tailExports{"failed child": 1, "error root": 1, "sampled root": 4}, exported)
+}
+
+func TestHoneycombOutputTailSampleRate(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb, WithSampleRate(3))
+	assert.Nil(err)
+
+	s := tailTestSpan(1, 1, 0, "root", time.Millisecond)
+	assert.Nil(exporter.exportSpan(context.TODO(), s, &tailDecision{rate: 5, reason: "tail:rate"}))
+	s.Attributes = []label.KeyValue{
+		label.Int("SampleRate", 2),
+		label.String("meta.sample_reason", "rules:default"),
+	}
+	assert.Nil(exporter.exportSpan(context.TODO(), s, &tailDecision{rate: 1, reason: "tail:#1"}))
+	assert.Nil(exporter.exportSpan(context.TODO(), s, nil))
+
+	events := mockHoneycomb.Events()
+	assert.Len(events, 3)
+	assert.Equal(uint(15), events[0].SampleRate)
+	assert.Equal(uint(15), events[0].Data["meta.sample_rate"])
+	assert.Equal("tail:rate", events[0].Data["meta.sample_reason"])
+	assert.Equal(uint(2), events[1].SampleRate)
+	assert.Equal("rules:default, tail:#1", events[1].Data["meta.sample_reason"])
+	assert.Equal(uint(2), events[2].SampleRate)
+	assert.Equal("rules:default", events[2].Data["meta.sample_reason"])
+}
@@ -0,0 +1,94 @@
+package honeycomb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// TeeExporter is a trace.SpanExporter that hands each batch of spans both to
+// an Exporter sending them to Honeycomb and to other exporters, such as one
+// writing them to stdout or sending them by OTLP elsewhere, during a
+// migration or for debugging.
+//
+// The other exporters fail independently of the Exporter and each other:
+// their failures are reported to the Exporter's error hook, identifying the
+// exporter by its position among them, such as "#2," but aren't returned.
+// The TeeExporter returns only the failures of the Exporter.
+type TeeExporter struct {
+	primary *Exporter
+	others  []trace.SpanExporter
+}
+
+var _ trace.SpanExporter = (*TeeExporter)(nil)
+
+// NewTeeExporter returns a TeeExporter handing spans to primary and to each
+// of others.
+func NewTeeExporter(primary *Exporter, others ...trace.SpanExporter) *TeeExporter {
+	return &TeeExporter{
+		primary: primary,
+		others:  others,
+	}
+}
+
+// onError reports a failure of one of the other exporters.
+func (t *TeeExporter) onError(i int, err error) {
+	onError := t.primary.onError
+	if onError == nil {
+		onError = handleError
+	}
+	onError(fmt.Errorf("tee exporter #%d: %w", i+1, err))
+}
+
+// each calls f for each of the other exporters concurrently with calling
+// primary, waiting for them all to return, and returns the error returned
+// by primary.
+func (t *TeeExporter) each(f func(trace.SpanExporter) error, primary func() error) error {
+	var wg sync.WaitGroup
+	for i, other := range t.others {
+		wg.Add(1)
+		go func(i int, other trace.SpanExporter) {
+			defer wg.Done()
+			if err := f(other); err != nil {
+				t.onError(i, err)
+			}
+		}(i, other)
+	}
+	err := primary()
+	wg.Wait()
+	return err
+}
+
+// ExportSpans hands spans to each of the exporters, concurrently, returning
+// once they all have returned.
+func (t *TeeExporter) ExportSpans(ctx context.Context, spans []*trace.SpanSnapshot) error {
+	return t.each(func(other trace.SpanExporter) error {
+		return other.ExportSpans(ctx, spans)
+	}, func() error {
+		return t.primary.ExportSpans(ctx, spans)
+	})
+}
+
+// ForceFlush flushes the Exporter, as its ForceFlush method does, and those
+// of the other exporters that have a ForceFlush method of the same form.
+func (t *TeeExporter) ForceFlush(ctx context.Context) error {
+	return t.each(func(other trace.SpanExporter) error {
+		if f, ok := other.(interface{ ForceFlush(context.Context) error }); ok {
+			return f.ForceFlush(ctx)
+		}
+		return nil
+	}, func() error {
+		return t.primary.ForceFlush(ctx)
+	})
+}
+
+// Shutdown shuts down each of the exporters.
+func (t *TeeExporter) Shutdown(ctx context.Context) error {
+	return t.each(func(other trace.SpanExporter) error {
+		return other.Shutdown(ctx)
+	}, func() error {
+		return t.primary.Shutdown(ctx)
+	})
+}
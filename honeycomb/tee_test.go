@@ -0,0 +1,46 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestTeeExporter(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	var mu sync.Mutex
+	var errs []error
+	exporter, err := makeTestExporter(mockHoneycomb, CallingOnError(func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}))
+	if !assert.Nil(err) {
+		return
+	}
+	failure := errors.New("stdout closed")
+	working, failing := &recordingExporter{}, &recordingExporter{err: failure}
+	tee := NewTeeExporter(exporter, working, failing)
+
+	spans := []*trace.SpanSnapshot{{Name: "first"}, {Name: "second"}}
+	assert.Nil(tee.ExportSpans(context.Background(), spans))
+	assert.Nil(tee.ForceFlush(context.Background()))
+	assert.Len(mockHoneycomb.Events(), 2)
+	assert.Equal(spans, working.spans)
+	if assert.Len(errs, 1) {
+		assert.True(errors.Is(errs[0], failure))
+		assert.Contains(errs[0].Error(), "#2")
+	}
+
+	assert.Nil(tee.Shutdown(context.Background()))
+	assert.True(working.shutdown)
+	assert.True(failing.shutdown)
+	assert.Equal(ErrExporterShutdown, tee.ExportSpans(context.Background(), spans))
+}
@@ -0,0 +1,83 @@
+package honeycomb
+
+import (
+	"math"
+	"time"
+
+	"go.opentelemetry.io/otel/label"
+)
+
+// attributeTimestamp interprets an attribute value as a point in time.
+// Strings must use RFC 3339 format. Numbers are taken as time since the Unix
+// epoch, with their magnitude determining their unit: seconds, milliseconds,
+// microseconds, or nanoseconds.
+func attributeTimestamp(v label.Value) (time.Time, bool) {
+	switch v.Type() {
+	case label.STRING:
+		t, err := time.Parse(time.RFC3339Nano, v.AsString())
+		return t, err == nil
+	case label.INT64:
+		return epochTimestamp(v.AsInt64())
+	case label.FLOAT64:
+		return epochFloatTimestamp(v.AsFloat64())
+	default:
+		return time.Time{}, false
+	}
+}
+
+// epochUnit returns the unit of a number of units of time since the Unix
+// epoch, determined by its magnitude.
+func epochUnit(abs uint64) time.Duration {
+	switch {
+	case abs < 1e11:
+		return time.Second
+	case abs < 1e14:
+		return time.Millisecond
+	case abs < 1e17:
+		return time.Microsecond
+	default:
+		return time.Nanosecond
+	}
+}
+
+// epochTimestamp interprets an integer as time since the Unix epoch, in the
+// unit given by epochUnit, failing for times that can't be expressed in
+// int64 nanoseconds.
+func epochTimestamp(n int64) (time.Time, bool) {
+	abs := uint64(n)
+	if n < 0 {
+		abs = -abs
+	}
+	unit := int64(epochUnit(abs))
+	if n > math.MaxInt64/unit || n < math.MinInt64/unit {
+		return time.Time{}, false
+	}
+	return time.Unix(0, n*unit), true
+}
+
+// epochFloatTimestamp interprets a floating-point number as epochTimestamp
+// does an integer, also failing for NaN and infinities.
+func epochFloatTimestamp(f float64) (time.Time, bool) {
+	unit := time.Nanosecond
+	if abs := math.Abs(f); abs < 1e17 {
+		unit = epochUnit(uint64(abs))
+	}
+	ns := f * float64(unit)
+	// float64(math.MaxInt64) rounds up to 2^63, which is out of range, while
+	// -2^63 is in range. The comparisons are false for NaN.
+	if !(ns < math.MaxInt64 && ns >= math.MinInt64) {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(ns)), true
+}
+
+// timestampFromAttributes returns the time given by the attribute with the
+// given key, if present and valid.
+func timestampFromAttributes(attrs []label.KeyValue, key label.Key) (time.Time, bool) {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return attributeTimestamp(kv.Value)
+		}
+	}
+	return time.Time{}, false
+}
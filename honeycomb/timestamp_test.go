@@ -0,0 +1,73 @@
+package honeycomb
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestAttributeTimestamp(t *testing.T) {
+	want := time.Date(2020, time.December, 1, 12, 30, 15, 250000000, time.UTC)
+	tests := []struct {
+		description string
+		value       label.Value
+		ok          bool
+	}{
+		{"RFC 3339", label.StringValue("2020-12-01T12:30:15.25Z"), true},
+		{"seconds", label.Float64Value(float64(want.UnixNano()) / 1e9), true},
+		{"milliseconds", label.Int64Value(want.UnixNano() / 1e6), true},
+		{"microseconds", label.Int64Value(want.UnixNano() / 1e3), true},
+		{"nanoseconds", label.Int64Value(want.UnixNano()), true},
+		{"malformed string", label.StringValue("yesterday"), false},
+		{"NaN", label.Float64Value(math.NaN()), false},
+		{"infinity", label.Float64Value(math.Inf(1)), false},
+		{"seconds out of range", label.Int64Value(5e10), false},
+		{"float seconds out of range", label.Float64Value(-5e10), false},
+		{"microseconds out of range", label.Int64Value(5e16), false},
+		{"bool", label.BoolValue(true), false},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			got, ok := attributeTimestamp(test.value)
+			assert.Equal(t, test.ok, ok)
+			if test.ok {
+				assert.WithinDuration(t, want, got, time.Microsecond)
+			}
+		})
+	}
+
+	// Integers are converted exactly, even at the limits of the range.
+	for _, ns := range []int64{want.UnixNano() + 1, math.MaxInt64, math.MinInt64} {
+		got, ok := attributeTimestamp(label.Int64Value(ns))
+		if assert.True(t, ok) {
+			assert.Equal(t, ns, got.UnixNano())
+		}
+	}
+}
+
+func TestHoneycombOutputWithTimestampAttribute(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	tr, err := setUpTestExporter(mockHoneycomb, WithTimestampAttribute("log.timestamp"))
+	assert.Nil(err)
+
+	_, span := tr.Start(context.TODO(), "myTestSpan")
+	span.SetAttributes(label.String("log.timestamp", "2020-12-01T12:30:15Z"))
+	span.AddEvent("something", apitrace.WithAttributes(label.Int64("log.timestamp", 1606825816000)))
+	span.AddEvent("something else")
+	span.End()
+
+	events := mockHoneycomb.Events()
+	assert.Len(events, 3)
+	assert.True(time.Date(2020, time.December, 1, 12, 30, 16, 0, time.UTC).Equal(events[0].Timestamp))
+	assert.WithinDuration(time.Now(), events[1].Timestamp, time.Minute)
+	assert.True(time.Date(2020, time.December, 1, 12, 30, 15, 0, time.UTC).Equal(events[2].Timestamp))
+}
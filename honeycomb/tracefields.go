@@ -0,0 +1,164 @@
+package honeycomb
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// traceFieldsTraceStateKey is the tracestate member that carries the trace
+// fields.
+const traceFieldsTraceStateKey = "hnytf"
+
+type traceFieldsContextKey struct{}
+
+// ContextWithTraceFields returns a copy of ctx carrying the given trace
+// fields, along with any it already carries that aren't replaced. Like the
+// Beelines' trace fields, these are fields, such as a customer's tier or the
+// state of a feature flag, that apply to a whole trace: TraceFieldsSpanProcessor
+// sets them as attributes on every span started in the returned context or
+// its descendants, and TraceFieldsPropagator carries them to the services it
+// calls, so that they appear on every span of the trace in every service.
+func ContextWithTraceFields(ctx context.Context, fields map[string]string) context.Context {
+	merged := make(map[string]string, len(fields))
+	for k, v := range TraceFieldsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		if len(k) != 0 {
+			merged[k] = v
+		}
+	}
+	return context.WithValue(ctx, traceFieldsContextKey{}, merged)
+}
+
+// TraceFieldsFromContext returns the trace fields carried by ctx. The
+// returned map must not be modified.
+func TraceFieldsFromContext(ctx context.Context) map[string]string {
+	fields, _ := ctx.Value(traceFieldsContextKey{}).(map[string]string)
+	return fields
+}
+
+// encodeTraceFields encodes trace fields as a tracestate member value, as
+// many of them as fit, in order of their names.
+func encodeTraceFields(fields map[string]string) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		pair := url.QueryEscape(name) + ":" + url.QueryEscape(fields[name])
+		if b.Len()+len(pair)+1 > maxTraceStateValueLength {
+			continue
+		}
+		if b.Len() != 0 {
+			b.WriteByte(';')
+		}
+		b.WriteString(pair)
+	}
+	return b.String()
+}
+
+// decodeTraceFields decodes trace fields encoded by encodeTraceFields,
+// skipping any that are malformed.
+func decodeTraceFields(s string) map[string]string {
+	fields := make(map[string]string)
+	for _, pair := range strings.Split(s, ";") {
+		i := strings.IndexByte(pair, ':')
+		if i < 0 {
+			continue
+		}
+		name, err := url.QueryUnescape(pair[:i])
+		if err != nil || len(name) == 0 {
+			continue
+		}
+		value, err := url.QueryUnescape(pair[i+1:])
+		if err != nil {
+			continue
+		}
+		fields[name] = value
+	}
+	return fields
+}
+
+// TraceFieldsPropagator propagates the trace fields set with
+// ContextWithTraceFields to the services a service calls in a member of the
+// W3C tracestate header. Because the tracestate header is limited in size,
+// the trace fields, once encoded, may take at most 256 bytes; fields that
+// don't fit are not propagated.
+//
+// Because it amends the tracestate header written by the
+// propagation.TraceContext propagator, it must follow that propagator when
+// combined with propagation.NewCompositeTextMapPropagator.
+type TraceFieldsPropagator struct{}
+
+var _ propagation.TextMapPropagator = TraceFieldsPropagator{}
+
+// Inject adds the trace fields in ctx to the tracestate header in the
+// carrier.
+func (TraceFieldsPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	fields := TraceFieldsFromContext(ctx)
+	if len(fields) == 0 || !apitrace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	value := encodeTraceFields(fields)
+	if !validTraceStateValue(value) {
+		return
+	}
+	members := traceStateMembers(carrier.Get(traceStateHeader), traceFieldsTraceStateKey)
+	members = append([]string{traceFieldsTraceStateKey + "=" + value}, members...)
+	carrier.Set(traceStateHeader, strings.Join(members, ","))
+}
+
+// Extract records the trace fields from the tracestate header in the
+// carrier, if present, in the returned context.
+func (TraceFieldsPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	if value, ok := traceStateValue(carrier.Get(traceStateHeader), traceFieldsTraceStateKey); ok && validTraceStateValue(value) {
+		if fields := decodeTraceFields(value); len(fields) != 0 {
+			return ContextWithTraceFields(ctx, fields)
+		}
+	}
+	return ctx
+}
+
+// Fields returns the keys whose values are set with Inject.
+func (TraceFieldsPropagator) Fields() []string {
+	return []string{traceStateHeader}
+}
+
+// TraceFieldsSpanProcessor is a span processor that sets the trace fields
+// carried by the context in which each span starts as attributes of the
+// span.
+type TraceFieldsSpanProcessor struct{}
+
+var _ sdktrace.SpanProcessor = TraceFieldsSpanProcessor{}
+
+// OnStart sets the trace fields as attributes of starting spans.
+func (TraceFieldsSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	fields := TraceFieldsFromContext(parent)
+	if len(fields) == 0 {
+		return
+	}
+	attrs := make([]label.KeyValue, 0, len(fields))
+	for name, value := range fields {
+		attrs = append(attrs, label.String(name, value))
+	}
+	s.SetAttributes(attrs...)
+}
+
+// OnEnd does nothing.
+func (TraceFieldsSpanProcessor) OnEnd(sdktrace.ReadOnlySpan) {}
+
+// Shutdown does nothing.
+func (TraceFieldsSpanProcessor) Shutdown(context.Context) error { return nil }
+
+// ForceFlush does nothing.
+func (TraceFieldsSpanProcessor) ForceFlush() {}
@@ -0,0 +1,71 @@
+package honeycomb
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestTraceFieldsPropagator(t *testing.T) {
+	assert := assert.New(t)
+	ctx := ContextWithTraceFields(clientContext(), map[string]string{
+		"customer.tier": "gold",
+		"flag":          "new checkout; v2",
+	})
+	ctx = ContextWithTraceFields(ctx, map[string]string{"customer.tier": "platinum"})
+
+	carrier := mapCarrier{"tracestate": "hnytf=stale:1,vendor=x"}
+	TraceFieldsPropagator{}.Inject(ctx, carrier)
+	assert.Equal("hnytf=customer.tier:platinum;flag:new+checkout%3B+v2,vendor=x", carrier["tracestate"])
+
+	extracted := TraceFieldsPropagator{}.Extract(context.Background(), carrier)
+	assert.Equal(map[string]string{
+		"customer.tier": "platinum",
+		"flag":          "new checkout; v2",
+	}, TraceFieldsFromContext(extracted))
+
+	// Contexts without spans or fields propagate nothing, and fields that
+	// don't fit in the tracestate header are left out.
+	carrier = mapCarrier{}
+	TraceFieldsPropagator{}.Inject(ContextWithTraceFields(context.Background(), map[string]string{"a": "b"}), carrier)
+	TraceFieldsPropagator{}.Inject(clientContext(), carrier)
+	assert.Empty(carrier)
+
+	TraceFieldsPropagator{}.Inject(ContextWithTraceFields(clientContext(), map[string]string{
+		"big":   strings.Repeat("x", 300),
+		"small": "y",
+	}), carrier)
+	assert.Equal("hnytf=small:y", carrier["tracestate"])
+
+	assert.Empty(TraceFieldsFromContext(TraceFieldsPropagator{}.Extract(context.Background(), mapCarrier{"tracestate": "hnytf=nonsense"})))
+}
+
+func TestTraceFieldsSpanProcessor(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb)
+	assert.Nil(err)
+	tr, err := setUpTestProvider(exporter, sdktrace.WithSpanProcessor(TraceFieldsSpanProcessor{}))
+	assert.Nil(err)
+
+	ctx := TraceFieldsPropagator{}.Extract(remoteContext(), mapCarrier{"tracestate": "hnytf=customer.tier:gold"})
+	ctx, parent := tr.Start(ctx, "parent")
+	_, child := tr.Start(ctx, "child")
+	child.End()
+	parent.End()
+	_, unrelated := tr.Start(context.Background(), "unrelated")
+	unrelated.End()
+
+	events := mockHoneycomb.Events()
+	if assert.Len(events, 3) {
+		assert.Equal("gold", events[0].Data["customer.tier"])
+		assert.Equal("gold", events[1].Data["customer.tier"])
+		assert.NotContains(events[2].Data, "customer.tier")
+	}
+}
@@ -2,6 +2,7 @@ package honeycomb
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
@@ -38,18 +39,64 @@ func oTelSpanKind(kind tracepb.Span_SpanKind) apitrace.SpanKind {
 	}
 }
 
-// Creates an OpenTelemetry SpanContext from information in an OC Span.
+// InvalidIDError reports that an OC Span carries a trace or span ID that
+// can't be converted, because it has the wrong length or is all zeros.
+type InvalidIDError struct {
+	// Field names the ID, such as "trace ID" or "link span ID".
+	Field string
+	// ID is the malformed ID.
+	ID []byte
+}
+
+func (e *InvalidIDError) Error() string {
+	return fmt.Sprintf("OpenCensus span has invalid %s %x (%d bytes)", e.Field, e.ID, len(e.ID))
+}
+
+// isZeroID reports whether an ID consists only of zeros.
+func isZeroID(id []byte) bool {
+	for _, b := range id {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// convertTraceID converts an OC trace ID, which must have 16 bytes or, as
+// some older tracers produce, 8 bytes, which are left-padded with zeros.
+func convertTraceID(id []byte, field string) (apitrace.TraceID, error) {
+	var traceID apitrace.TraceID
+	if (len(id) != len(traceID) && len(id) != len(traceID)/2) || isZeroID(id) {
+		return traceID, &InvalidIDError{Field: field, ID: id}
+	}
+	copy(traceID[len(traceID)-len(id):], id)
+	return traceID, nil
+}
+
+// convertSpanID converts an OC span ID, which must have 8 bytes.
+func convertSpanID(id []byte, field string) (apitrace.SpanID, error) {
+	var spanID apitrace.SpanID
+	if len(id) != len(spanID) || isZeroID(id) {
+		return spanID, &InvalidIDError{Field: field, ID: id}
+	}
+	copy(spanID[:], id)
+	return spanID, nil
+}
+
+// Creates an OpenTelemetry SpanContext from information in an OC Span,
+// naming the IDs with the given prefix in any error.
 // Note that the OC Span has no equivalent to TraceFlags field in the
 // OpenTelemetry SpanContext type.
-func spanContext(traceID []byte, spanID []byte) apitrace.SpanContext {
+func spanContext(traceID []byte, spanID []byte, prefix string) (apitrace.SpanContext, error) {
 	ctx := apitrace.SpanContext{}
-	if traceID != nil {
-		copy(ctx.TraceID[:], traceID[:])
+	var err error
+	if ctx.TraceID, err = convertTraceID(traceID, prefix+"trace ID"); err != nil {
+		return ctx, err
 	}
-	if spanID != nil {
-		copy(ctx.SpanID[:], spanID[:])
+	if ctx.SpanID, err = convertSpanID(spanID, prefix+"span ID"); err != nil {
+		return ctx, err
 	}
-	return ctx
+	return ctx, nil
 }
 
 func spanResource(span *tracepb.Span) *resource.Resource {
@@ -95,23 +142,43 @@ func createOTelAttributes(attributes *tracepb.Span_Attributes) []label.KeyValue
 	return oTelAttrs
 }
 
-// Create Span Links (including their attributes) from an OC Span
-func createSpanLinks(spanLinks *tracepb.Span_Links) []apitrace.Link {
+// ocLinkRefType returns the LinkRefTypeKey value for the type of an OC link,
+// mapping types as the OpenCensus Jaeger exporter does.
+func ocLinkRefType(linkType tracepb.Span_Link_Type) (string, bool) {
+	switch linkType {
+	case tracepb.Span_Link_CHILD_LINKED_SPAN:
+		return linkRefTypeChildOf, true
+	case tracepb.Span_Link_PARENT_LINKED_SPAN:
+		return linkRefTypeFollowsFrom, true
+	default:
+		return "", false
+	}
+}
+
+// Create Span Links (including their attributes and types) from an OC Span
+func createSpanLinks(spanLinks *tracepb.Span_Links) ([]apitrace.Link, error) {
 	if spanLinks == nil {
-		return nil
+		return nil, nil
 	}
 
 	links := make([]apitrace.Link, len(spanLinks.Link))
 
 	for i, link := range spanLinks.Link {
+		sc, err := spanContext(link.GetTraceId(), link.GetSpanId(), "link ")
+		if err != nil {
+			return nil, err
+		}
 		traceLink := apitrace.Link{
-			SpanContext: spanContext(link.GetTraceId(), link.GetSpanId()),
+			SpanContext: sc,
 			Attributes:  createOTelAttributes(link.Attributes),
 		}
+		if refType, ok := ocLinkRefType(link.GetType()); ok {
+			traceLink.Attributes = append(traceLink.Attributes, LinkRefTypeKey.String(refType))
+		}
 		links[i] = traceLink
 	}
 
-	return links
+	return links, nil
 }
 
 func createMessageEvents(spanEvents *tracepb.Span_TimeEvents) []trace.Event {
@@ -149,6 +216,24 @@ func attributeValueAsString(val *tracepb.AttributeValue) string {
 	return ""
 }
 
+func getDroppedAttributeCount(attributes *tracepb.Span_Attributes) int {
+	if attributes != nil {
+		return int(attributes.DroppedAttributesCount)
+	}
+
+	return 0
+}
+
+// OpenTelemetry has no counterpart to OC message events, so count those
+// dropped along with dropped annotations.
+func getDroppedMessageEventCount(timeEvents *tracepb.Span_TimeEvents) int {
+	if timeEvents != nil {
+		return int(timeEvents.DroppedAnnotationsCount) + int(timeEvents.DroppedMessageEventsCount)
+	}
+
+	return 0
+}
+
 func getDroppedLinkCount(links *tracepb.Span_Links) int {
 	if links != nil {
 		return int(links.DroppedLinksCount)
@@ -200,20 +285,34 @@ func getStatusMessage(span *tracepb.Span) string {
 	}
 }
 
-// OCProtoSpanToOTelSpanSnapshot converts an OC Span to an OTel SpanSnapshot
+// OCProtoSpanToOTelSpanSnapshot converts an OC Span to an OTel SpanSnapshot.
+// It returns an *InvalidIDError if the span or any of its links has a
+// malformed trace, span, or parent span ID. Trace IDs must have 16 bytes or
+// 8 bytes, which are left-padded with zeros, and span IDs must have 8 bytes.
+// Spans without parents may omit their parent span IDs.
 func OCProtoSpanToOTelSpanSnapshot(span *tracepb.Span) (*trace.SpanSnapshot, error) {
 	if span == nil {
 		return nil, errors.New("expected a non-nil span")
 	}
 
+	sc, err := spanContext(span.GetTraceId(), span.GetSpanId(), "")
+	if err != nil {
+		return nil, err
+	}
 	spanData := &trace.SpanSnapshot{
-		SpanContext: spanContext(span.GetTraceId(), span.GetSpanId()),
+		SpanContext: sc,
 	}
 
-	copy(spanData.ParentSpanID[:], span.GetParentSpanId()[:])
+	if parentID := span.GetParentSpanId(); len(parentID) != 0 {
+		if spanData.ParentSpanID, err = convertSpanID(parentID, "parent span ID"); err != nil {
+			return nil, err
+		}
+	}
+	if spanData.Links, err = createSpanLinks(span.GetLinks()); err != nil {
+		return nil, err
+	}
 	spanData.Name = getSpanName(span)
 	spanData.SpanKind = oTelSpanKind(span.GetKind())
-	spanData.Links = createSpanLinks(span.GetLinks())
 	spanData.Attributes = createOTelAttributes(span.GetAttributes())
 	spanData.MessageEvents = createMessageEvents(span.GetTimeEvents())
 	spanData.StartTime = timestampToTime(span.GetStartTime())
@@ -221,6 +320,8 @@ func OCProtoSpanToOTelSpanSnapshot(span *tracepb.Span) (*trace.SpanSnapshot, err
 	spanData.StatusCode = getStatusCode(span)
 	spanData.StatusMessage = getStatusMessage(span)
 	spanData.HasRemoteParent = getHasRemoteParent(span)
+	spanData.DroppedAttributeCount = getDroppedAttributeCount(span.GetAttributes())
+	spanData.DroppedMessageEventCount = getDroppedMessageEventCount(span.GetTimeEvents())
 	spanData.DroppedLinkCount = getDroppedLinkCount(span.GetLinks())
 	spanData.ChildSpanCount = getChildSpanCount(span)
 	spanData.Resource = spanResource(span)
@@ -1,6 +1,7 @@
 package honeycomb
 
 import (
+	"errors"
 	"math"
 	"testing"
 	"time"
@@ -39,10 +40,16 @@ func TestOCProtoSpanToOTelSpanSnapshot(t *testing.T) {
 		t.Fatalf("failed to convert time to timestamp: %v", err)
 	}
 
+	traceID := []byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02}
+	spanID := []byte{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03}
+	parentID := []byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01}
+	linkTraceID := []byte{0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04}
+	linkSpanID := []byte{0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05}
+
 	span := tracepb.Span{
-		TraceId:      []byte{0x02},
-		SpanId:       []byte{0x03},
-		ParentSpanId: []byte{0x01},
+		TraceId:      traceID,
+		SpanId:       spanID,
+		ParentSpanId: parentID,
 		Name:         &tracepb.TruncatableString{Value: "trace-name"},
 		Kind:         tracepb.Span_CLIENT,
 		StartTime:    startTimestamp,
@@ -64,12 +71,14 @@ func TestOCProtoSpanToOTelSpanSnapshot(t *testing.T) {
 					Value: &tracepb.AttributeValue_BoolValue{BoolValue: true},
 				},
 			},
+			DroppedAttributesCount: 3,
 		},
 		Links: &tracepb.Span_Links{
 			Link: []*tracepb.Span_Link{
 				{
-					TraceId: []byte{0x04},
-					SpanId:  []byte{0x05},
+					TraceId: linkTraceID,
+					SpanId:  linkSpanID,
+					Type:    tracepb.Span_Link_PARENT_LINKED_SPAN,
 					Attributes: &tracepb.Span_Attributes{
 						AttributeMap: map[string]*tracepb.AttributeValue{
 							"e": {
@@ -101,6 +110,8 @@ func TestOCProtoSpanToOTelSpanSnapshot(t *testing.T) {
 					},
 				},
 			},
+			DroppedAnnotationsCount:   4,
+			DroppedMessageEventsCount: 1,
 		},
 		Status:                  &tracepb.Status{Code: int32(codes.Unset), Message: "status message"},
 		SameProcessAsParentSpan: &wrappers.BoolValue{Value: false},
@@ -114,8 +125,11 @@ func TestOCProtoSpanToOTelSpanSnapshot(t *testing.T) {
 	}
 
 	want := &expTrace.SpanSnapshot{
-		SpanContext:  spanContext([]byte{0x02}, []byte{0x03}),
-		ParentSpanID: apitrace.SpanID{0x01},
+		SpanContext: apitrace.SpanContext{
+			TraceID: apitrace.TraceID{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02},
+			SpanID:  apitrace.SpanID{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03},
+		},
+		ParentSpanID: apitrace.SpanID{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
 		SpanKind:     apitrace.SpanKindClient,
 		Name:         "trace-name",
 		StartTime:    time.Unix(start.Unix(), int64(start.Nanosecond())),
@@ -128,9 +142,14 @@ func TestOCProtoSpanToOTelSpanSnapshot(t *testing.T) {
 		},
 		Links: []apitrace.Link{
 			{
-				SpanContext: spanContext([]byte{0x04}, []byte{0x05}),
+				SpanContext: apitrace.SpanContext{
+					// The 8-byte link trace ID is left-padded with zeros.
+					TraceID: apitrace.TraceID{8: 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+					SpanID:  apitrace.SpanID{0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05},
+				},
 				Attributes: []label.KeyValue{
 					label.Float64("e", math.E),
+					LinkRefTypeKey.String("follows_from"),
 				},
 			},
 		},
@@ -143,12 +162,14 @@ func TestOCProtoSpanToOTelSpanSnapshot(t *testing.T) {
 				},
 			},
 		},
-		StatusCode:       codes.Unset,
-		StatusMessage:    "status message",
-		HasRemoteParent:  true,
-		DroppedLinkCount: 2,
-		ChildSpanCount:   5,
-		Resource:         resource.NewWithAttributes(label.String("host.name", "xanadu")),
+		StatusCode:               codes.Unset,
+		StatusMessage:            "status message",
+		HasRemoteParent:          true,
+		DroppedAttributeCount:    3,
+		DroppedMessageEventCount: 5,
+		DroppedLinkCount:         2,
+		ChildSpanCount:           5,
+		Resource:                 resource.NewWithAttributes(label.String("host.name", "xanadu")),
 	}
 
 	got, err := OCProtoSpanToOTelSpanSnapshot(&span)
@@ -161,6 +182,132 @@ func TestOCProtoSpanToOTelSpanSnapshot(t *testing.T) {
 	}
 }
 
+func TestOCProtoSpanToOTelSpanSnapshotRootSpan(t *testing.T) {
+	span := &tracepb.Span{
+		TraceId: []byte{0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19},
+		SpanId:  []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+	}
+
+	got, err := OCProtoSpanToOTelSpanSnapshot(span)
+	if err != nil {
+		t.Fatalf("failed to convert root span: %v", err)
+	}
+	if got.ParentSpanID.IsValid() {
+		t.Errorf("root span has parent span ID %s", got.ParentSpanID)
+	}
+}
+
+// These cases were derived from fuzzing OCProtoSpanToOTelSpanSnapshot with
+// malformed agent traffic. Each must be rejected rather than producing a
+// truncated, zero-padded, or all-zero ID.
+func TestOCProtoSpanToOTelSpanSnapshotMalformedIDs(t *testing.T) {
+	validTraceID := []byte{0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19}
+	validSpanID := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	for _, tc := range []struct {
+		desc  string
+		span  *tracepb.Span
+		field string
+	}{
+		{
+			desc:  "missing trace ID",
+			span:  &tracepb.Span{SpanId: validSpanID},
+			field: "trace ID",
+		},
+		{
+			desc:  "one-byte trace ID",
+			span:  &tracepb.Span{TraceId: []byte{0x02}, SpanId: validSpanID},
+			field: "trace ID",
+		},
+		{
+			desc:  "twelve-byte trace ID",
+			span:  &tracepb.Span{TraceId: validTraceID[:12], SpanId: validSpanID},
+			field: "trace ID",
+		},
+		{
+			desc:  "overlong trace ID",
+			span:  &tracepb.Span{TraceId: append(append([]byte{}, validTraceID...), 0xff), SpanId: validSpanID},
+			field: "trace ID",
+		},
+		{
+			desc:  "all-zero trace ID",
+			span:  &tracepb.Span{TraceId: make([]byte, 16), SpanId: validSpanID},
+			field: "trace ID",
+		},
+		{
+			desc:  "all-zero short trace ID",
+			span:  &tracepb.Span{TraceId: make([]byte, 8), SpanId: validSpanID},
+			field: "trace ID",
+		},
+		{
+			desc:  "missing span ID",
+			span:  &tracepb.Span{TraceId: validTraceID},
+			field: "span ID",
+		},
+		{
+			desc:  "short span ID",
+			span:  &tracepb.Span{TraceId: validTraceID, SpanId: validSpanID[:4]},
+			field: "span ID",
+		},
+		{
+			desc:  "overlong span ID",
+			span:  &tracepb.Span{TraceId: validTraceID, SpanId: validTraceID},
+			field: "span ID",
+		},
+		{
+			desc:  "all-zero span ID",
+			span:  &tracepb.Span{TraceId: validTraceID, SpanId: make([]byte, 8)},
+			field: "span ID",
+		},
+		{
+			desc:  "short parent span ID",
+			span:  &tracepb.Span{TraceId: validTraceID, SpanId: validSpanID, ParentSpanId: []byte{0x01}},
+			field: "parent span ID",
+		},
+		{
+			desc:  "all-zero parent span ID",
+			span:  &tracepb.Span{TraceId: validTraceID, SpanId: validSpanID, ParentSpanId: make([]byte, 8)},
+			field: "parent span ID",
+		},
+		{
+			desc: "short link trace ID",
+			span: &tracepb.Span{
+				TraceId: validTraceID,
+				SpanId:  validSpanID,
+				Links: &tracepb.Span_Links{
+					Link: []*tracepb.Span_Link{{TraceId: []byte{0x04}, SpanId: validSpanID}},
+				},
+			},
+			field: "link trace ID",
+		},
+		{
+			desc: "missing link span ID",
+			span: &tracepb.Span{
+				TraceId: validTraceID,
+				SpanId:  validSpanID,
+				Links: &tracepb.Span_Links{
+					Link: []*tracepb.Span_Link{{TraceId: validTraceID}},
+				},
+			},
+			field: "link span ID",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := OCProtoSpanToOTelSpanSnapshot(tc.span)
+			if err == nil {
+				t.Fatalf("expected an error, got span %+v", got)
+			}
+			var idErr *InvalidIDError
+			if !errors.As(err, &idErr) {
+				t.Fatalf("expected an *InvalidIDError, got %T: %v", err, err)
+			}
+			if idErr.Field != tc.field {
+				t.Errorf("expected the error to name %q, got %q", tc.field, idErr.Field)
+			}
+		})
+	}
+}
+
 func keyValueLess(lhs, rhs label.KeyValue) bool {
 	return lhs.Key < rhs.Key
 }
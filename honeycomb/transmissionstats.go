@@ -0,0 +1,92 @@
+package honeycomb
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// TransmissionStats describes the events the exporter has handed to
+// libhoney for transmission, and what became of them. The outcomes are
+// learned from libhoney's responses, which only the goroutine run by Start,
+// or RunErrorLogger, consumes.
+type TransmissionStats struct {
+	// Depth is the number of events queued or being sent, for which no
+	// response has been consumed.
+	Depth int64
+	// Enqueued counts the events handed to libhoney for transmission.
+	Enqueued uint64
+	// Sent counts the events Honeycomb accepted.
+	Sent uint64
+	// Failed counts the events libhoney sent that Honeycomb didn't accept,
+	// or that failed to reach it.
+	Failed uint64
+	// Dropped counts the events libhoney dropped without sending them
+	// because its queue was full.
+	Dropped uint64
+}
+
+// txCounters counts the events handed to libhoney by outcome.
+type txCounters struct {
+	enqueued uint64
+	sent     uint64
+	failed   uint64
+	dropped  uint64
+}
+
+// CallingOnQueueOverflow specifies a hook function to be called with each
+// span lost because a queue was full: for each event libhoney drops because
+// its queue of events awaiting transmission is full, with the span for which
+// it was sent, and for each span ExportSpans drops because the queue used by
+// WithAsyncExport is full. This allows reacting to the losses, such as by
+// shedding load or raising an alert. Libhoney's are also passed to the error
+// hook as errors matching ErrQueueOverflow, and counted in
+// TransmissionStats; the hook is called for them by the goroutine run by
+// Start, or by RunErrorLogger.
+func CallingOnQueueOverflow(f func(SpanReference)) ExporterOption {
+	return func(c *exporterConfig) error {
+		c.onQueueOverflow = f
+		return nil
+	}
+}
+
+// recordResponse counts the outcome of an event from the error in the
+// response to it, calling the overflow hook for dropped events.
+func (e *Exporter) recordResponse(span SpanReference, err error) {
+	if err != nil && e.selfMetrics != nil {
+		e.selfMetrics.recordSendError(err)
+	}
+	if err != nil {
+		e.stats.recordFailure(err)
+	} else {
+		e.stats.recordSuccess()
+	}
+	switch {
+	case err == nil:
+		atomic.AddUint64(&e.txCounters.sent, 1)
+	case errors.Is(err, ErrQueueOverflow):
+		atomic.AddUint64(&e.txCounters.dropped, 1)
+		if e.onQueueOverflow != nil {
+			e.onQueueOverflow(span)
+		}
+	default:
+		atomic.AddUint64(&e.txCounters.failed, 1)
+	}
+}
+
+// TransmissionStats returns statistics about the events the exporter has
+// handed to libhoney for transmission.
+func (e *Exporter) TransmissionStats() TransmissionStats {
+	s := TransmissionStats{
+		Enqueued: atomic.LoadUint64(&e.txCounters.enqueued),
+		Sent:     atomic.LoadUint64(&e.txCounters.sent),
+		Failed:   atomic.LoadUint64(&e.txCounters.failed),
+		Dropped:  atomic.LoadUint64(&e.txCounters.dropped),
+	}
+	// The outcomes are loaded after the events enqueued, so this may count
+	// too few events in flight, but not too many.
+	s.Depth = int64(s.Enqueued) - int64(s.Sent+s.Failed+s.Dropped)
+	if s.Depth < 0 {
+		s.Depth = 0
+	}
+	return s
+}
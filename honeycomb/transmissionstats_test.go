@@ -0,0 +1,87 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestTransmissionStats(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{BlockOnResponses: true}
+	var overflowed []SpanReference
+	exporter, err := makeTestExporter(mockHoneycomb,
+		CallingOnError(func(error) {}),
+		CallingOnQueueOverflow(func(span SpanReference) {
+			overflowed = append(overflowed, span)
+		}))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Nil(exporter.Start(context.Background()))
+
+	spans := make([]*trace.SpanSnapshot, 3)
+	for i := range spans {
+		spans[i] = &trace.SpanSnapshot{
+			SpanContext: apitrace.SpanContext{
+				TraceID: apitrace.TraceID{1},
+				SpanID:  apitrace.SpanID{byte(i + 1)},
+			},
+			Name: "span",
+		}
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), spans))
+	assert.Equal(TransmissionStats{Depth: 3, Enqueued: 3}, exporter.TransmissionStats())
+
+	events := mockHoneycomb.Events()
+	mockHoneycomb.SendResponse(transmission.Response{StatusCode: http.StatusAccepted, Metadata: events[0].Metadata})
+	mockHoneycomb.SendResponse(transmission.Response{Err: errors.New(queueOverflowMessage), Metadata: events[1].Metadata})
+	mockHoneycomb.SendResponse(transmission.Response{StatusCode: http.StatusBadRequest, Err: errors.New("got unexpected HTTP status 400"), Metadata: events[2].Metadata})
+	assert.Eventually(func() bool {
+		return exporter.TransmissionStats().Depth == 0
+	}, time.Second, time.Millisecond)
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	assert.Equal(TransmissionStats{Enqueued: 3, Sent: 1, Failed: 1, Dropped: 1}, exporter.TransmissionStats())
+	assert.Equal([]SpanReference{spanReference(spans[1])}, overflowed)
+}
+
+func TestQueueOverflowAsyncExport(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	block := make(chan struct{})
+	var overflowed []SpanReference
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithAsyncExport(1, 1),
+		CallingOnError(func(error) {}),
+		WithBeforeSend(func(context.Context, *libhoney.Event, *trace.SpanSnapshot) bool {
+			<-block
+			return true
+		}),
+		CallingOnQueueOverflow(func(span SpanReference) {
+			overflowed = append(overflowed, span)
+		}))
+	if !assert.Nil(err) {
+		return
+	}
+	first := &trace.SpanSnapshot{SpanContext: apitrace.SpanContext{SpanID: apitrace.SpanID{1}}}
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{first}))
+	assert.Eventually(func() bool {
+		return exporter.QueueStats().Depth == 0
+	}, time.Second, time.Millisecond)
+	queued := &trace.SpanSnapshot{SpanContext: apitrace.SpanContext{SpanID: apitrace.SpanID{2}}}
+	dropped := &trace.SpanSnapshot{SpanContext: apitrace.SpanContext{SpanID: apitrace.SpanID{3}}}
+	assert.Error(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{queued, dropped}))
+	close(block)
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.Equal([]SpanReference{spanReference(dropped)}, overflowed)
+}
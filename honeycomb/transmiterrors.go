@@ -0,0 +1,171 @@
+package honeycomb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+)
+
+// The reasons for which Honeycomb may not accept events, identifying the
+// *TransmissionError passed to the error hook with errors.Is, so that callers
+// can react to each differently, such as by paging on ErrUnauthorized but
+// only counting ErrRateLimited.
+var (
+	// ErrUnauthorized reports that Honeycomb rejected the API key, with HTTP
+	// status 401.
+	ErrUnauthorized = errors.New("Honeycomb rejected the API key")
+	// ErrRateLimited reports that Honeycomb is rate limiting the API key or
+	// dataset, with HTTP status 429. The TransmissionError's RetryAfter may
+	// say how long to wait.
+	ErrRateLimited = errors.New("Honeycomb is rate limiting events")
+	// ErrPayloadTooLarge reports that an event or batch of events was too
+	// large for Honeycomb to accept, with HTTP status 413, or that libhoney
+	// refused to send an event exceeding Honeycomb's limit on event size.
+	ErrPayloadTooLarge = errors.New("payload too large for Honeycomb")
+	// ErrQueueOverflow reports that libhoney dropped an event because its
+	// queue of events awaiting transmission was full.
+	ErrQueueOverflow = errors.New("transmission queue overflow")
+)
+
+// Messages of the errors libhoney reports without HTTP statuses.
+const (
+	queueOverflowMessage = "queue overflow"
+	eventTooLargeMessage = "exceeds max event size"
+)
+
+// TransmissionError reports that Honeycomb didn't accept an event, for the
+// reason given by one of ErrUnauthorized, ErrRateLimited, ErrPayloadTooLarge,
+// and ErrQueueOverflow, which it matches with errors.Is.
+type TransmissionError struct {
+	// Reason is the sentinel error identifying the failure.
+	Reason error
+	// StatusCode is the HTTP status with which Honeycomb responded, or zero
+	// if the event wasn't sent.
+	StatusCode int
+	// RetryAfter is how long Honeycomb asked clients to wait before sending
+	// more events, with the Retry-After header of its most recent rate
+	// limiting response, if known.
+	RetryAfter time.Duration
+	// Err is the error libhoney reported, if any.
+	Err error
+}
+
+func (e *TransmissionError) Error() string {
+	msg := e.Reason.Error()
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (HTTP status %d)", e.StatusCode)
+	}
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf("; retry after %v", e.RetryAfter)
+	}
+	return msg
+}
+
+// Is reports whether target is the error's Reason.
+func (e *TransmissionError) Is(target error) bool {
+	return target == e.Reason
+}
+
+// Unwrap returns the error libhoney reported.
+func (e *TransmissionError) Unwrap() error {
+	return e.Err
+}
+
+// transmissionError returns the *TransmissionError for a failed transmission
+// response of a recognized kind, or nil.
+func transmissionError(r transmission.Response) *TransmissionError {
+	var reason error
+	switch r.StatusCode {
+	case http.StatusUnauthorized:
+		reason = ErrUnauthorized
+	case http.StatusTooManyRequests:
+		reason = ErrRateLimited
+	case http.StatusRequestEntityTooLarge:
+		reason = ErrPayloadTooLarge
+	case 0:
+		switch {
+		case r.Err == nil:
+		case r.Err.Error() == queueOverflowMessage:
+			reason = ErrQueueOverflow
+		case strings.Contains(r.Err.Error(), eventTooLargeMessage):
+			reason = ErrPayloadTooLarge
+		}
+	}
+	if reason == nil {
+		return nil
+	}
+	return &TransmissionError{Reason: reason, StatusCode: r.StatusCode, Err: r.Err}
+}
+
+// responseError returns the error for a failed transmission response to an
+// event sent to dataset, as the function responseError does, adding the delay
+// requested by the most recent rate limiting response.
+func (e *Exporter) responseError(r transmission.Response, dataset string) error {
+	err := responseError(r, dataset)
+	var te *TransmissionError
+	if e.retryAfter != nil && errors.As(err, &te) && te.Reason == ErrRateLimited {
+		te.RetryAfter = e.retryAfter.remaining()
+	}
+	return err
+}
+
+// retryAfterTransport records the delay requested by the Retry-After header
+// of the most recent rate limiting response, which libhoney doesn't report.
+type retryAfterTransport struct {
+	base http.RoundTripper
+	// until is the time, in nanoseconds since the Unix epoch, until which
+	// Honeycomb asked clients to wait.
+	until int64
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		now := time.Now()
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+			atomic.StoreInt64(&t.until, now.Add(d).UnixNano())
+		}
+	}
+	return resp, err
+}
+
+// remaining returns the time left to wait as requested by the most recent
+// rate limiting response, or zero.
+func (t *retryAfterTransport) remaining() time.Duration {
+	until := atomic.LoadInt64(&t.until)
+	if until == 0 {
+		return 0
+	}
+	if d := time.Until(time.Unix(0, until)); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// parseRetryAfter parses the value of a Retry-After header, either a number
+// of seconds or an HTTP date, as a delay from now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
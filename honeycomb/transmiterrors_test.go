@@ -0,0 +1,92 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestTransmissionErrors(t *testing.T) {
+	failure := errors.New("got unexpected HTTP status")
+	for _, test := range []struct {
+		response transmission.Response
+		want     error
+	}{
+		{transmission.Response{StatusCode: http.StatusUnauthorized, Err: failure}, ErrUnauthorized},
+		{transmission.Response{StatusCode: http.StatusTooManyRequests, Err: failure}, ErrRateLimited},
+		{transmission.Response{StatusCode: http.StatusRequestEntityTooLarge, Err: failure}, ErrPayloadTooLarge},
+		{transmission.Response{Err: errors.New("event exceeds max event size of 100000 bytes, API will not accept this event")}, ErrPayloadTooLarge},
+		{transmission.Response{Err: errors.New("queue overflow")}, ErrQueueOverflow},
+	} {
+		err := responseError(test.response, "test")
+		assert.True(t, errors.Is(err, test.want), "%v", err)
+		var te *TransmissionError
+		if assert.True(t, errors.As(err, &te)) {
+			assert.Equal(t, test.response.StatusCode, te.StatusCode)
+			assert.Equal(t, test.response.Err, errors.Unwrap(err))
+		}
+	}
+
+	assert.Equal(t, failure, responseError(transmission.Response{StatusCode: http.StatusBadRequest, Err: failure}, "test"))
+	assert.Equal(t, "Honeycomb is rate limiting events (HTTP status 429); retry after 30s",
+		(&TransmissionError{Reason: ErrRateLimited, StatusCode: 429, RetryAfter: 30 * time.Second}).Error())
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for value, want := range map[string]time.Duration{
+		"30":                            30 * time.Second,
+		" 0 ":                           0,
+		"Fri, 01 Jan 2021 00:01:00 GMT": time.Minute,
+		"Thu, 31 Dec 2020 00:00:00 GMT": 0,
+	} {
+		d, ok := parseRetryAfter(value, now)
+		assert.True(t, ok, value)
+		assert.Equal(t, want, d, value)
+	}
+	for _, value := range []string{"", "-1", "soon"} {
+		_, ok := parseRetryAfter(value, now)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestRateLimitedRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	errs := make(chan error, 1)
+	exporter, err := NewExporter(Config{APIKey: "overridden"},
+		WithAPIURL(server.URL),
+		CallingOnError(func(err error) {
+			errs <- err
+		}))
+	if !assert.Nil(err) {
+		return
+	}
+	defer exporter.Shutdown(context.Background())
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "limited"}}))
+	assert.Nil(exporter.ForceFlush(context.Background()))
+
+	select {
+	case err := <-errs:
+		var te *TransmissionError
+		if assert.True(errors.As(err, &te), "%v", err) {
+			assert.Equal(ErrRateLimited, te.Reason)
+			assert.InDelta(float64(30*time.Second), float64(te.RetryAfter), float64(time.Second))
+		}
+	default:
+		t.Fatal("expected an error")
+	}
+}
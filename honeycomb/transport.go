@@ -0,0 +1,176 @@
+package honeycomb
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+	"github.com/honeycombio/libhoney-go/transmission"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// WithRoundTripper causes the exporter to make its HTTP requests to Honeycomb
+// through rt, such as a transport presenting client certificates for mutual
+// TLS or one signing requests for an egress gateway. Diagnose and
+// WithDatasetPreflight use it as well. To use the transport of an
+// http.Client, pass its Transport field; the exporter imposes its own timeout
+// on requests.
+func WithRoundTripper(rt http.RoundTripper) ExporterOption {
+	return func(c *exporterConfig) error {
+		if rt == nil {
+			return errors.New("round tripper must not be nil")
+		}
+		c.transport = rt
+		return nil
+	}
+}
+
+// WithProxyURL causes the exporter to make its HTTP requests to Honeycomb
+// through the proxy at proxyURL, such as "http://proxy.example.com:3128,"
+// except for requests to hosts excluded by the NO_PROXY environment
+// variable. Without it, the exporter uses the proxy given by the HTTPS_PROXY
+// environment variable, if any, as Go's default transport does. It can't be
+// combined with WithRoundTripper, whose transport chooses its own proxy.
+func WithProxyURL(proxyURL string) ExporterOption {
+	return func(c *exporterConfig) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("proxy URL %q must use the http, https, or socks5 scheme", proxyURL)
+		}
+		if len(u.Host) == 0 {
+			return fmt.Errorf("proxy URL %q must include a host", proxyURL)
+		}
+		c.proxyURL = u
+		return nil
+	}
+}
+
+// WithTLSConfig causes the exporter to use config for its TLS connections to
+// Honeycomb, such as when its traffic passes through a TLS-intercepting
+// gateway, or goes to a Refinery server with a certificate issued by an
+// internal certificate authority. WithCACertificates and
+// WithClientCertificate add to config without modifying it. It can't be
+// combined with WithRoundTripper, whose transport makes its own connections.
+func WithTLSConfig(config *tls.Config) ExporterOption {
+	return func(c *exporterConfig) error {
+		if config == nil {
+			return errors.New("TLS configuration must not be nil")
+		}
+		c.tlsConfig = config
+		return nil
+	}
+}
+
+// WithCACertificates causes the exporter to trust only the certificate
+// authorities whose PEM-encoded certificates are in the file at path, rather
+// than those trusted by the host, to verify the certificates of the servers
+// to which it connects. It can't be combined with WithRoundTripper.
+func WithCACertificates(path string) ExporterOption {
+	return func(c *exporterConfig) error {
+		pem, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading CA certificates: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no CA certificates found in %s", path)
+		}
+		c.rootCAs = pool
+		return nil
+	}
+}
+
+// WithClientCertificate causes the exporter to present the certificate and
+// private key in the PEM-encoded files at certFile and keyFile to the servers
+// to which it connects, for mutual TLS. It can't be combined with
+// WithRoundTripper.
+func WithClientCertificate(certFile, keyFile string) ExporterOption {
+	return func(c *exporterConfig) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("loading client certificate: %w", err)
+		}
+		c.clientCerts = append(c.clientCerts, cert)
+		return nil
+	}
+}
+
+// customTLS reports whether the exporter's TLS connections are configured by
+// WithTLSConfig, WithCACertificates, or WithClientCertificate.
+func (c *exporterConfig) customTLS() bool {
+	return c.tlsConfig != nil || c.rootCAs != nil || len(c.clientCerts) != 0
+}
+
+// clientTLSConfig returns the configuration of the exporter's TLS
+// connections, given customTLS.
+func (c *exporterConfig) clientTLSConfig() *tls.Config {
+	config := &tls.Config{}
+	if c.tlsConfig != nil {
+		config = c.tlsConfig.Clone()
+	}
+	if c.rootCAs != nil {
+		config.RootCAs = c.rootCAs
+	}
+	if len(c.clientCerts) != 0 {
+		certs := append([]tls.Certificate(nil), config.Certificates...)
+		config.Certificates = append(certs, c.clientCerts...)
+	}
+	return config
+}
+
+// roundTripper returns the transport through which to make HTTP requests to
+// Honeycomb.
+func (c *exporterConfig) roundTripper() http.RoundTripper {
+	if c.transport != nil {
+		return c.transport
+	}
+	if c.proxyURL == nil && !c.customTLS() {
+		return http.DefaultTransport
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.proxyURL != nil {
+		transport.Proxy = c.proxy()
+	}
+	if c.customTLS() {
+		transport.TLSClientConfig = c.clientTLSConfig()
+	}
+	return transport
+}
+
+// proxy returns the function choosing the proxy for each request, given
+// WithProxyURL.
+func (c *exporterConfig) proxy() func(*http.Request) (*url.URL, error) {
+	config := httpproxy.Config{
+		HTTPProxy:  c.proxyURL.String(),
+		HTTPSProxy: c.proxyURL.String(),
+		NoProxy:    httpproxy.FromEnvironment().NoProxy,
+	}
+	proxyForURL := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyForURL(req.URL)
+	}
+}
+
+// newTransmission returns libhoney's default transmission, making its HTTP
+// requests through transport.
+func newTransmission(transport http.RoundTripper, logger libhoney.Logger) transmission.Sender {
+	return &transmission.Honeycomb{
+		MaxBatchSize:         libhoney.DefaultMaxBatchSize,
+		BatchTimeout:         libhoney.DefaultBatchTimeout,
+		MaxConcurrentBatches: libhoney.DefaultMaxConcurrentBatches,
+		PendingWorkCapacity:  libhoney.DefaultPendingWorkCapacity,
+		UserAgentAddition:    libhoney.UserAgentAddition,
+		Logger:               logger,
+		Transport:            transport,
+	}
+}
@@ -0,0 +1,157 @@
+package honeycomb
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// recordingTransport records the paths of the requests made through it.
+type recordingTransport struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.paths = append(t.paths, req.URL.Path)
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithRoundTripper(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[{"status": 202}]`)
+	}))
+	defer server.Close()
+
+	transport := &recordingTransport{}
+	exporter, err := NewExporter(Config{APIKey: "overridden"},
+		TargetingDataset("test"),
+		WithAPIURL(server.URL),
+		WithRoundTripper(transport))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "sent"}}))
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.Equal([]string{"/1/batch/test"}, transport.paths)
+
+	transport.paths = nil
+	_, err = Diagnose(context.Background(), Config{APIKey: "overridden"},
+		WithAPIURL(server.URL),
+		WithRoundTripper(transport))
+	assert.Nil(err)
+	assert.Equal([]string{"/1/auth"}, transport.paths)
+
+	_, err = NewExporter(Config{APIKey: "overridden"}, WithRoundTripper(nil))
+	assert.Error(err)
+}
+
+func TestWithProxyURL(t *testing.T) {
+	assert := assert.New(t)
+	setEnv(t, "NO_PROXY", "internal.example.com")
+	setEnv(t, "no_proxy", "")
+
+	var econf exporterConfig
+	assert.Nil(WithProxyURL("http://proxy.example.com:3128")(&econf))
+	transport, ok := econf.roundTripper().(*http.Transport)
+	if !assert.True(ok) {
+		return
+	}
+	for target, want := range map[string]string{
+		"https://api.honeycomb.io/1/batch/test":          "http://proxy.example.com:3128",
+		"https://internal.example.com/1/batch/test":      "",
+		"http://refinery.internal.example.com/1/batch/x": "",
+	} {
+		req, _ := http.NewRequest(http.MethodPost, target, nil)
+		proxy, err := transport.Proxy(req)
+		assert.Nil(err)
+		if len(want) == 0 {
+			assert.Nil(proxy, target)
+		} else if assert.NotNil(proxy, target) {
+			assert.Equal(want, proxy.String())
+		}
+	}
+
+	for _, proxyURL := range []string{"proxy.example.com:3128", "ftp://proxy.example.com", "http://"} {
+		assert.Error(WithProxyURL(proxyURL)(&econf), proxyURL)
+	}
+	_, err := NewExporter(Config{APIKey: "overridden"},
+		WithProxyURL("http://proxy.example.com:3128"),
+		WithRoundTripper(http.DefaultTransport))
+	assert.Error(err)
+}
+
+func TestTLSOptions(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	// The server's own certificate serves as the CA and client certificates.
+	dir, err := ioutil.TempDir("", "honeycomb-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cert := server.TLS.Certificates[0]
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	for file, block := range map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: cert.Certificate[0]},
+		keyFile:  {Type: "PRIVATE KEY", Bytes: key},
+	} {
+		if err := ioutil.WriteFile(file, pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reachable := func(opts ...ExporterOption) bool {
+		d, err := Diagnose(context.Background(), Config{APIKey: "overridden"}, append(opts, WithAPIURL(server.URL))...)
+		if !assert.Nil(err) || !assert.NotEmpty(d.Checks) {
+			return false
+		}
+		return d.Checks[0].Err == nil
+	}
+	assert.False(reachable(), "the server's certificate isn't trusted")
+	assert.False(reachable(WithCACertificates(certFile)), "the server requires a client certificate")
+	assert.True(reachable(WithCACertificates(certFile), WithClientCertificate(certFile, keyFile)))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	config := &tls.Config{RootCAs: pool}
+	assert.True(reachable(WithTLSConfig(config), WithClientCertificate(certFile, keyFile)))
+	assert.Empty(config.Certificates, "the given configuration is left unmodified")
+
+	var econf exporterConfig
+	assert.Error(WithCACertificates(keyFile)(&econf))
+	assert.Error(WithClientCertificate(certFile, filepath.Join(dir, "missing.pem"))(&econf))
+	assert.Error(WithTLSConfig(nil)(&econf))
+	_, err = NewExporter(Config{APIKey: "overridden"},
+		WithTLSConfig(config),
+		WithRoundTripper(http.DefaultTransport))
+	assert.Error(err)
+}
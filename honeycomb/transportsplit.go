@@ -0,0 +1,140 @@
+package honeycomb
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// WithTransportSplit causes the exporter to hand the spans of the given
+// percentage of traces to alternate, another exporter, such as an OTLP
+// exporter sending to Honeycomb's OTLP endpoint, while sending the rest as
+// events as usual. This allows migrating from one transport to another
+// gradually under production load, raising the percentage as confidence in
+// the alternate transport grows. Whether a trace takes the alternate path
+// depends only on its trace ID, so every span of a trace takes the same
+// path, in every service configured with the same percentage, and the
+// traces taking it at one percentage still take it at higher percentages.
+//
+// The exporter counts the spans each path accepts and fails to accept,
+// reporting the counts from TransportSplitStats. It reports the failures of
+// the alternate exporter to the error hook, and shuts it down when shut
+// down itself.
+func WithTransportSplit(percent float64, alternate trace.SpanExporter) ExporterOption {
+	return func(c *exporterConfig) error {
+		if math.IsNaN(percent) || percent < 0 || percent > 100 {
+			return fmt.Errorf("transport split percentage must be between 0 and 100, not %v", percent)
+		}
+		if alternate == nil {
+			return errors.New("alternate exporter must not be nil")
+		}
+		c.transportSplit = &transportSplit{
+			bound:     uint64(percent / 100 * math.MaxUint64),
+			all:       percent == 100,
+			alternate: alternate,
+		}
+		return nil
+	}
+}
+
+// TransportPathStats counts the spans given to one of the paths configured
+// by WithTransportSplit.
+type TransportPathStats struct {
+	// Accepted is the number of spans the path accepted.
+	Accepted uint64
+	// Failed is the number of spans the path failed to accept.
+	Failed uint64
+}
+
+// TransportSplitStats counts the spans given to each of the paths configured
+// by WithTransportSplit.
+type TransportSplitStats struct {
+	// Events counts the spans sent as events.
+	Events TransportPathStats
+	// Alternate counts the spans handed to the alternate exporter.
+	Alternate TransportPathStats
+}
+
+// transportSplit divides spans between the exporter's own path and an
+// alternate exporter.
+type transportSplit struct {
+	// The counts come first so that they're 64-bit aligned, as the atomic
+	// operations on them require on 32-bit platforms.
+	eventsAccepted    uint64
+	eventsFailed      uint64
+	alternateAccepted uint64
+	alternateFailed   uint64
+
+	// bound is the value below which the low half of a trace ID routes the
+	// trace to the alternate exporter, unless all is set.
+	bound     uint64
+	all       bool
+	alternate trace.SpanExporter
+}
+
+// takesAlternate reports whether the trace with the given ID takes the
+// alternate path. It uses the low half of the ID, leaving the decision
+// independent of those of samplers using the high half.
+func (s *transportSplit) takesAlternate(id apitrace.TraceID) bool {
+	return s.all || binary.BigEndian.Uint64(id[8:]) < s.bound
+}
+
+// divide separates the spans taking the alternate path from the others.
+func (s *transportSplit) divide(spans []*trace.SpanSnapshot) (own, alternate []*trace.SpanSnapshot) {
+	for _, span := range spans {
+		if s.takesAlternate(span.SpanContext.TraceID) {
+			alternate = append(alternate, span)
+		} else {
+			own = append(own, span)
+		}
+	}
+	return own, alternate
+}
+
+// exportAlternate hands spans to the alternate exporter, reporting any
+// failure to onError as well as returning it.
+func (s *transportSplit) exportAlternate(ctx context.Context, spans []*trace.SpanSnapshot, onError func(error)) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	if err := s.alternate.ExportSpans(ctx, spans); err != nil {
+		atomic.AddUint64(&s.alternateFailed, uint64(len(spans)))
+		err = fmt.Errorf("alternate transport: %w", err)
+		onError(err)
+		return err
+	}
+	atomic.AddUint64(&s.alternateAccepted, uint64(len(spans)))
+	return nil
+}
+
+// recordEvents counts the outcome of exporting spans as events.
+func (s *transportSplit) recordEvents(result ExportResult) {
+	atomic.AddUint64(&s.eventsAccepted, uint64(result.Accepted))
+	atomic.AddUint64(&s.eventsFailed, uint64(len(result.Failed)))
+}
+
+// TransportSplitStats counts the spans given to each of the paths configured
+// by WithTransportSplit. It returns the zero value if the exporter isn't
+// configured with WithTransportSplit.
+func (e *Exporter) TransportSplitStats() TransportSplitStats {
+	s := e.transportSplit
+	if s == nil {
+		return TransportSplitStats{}
+	}
+	return TransportSplitStats{
+		Events: TransportPathStats{
+			Accepted: atomic.LoadUint64(&s.eventsAccepted),
+			Failed:   atomic.LoadUint64(&s.eventsFailed),
+		},
+		Alternate: TransportPathStats{
+			Accepted: atomic.LoadUint64(&s.alternateAccepted),
+			Failed:   atomic.LoadUint64(&s.alternateFailed),
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// recordingExporter records the spans it's given, failing if err is set.
+type recordingExporter struct {
+	spans    []*trace.SpanSnapshot
+	err      error
+	shutdown bool
+}
+
+func (r *recordingExporter) ExportSpans(ctx context.Context, sds []*trace.SpanSnapshot) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.spans = append(r.spans, sds...)
+	return nil
+}
+
+func (r *recordingExporter) Shutdown(ctx context.Context) error {
+	r.shutdown = true
+	return nil
+}
+
+func TestTransportSplit(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	alternate := &recordingExporter{}
+	exporter, err := makeTestExporter(mockHoneycomb, WithTransportSplit(25, alternate), CallingOnError(func(error) {}))
+	assert.Nil(err)
+
+	span := func(low byte, id byte) *trace.SpanSnapshot {
+		var traceID apitrace.TraceID
+		traceID[0] = 0xff
+		traceID[8] = low
+		return &trace.SpanSnapshot{
+			SpanContext: apitrace.SpanContext{TraceID: traceID, SpanID: apitrace.SpanID{id}},
+			Name:        "span",
+		}
+	}
+	// The low halves of the first two trace IDs fall in the lowest quarter.
+	sds := []*trace.SpanSnapshot{span(0x10, 1), span(0x3f, 2), span(0x40, 3), span(0x10, 4), span(0xc0, 5)}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Equal([]*trace.SpanSnapshot{sds[0], sds[1], sds[3]}, alternate.spans)
+	assert.Len(mockHoneycomb.Events(), 2)
+
+	alternate.err = errors.New("unavailable")
+	err = exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{span(0x10, 6), span(0x80, 7)})
+	var exportErr *ExportError
+	if assert.True(errors.As(err, &exportErr)) {
+		assert.Equal(1, exportErr.Accepted)
+		assert.Len(exportErr.Failed, 1)
+	}
+	assert.Equal(TransportSplitStats{
+		Events:    TransportPathStats{Accepted: 3},
+		Alternate: TransportPathStats{Accepted: 3, Failed: 1},
+	}, exporter.TransportSplitStats())
+
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.True(alternate.shutdown)
+
+	_, err = makeTestExporter(mockHoneycomb, WithTransportSplit(101, alternate))
+	assert.Error(err)
+	_, err = makeTestExporter(mockHoneycomb, WithTransportSplit(50, nil))
+	assert.Error(err)
+}
@@ -0,0 +1,150 @@
+package honeycomb
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// maxTruncatedSpanNames is the number of span names a TruncationReport
+// lists.
+const maxTruncatedSpanNames = 10
+
+// TruncationCount describes the spans that dropped one kind of item, such as
+// attributes, because the SDK limits the number of such items per span.
+type TruncationCount struct {
+	// Spans is the number of spans that dropped items.
+	Spans int
+	// Dropped is the total number of items those spans dropped.
+	Dropped int
+	// Kept is the largest number of items any of those spans kept, which is
+	// the limit configured in the SDK.
+	Kept int
+}
+
+// TruncationReport summarizes the spans that the SDK truncated during one
+// reporting interval.
+type TruncationReport struct {
+	// Attributes, Events, and Links describe the spans that dropped
+	// attributes, span events, and links, respectively.
+	Attributes TruncationCount
+	Events     TruncationCount
+	Links      TruncationCount
+	// SpanNames holds some of the names of the truncated spans, sorted.
+	SpanNames []string
+}
+
+// String describes the report as a warning.
+func (r TruncationReport) String() string {
+	var parts []string
+	describe := func(c TruncationCount, items, setting string) {
+		if c.Spans != 0 {
+			parts = append(parts, fmt.Sprintf("%d spans dropped %d %s beyond the limit of %d (sdktrace.Config.%s)",
+				c.Spans, c.Dropped, items, c.Kept, setting))
+		}
+	}
+	describe(r.Attributes, "attributes", "MaxAttributesPerSpan")
+	describe(r.Events, "events", "MaxEventsPerSpan")
+	describe(r.Links, "links", "MaxLinksPerSpan")
+	return fmt.Sprintf("Honeycomb exporter: %s; spans affected include %s",
+		strings.Join(parts, ", "), strings.Join(r.SpanNames, ", "))
+}
+
+// truncationAdvisor accumulates signs of truncation in the spans passing
+// through the exporter and reports them periodically.
+type truncationAdvisor struct {
+	interval time.Duration
+	report   func(TruncationReport)
+
+	mu      sync.Mutex
+	current TruncationReport
+	names   map[string]struct{}
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newTruncationAdvisor(interval time.Duration, report func(TruncationReport)) *truncationAdvisor {
+	if report == nil {
+		report = func(r TruncationReport) {
+			log.Print(r)
+		}
+	}
+	return &truncationAdvisor{
+		interval: interval,
+		report:   report,
+		names:    make(map[string]struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func countTruncation(c *TruncationCount, dropped, kept int) bool {
+	if dropped <= 0 {
+		return false
+	}
+	c.Spans++
+	c.Dropped += dropped
+	if kept > c.Kept {
+		c.Kept = kept
+	}
+	return true
+}
+
+func (a *truncationAdvisor) record(data *trace.SpanSnapshot) {
+	if data.DroppedAttributeCount <= 0 && data.DroppedMessageEventCount <= 0 && data.DroppedLinkCount <= 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	truncated := countTruncation(&a.current.Attributes, data.DroppedAttributeCount, len(data.Attributes))
+	truncated = countTruncation(&a.current.Events, data.DroppedMessageEventCount, len(data.MessageEvents)) || truncated
+	truncated = countTruncation(&a.current.Links, data.DroppedLinkCount, len(data.Links)) || truncated
+	if truncated && len(a.names) < maxTruncatedSpanNames {
+		a.names[data.Name] = struct{}{}
+	}
+}
+
+// flush reports the truncation recorded since the last report, if any.
+func (a *truncationAdvisor) flush() {
+	a.mu.Lock()
+	if len(a.names) == 0 {
+		a.mu.Unlock()
+		return
+	}
+	r := a.current
+	for name := range a.names {
+		r.SpanNames = append(r.SpanNames, name)
+	}
+	a.current = TruncationReport{}
+	a.names = make(map[string]struct{})
+	a.mu.Unlock()
+
+	sort.Strings(r.SpanNames)
+	a.report(r)
+}
+
+func (a *truncationAdvisor) run() {
+	defer close(a.done)
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.flush()
+		case <-a.stop:
+			a.flush()
+			return
+		}
+	}
+}
+
+func (a *truncationAdvisor) close() {
+	close(a.stop)
+	<-a.done
+}
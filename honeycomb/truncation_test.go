@@ -0,0 +1,61 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestHoneycombTruncationAdvisory(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	var reports []TruncationReport
+	exporter, err := makeTestExporter(mockHoneycomb,
+		WithTruncationAdvisory(time.Hour, func(r TruncationReport) {
+			reports = append(reports, r)
+		}))
+	assert.Nil(err)
+
+	tr, err := setUpTestProvider(exporter,
+		sdktrace.WithConfig(sdktrace.Config{
+			DefaultSampler:       sdktrace.AlwaysSample(),
+			MaxAttributesPerSpan: 2,
+			MaxEventsPerSpan:     128,
+			MaxLinksPerSpan:      128,
+		}))
+	assert.Nil(err)
+
+	_, span := tr.Start(context.TODO(), "wide")
+	span.SetAttributes(label.Int("a", 1), label.Int("b", 2), label.Int("c", 3), label.Int("d", 4))
+	span.End()
+	_, span = tr.Start(context.TODO(), "narrow")
+	span.SetAttributes(label.Int("a", 1))
+	span.End()
+
+	assert.Nil(exporter.Shutdown(context.Background()))
+	assert.Equal([]TruncationReport{{
+		Attributes: TruncationCount{Spans: 1, Dropped: 2, Kept: 2},
+		SpanNames:  []string{"wide"},
+	}}, reports)
+
+	_, err = makeTestExporter(mockHoneycomb, WithTruncationAdvisory(0, nil))
+	assert.Error(err)
+}
+
+func TestTruncationReportString(t *testing.T) {
+	r := TruncationReport{
+		Attributes: TruncationCount{Spans: 3, Dropped: 7, Kept: 128},
+		Links:      TruncationCount{Spans: 1, Dropped: 1, Kept: 32},
+		SpanNames:  []string{"GET /a", "batch"},
+	}
+	assert.Equal(t, "Honeycomb exporter: 3 spans dropped 7 attributes beyond the limit of 128 (sdktrace.Config.MaxAttributesPerSpan), "+
+		"1 spans dropped 1 links beyond the limit of 32 (sdktrace.Config.MaxLinksPerSpan); "+
+		"spans affected include GET /a, batch", r.String())
+}
@@ -0,0 +1,96 @@
+package honeycomb
+
+import (
+	"net/url"
+	"strings"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// urlFields are the fields that may hold a URL or request target with a query
+// string, and queryFields those that hold only a query string.
+var (
+	urlFields   = []string{"http.url", "url.full", "http.target", "request.url"}
+	queryFields = []string{"url.query", "request.query"}
+)
+
+// queryScrubber replaces the values of the query string parameters not named
+// in allowed with mask.
+type queryScrubber struct {
+	mask    string
+	allowed map[string]struct{}
+}
+
+// scrubQuery scrubs a query string, preserving the order and encoding of its
+// parameters.
+func (s *queryScrubber) scrubQuery(query string) string {
+	if len(query) == 0 {
+		return query
+	}
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		eq := strings.IndexByte(param, '=')
+		if eq < 0 {
+			continue
+		}
+		name, err := url.QueryUnescape(param[:eq])
+		if err != nil {
+			name = param[:eq]
+		}
+		if _, ok := s.allowed[name]; ok {
+			continue
+		}
+		params[i] = param[:eq+1] + s.mask
+	}
+	return strings.Join(params, "&")
+}
+
+// scrubURL scrubs the query string of a URL or request target, leaving any
+// fragment intact.
+func (s *queryScrubber) scrubURL(u string) string {
+	start := strings.IndexByte(u, '?')
+	if start < 0 {
+		return u
+	}
+	end := len(u)
+	if i := strings.IndexByte(u[start:], '#'); i >= 0 {
+		end = start + i
+	}
+	return u[:start+1] + s.scrubQuery(u[start+1:end]) + u[end:]
+}
+
+func (s *queryScrubber) transform(ev *libhoney.Event, _ *trace.SpanSnapshot) {
+	fields := ev.Fields()
+	for _, name := range urlFields {
+		if u, ok := fields[name].(string); ok {
+			ev.AddField(name, s.scrubURL(u))
+		}
+	}
+	for _, name := range queryFields {
+		if q, ok := fields[name].(string); ok {
+			ev.AddField(name, s.scrubQuery(q))
+		}
+	}
+}
+
+// WithURLQueryScrubbing causes the exporter to replace the values of query
+// string parameters in the URLs it sends, in the "http.url," "url.full,"
+// "http.target," "url.query," "request.url," and "request.query" fields, with
+// mask, such as "REDACTED." If mask is empty, the values are removed,
+// leaving only the parameter names. The values of the parameters named in
+// allowed, which are known not to carry sensitive data, are sent intact.
+func WithURLQueryScrubbing(mask string, allowed ...string) ExporterOption {
+	return func(c *exporterConfig) error {
+		s := &queryScrubber{
+			mask:    url.QueryEscape(mask),
+			allowed: make(map[string]struct{}, len(allowed)),
+		}
+		for _, name := range allowed {
+			s.allowed[name] = struct{}{}
+		}
+		c.processors = append(c.processors, transformProcessor(s.transform))
+		return nil
+	}
+}
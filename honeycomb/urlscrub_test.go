@@ -0,0 +1,55 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/label"
+)
+
+func TestQueryScrubberScrubURL(t *testing.T) {
+	s := &queryScrubber{
+		mask:    "REDACTED",
+		allowed: map[string]struct{}{"page": {}, "sort by": {}},
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://example.com/search", "https://example.com/search"},
+		{"https://example.com/search?", "https://example.com/search?"},
+		{"/login?token=abc123&page=2", "/login?token=REDACTED&page=2"},
+		{"/users?email=jo%40example.com&sort+by=name&flag", "/users?email=REDACTED&sort+by=name&flag"},
+		{"/cb?code=xyz#section", "/cb?code=REDACTED#section"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, s.scrubURL(test.in), test.in)
+	}
+}
+
+func TestHoneycombOutputWithURLQueryScrubbing(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	tr, err := setUpTestExporter(mockHoneycomb,
+		WithHTTPFieldNormalization(),
+		WithURLQueryScrubbing("", "q"))
+	assert.Nil(err)
+
+	_, span := tr.Start(context.TODO(), "myTestSpan")
+	span.SetAttributes(
+		label.String("http.url", "https://example.com/search?q=shoes&api_key=s3cret"),
+		label.String("http.target", "/search?q=shoes&api_key=s3cret"),
+	)
+	span.End()
+
+	assert.Len(mockHoneycomb.Events(), 1)
+	fields := mockHoneycomb.Events()[0].Data
+	assert.Equal("https://example.com/search?q=shoes&api_key=", fields["http.url"])
+	assert.Equal("https://example.com/search?q=shoes&api_key=", fields["request.url"])
+	assert.Equal("/search?q=shoes&api_key=", fields["http.target"])
+	assert.Equal("q=shoes&api_key=", fields["request.query"])
+}
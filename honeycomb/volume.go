@@ -0,0 +1,64 @@
+package honeycomb
+
+import (
+	"sync"
+)
+
+// DatasetVolume describes the events an exporter has sent to one dataset.
+type DatasetVolume struct {
+	// Events is the number of events queued for transmission.
+	Events uint64
+	// Bytes is the total size of those events once serialized.
+	Bytes uint64
+}
+
+// WithVolumeAccounting causes the exporter to count the events it sends to
+// each dataset, along with their sizes, for reporting by DatasetVolumes. This
+// helps attribute the cost of Honeycomb usage to the services sharing one
+// exporter, such as those sending spans through cmd/hcagent.
+func WithVolumeAccounting() ExporterOption {
+	return func(c *exporterConfig) error {
+		c.volumeAccounting = true
+		return nil
+	}
+}
+
+// volumeAccountant counts the events sent to each dataset.
+type volumeAccountant struct {
+	mu      sync.Mutex
+	volumes map[string]DatasetVolume
+}
+
+func newVolumeAccountant() *volumeAccountant {
+	return &volumeAccountant{volumes: make(map[string]DatasetVolume)}
+}
+
+// record counts an event of the given serialized size sent to dataset.
+func (a *volumeAccountant) record(dataset string, size int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	v := a.volumes[dataset]
+	v.Events++
+	v.Bytes += uint64(size)
+	a.volumes[dataset] = v
+}
+
+func (a *volumeAccountant) snapshot() map[string]DatasetVolume {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	volumes := make(map[string]DatasetVolume, len(a.volumes))
+	for dataset, v := range a.volumes {
+		volumes[dataset] = v
+	}
+	return volumes
+}
+
+// DatasetVolumes reports the events sent to each dataset since the exporter
+// was created, keyed by dataset name, when the exporter is configured with
+// WithVolumeAccounting. It returns nil otherwise.
+func (e *Exporter) DatasetVolumes() map[string]DatasetVolume {
+	if e.volumes == nil {
+		return nil
+	}
+	return e.volumes.snapshot()
+}
@@ -0,0 +1,54 @@
+package honeycomb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+func TestHoneycombDatasetVolumes(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb, WithVolumeAccounting(), WithErrorsDataset("errors", true))
+	assert.Nil(err)
+	assert.Empty(exporter.DatasetVolumes())
+
+	sds := []*trace.SpanSnapshot{{Name: "ok"}, {Name: "failed", StatusCode: codes.Error}}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	volumes := exporter.DatasetVolumes()
+	assert.Len(volumes, 2)
+	assert.Equal(uint64(2), volumes["test"].Events)
+	assert.Equal(uint64(1), volumes["errors"].Events)
+	assert.True(volumes["test"].Bytes > volumes["errors"].Bytes)
+	assert.Equal(volumes, exporter.Stats().DatasetVolumes)
+
+	unaccounted, err := makeTestExporter(&transmission.MockSender{})
+	assert.Nil(err)
+	assert.Nil(unaccounted.DatasetVolumes())
+	assert.Nil(unaccounted.Stats().DatasetVolumes)
+}
+
+func TestHoneycombDatasetVolumesWithOversizedEventPolicy(t *testing.T) {
+	mockHoneycomb := &transmission.MockSender{}
+	assert := assert.New(t)
+
+	exporter, err := makeTestExporter(mockHoneycomb, WithVolumeAccounting(), WithOversizedEventPolicy(DropOversizedEvents))
+	assert.Nil(err)
+	sds := []*trace.SpanSnapshot{{Name: "first"}, {Name: "second"}}
+	assert.Nil(exporter.ExportSpans(context.Background(), sds))
+	assert.Nil(exporter.Shutdown(context.Background()))
+
+	var size uint64
+	for _, ev := range mockHoneycomb.Events() {
+		size += uint64(serializedSize(ev.Data))
+	}
+	assert.Equal(DatasetVolume{Events: 2, Bytes: size}, exporter.DatasetVolumes()["test"])
+}
@@ -0,0 +1,121 @@
+// Package honeycombprom exposes the counters and queue gauges of a Honeycomb
+// exporter as Prometheus metrics, for applications monitored only by
+// Prometheus. It is a module of its own, so that the exporter doesn't depend
+// on the Prometheus client.
+package honeycombprom
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/honeycombio/opentelemetry-exporter-go/honeycomb"
+)
+
+const namespace = "honeycomb_exporter"
+
+// collector collects the metrics of one exporter.
+type collector struct {
+	exporter *honeycomb.Exporter
+
+	events         *prometheus.Desc
+	transmitDepth  *prometheus.Desc
+	exportDepth    *prometheus.Desc
+	exportCapacity *prometheus.Desc
+	exportDropped  *prometheus.Desc
+	shortDropped   *prometheus.Desc
+	truncated      *prometheus.Desc
+	circuitState   *prometheus.Desc
+	datasetEvents  *prometheus.Desc
+	datasetBytes   *prometheus.Desc
+}
+
+// NewCollector returns a prometheus.Collector reporting the state of
+// exporter, with the given constant labels, which may be nil:
+//
+// - "honeycomb_exporter_events_total," counting the events handed to
+// libhoney for transmission, labeled with an "outcome" of "enqueued,"
+// "sent," "failed," or "dropped," as reported by TransmissionStats;
+//
+// - "honeycomb_exporter_transmission_queue_depth," the number of events
+// queued or being sent;
+//
+// - "honeycomb_exporter_export_queue_depth," "..._export_queue_capacity,"
+// and "..._export_queue_dropped_total," describing the queue used with
+// WithAsyncExport, as reported by QueueStats;
+//
+// - "honeycomb_exporter_short_spans_dropped_total" and
+// "honeycomb_exporter_truncated_trace_spans_dropped_total," counting the
+// spans dropped by WithMinSpanDuration and WithMaxSpansPerTrace;
+//
+// - "honeycomb_exporter_circuit_state," the state of the circuit breaker
+// configured by WithAuthCircuitBreaker: 0 when closed, 1 when open, and 2
+// when half-open; and
+//
+// - with WithVolumeAccounting, "honeycomb_exporter_dataset_events_total" and
+// "honeycomb_exporter_dataset_bytes_total," counting the events sent to each
+// dataset and their total size, labeled with its name as "dataset," as
+// reported by DatasetVolumes.
+//
+// The outcomes of transmission are learned from libhoney's responses, so
+// they are only counted while the exporter's Start goroutine, or
+// RunErrorLogger, consumes them.
+func NewCollector(exporter *honeycomb.Exporter, constLabels prometheus.Labels) prometheus.Collector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, constLabels)
+	}
+	return &collector{
+		exporter:       exporter,
+		events:         desc("events_total", "Events handed to libhoney for transmission, by outcome.", "outcome"),
+		transmitDepth:  desc("transmission_queue_depth", "Events queued or being sent."),
+		exportDepth:    desc("export_queue_depth", "Spans waiting in the asynchronous export queue."),
+		exportCapacity: desc("export_queue_capacity", "Capacity of the asynchronous export queue."),
+		exportDropped:  desc("export_queue_dropped_total", "Spans dropped because the asynchronous export queue was full."),
+		shortDropped:   desc("short_spans_dropped_total", "Spans dropped for lasting less than the minimum span duration."),
+		truncated:      desc("truncated_trace_spans_dropped_total", "Spans dropped from traces exceeding the span limit."),
+		circuitState:   desc("circuit_state", "State of the authentication circuit breaker: 0 closed, 1 open, 2 half-open."),
+		datasetEvents:  desc("dataset_events_total", "Events sent to each dataset.", "dataset"),
+		datasetBytes:   desc("dataset_bytes_total", "Total size in bytes of the events sent to each dataset.", "dataset"),
+	}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.events
+	ch <- c.transmitDepth
+	ch <- c.exportDepth
+	ch <- c.exportCapacity
+	ch <- c.exportDropped
+	ch <- c.shortDropped
+	ch <- c.truncated
+	ch <- c.circuitState
+	ch <- c.datasetEvents
+	ch <- c.datasetBytes
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	counter := func(desc *prometheus.Desc, v uint64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(v), labels...)
+	}
+	gauge := func(desc *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v)
+	}
+
+	tx := c.exporter.TransmissionStats()
+	counter(c.events, tx.Enqueued, "enqueued")
+	counter(c.events, tx.Sent, "sent")
+	counter(c.events, tx.Failed, "failed")
+	counter(c.events, tx.Dropped, "dropped")
+	gauge(c.transmitDepth, float64(tx.Depth))
+
+	queue := c.exporter.QueueStats()
+	gauge(c.exportDepth, float64(queue.Depth))
+	gauge(c.exportCapacity, float64(queue.Capacity))
+	counter(c.exportDropped, queue.Dropped)
+
+	counter(c.shortDropped, c.exporter.ShortSpansDropped())
+	counter(c.truncated, c.exporter.TruncatedTraceSpansDropped())
+	gauge(c.circuitState, float64(c.exporter.CircuitState()))
+
+	for dataset, v := range c.exporter.DatasetVolumes() {
+		counter(c.datasetEvents, v.Events, dataset)
+		counter(c.datasetBytes, v.Bytes, dataset)
+	}
+}
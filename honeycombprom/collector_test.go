@@ -0,0 +1,60 @@
+package honeycombprom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/sdk/export/trace"
+
+	"github.com/honeycombio/opentelemetry-exporter-go/honeycomb"
+)
+
+func TestCollector(t *testing.T) {
+	assert := assert.New(t)
+	mockHoneycomb := &transmission.MockSender{}
+	exporter, err := honeycomb.NewExporter(honeycomb.Config{APIKey: "overridden"},
+		honeycomb.TargetingDataset("test"),
+		honeycomb.WithSender(mockHoneycomb),
+		honeycomb.WithVolumeAccounting())
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Nil(exporter.ExportSpans(context.Background(), []*trace.SpanSnapshot{{Name: "first"}, {Name: "second"}}))
+
+	collector := NewCollector(exporter, map[string]string{"service": "test"})
+	expected := `
+# HELP honeycomb_exporter_events_total Events handed to libhoney for transmission, by outcome.
+# TYPE honeycomb_exporter_events_total counter
+honeycomb_exporter_events_total{outcome="dropped",service="test"} 0
+honeycomb_exporter_events_total{outcome="enqueued",service="test"} 2
+honeycomb_exporter_events_total{outcome="failed",service="test"} 0
+honeycomb_exporter_events_total{outcome="sent",service="test"} 0
+# HELP honeycomb_exporter_transmission_queue_depth Events queued or being sent.
+# TYPE honeycomb_exporter_transmission_queue_depth gauge
+honeycomb_exporter_transmission_queue_depth{service="test"} 2
+# HELP honeycomb_exporter_circuit_state State of the authentication circuit breaker: 0 closed, 1 open, 2 half-open.
+# TYPE honeycomb_exporter_circuit_state gauge
+honeycomb_exporter_circuit_state{service="test"} 0
+# HELP honeycomb_exporter_dataset_events_total Events sent to each dataset.
+# TYPE honeycomb_exporter_dataset_events_total counter
+honeycomb_exporter_dataset_events_total{dataset="test",service="test"} 2
+# HELP honeycomb_exporter_dataset_bytes_total Total size in bytes of the events sent to each dataset.
+# TYPE honeycomb_exporter_dataset_bytes_total counter
+honeycomb_exporter_dataset_bytes_total{dataset="test",service="test"} %d
+`
+	expected = fmt.Sprintf(expected, exporter.DatasetVolumes()["test"].Bytes)
+	assert.Nil(testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"honeycomb_exporter_events_total",
+		"honeycomb_exporter_transmission_queue_depth",
+		"honeycomb_exporter_circuit_state",
+		"honeycomb_exporter_dataset_events_total",
+		"honeycomb_exporter_dataset_bytes_total"))
+	assert.Equal(13, testutil.CollectAndCount(collector))
+	assert.Nil(exporter.Shutdown(context.Background()))
+}
@@ -0,0 +1,338 @@
+// Package otlpjson decodes the OTLP/JSON encoding of trace export requests
+// into span snapshots for the commands in this repository.
+package otlpjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// The types below mirror the subset of the OTLP/JSON trace encoding that we
+// need to reconstruct span snapshots. Both the current "scopeSpans" and the
+// older "instrumentationLibrarySpans" groupings are accepted.
+
+type otlpTracesData struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"resource"`
+	SchemaURL                   string           `json:"schemaUrl"`
+	ScopeSpans                  []otlpScopeSpans `json:"scopeSpans"`
+	InstrumentationLibrarySpans []otlpScopeSpans `json:"instrumentationLibrarySpans"`
+}
+
+type otlpScopeSpans struct {
+	SchemaURL string     `json:"schemaUrl"`
+	Spans     []otlpSpan `json:"spans"`
+}
+
+// schemaURLKey is the resource attribute through which the exporter learns
+// the schema URL of each span, matching honeycomb.SchemaURLKey.
+const schemaURLKey = label.Key("otel.schema_url")
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId"`
+	Name              string          `json:"name"`
+	Kind              json.RawMessage `json:"kind"`
+	StartTimeUnixNano json.Number     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   json.Number     `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue  `json:"attributes"`
+	Events            []struct {
+		TimeUnixNano json.Number    `json:"timeUnixNano"`
+		Name         string         `json:"name"`
+		Attributes   []otlpKeyValue `json:"attributes"`
+	} `json:"events"`
+	Links []struct {
+		TraceID    string         `json:"traceId"`
+		SpanID     string         `json:"spanId"`
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"links"`
+	Status struct {
+		Code    json.RawMessage `json:"code"`
+		Message string          `json:"message"`
+	} `json:"status"`
+	DroppedAttributesCount int `json:"droppedAttributesCount"`
+	DroppedEventsCount     int `json:"droppedEventsCount"`
+	DroppedLinksCount      int `json:"droppedLinksCount"`
+}
+
+type otlpKeyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue *string      `json:"stringValue"`
+		BoolValue   *bool        `json:"boolValue"`
+		IntValue    *json.Number `json:"intValue"`
+		DoubleValue *json.Number `json:"doubleValue"`
+	} `json:"value"`
+}
+
+// ReadJSON decodes a single OTLP/JSON document into span snapshots.
+func ReadJSON(r io.Reader) ([]*exporttrace.SpanSnapshot, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var data otlpTracesData
+	if err := dec.Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode OTLP JSON: %v", err)
+	}
+	return data.snapshots()
+}
+
+// ReadJSONLines decodes a stream with one OTLP/JSON document per line, as
+// written by the OpenTelemetry Collector's file exporter.
+func ReadJSONLines(r io.Reader) ([]*exporttrace.SpanSnapshot, error) {
+	var snapshots []*exporttrace.SpanSnapshot
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		lineSnapshots, err := ReadJSON(bytes.NewReader(line))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		snapshots = append(snapshots, lineSnapshots...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+func (d *otlpTracesData) snapshots() ([]*exporttrace.SpanSnapshot, error) {
+	var snapshots []*exporttrace.SpanSnapshot
+	for _, rs := range d.ResourceSpans {
+		attrs := convertAttributes(rs.Resource.Attributes)
+		var res *resource.Resource
+		if len(attrs) > 0 {
+			res = resource.NewWithAttributes(attrs...)
+		}
+		for _, group := range [][]otlpScopeSpans{rs.ScopeSpans, rs.InstrumentationLibrarySpans} {
+			for _, ss := range group {
+				// A scope's schema URL takes precedence over its
+				// resource's.
+				res := res
+				if schemaURL := ss.SchemaURL; len(schemaURL) != 0 || len(rs.SchemaURL) != 0 {
+					if len(schemaURL) == 0 {
+						schemaURL = rs.SchemaURL
+					}
+					res = resource.NewWithAttributes(append(attrs[:len(attrs):len(attrs)], schemaURLKey.String(schemaURL))...)
+				}
+				for i := range ss.Spans {
+					snapshot, err := ss.Spans[i].snapshot()
+					if err != nil {
+						return nil, err
+					}
+					snapshot.Resource = res
+					snapshots = append(snapshots, snapshot)
+				}
+			}
+		}
+	}
+	return snapshots, nil
+}
+
+func (s *otlpSpan) snapshot() (*exporttrace.SpanSnapshot, error) {
+	snapshot := &exporttrace.SpanSnapshot{
+		Name:                     s.Name,
+		Attributes:               convertAttributes(s.Attributes),
+		StatusMessage:            s.Status.Message,
+		DroppedAttributeCount:    s.DroppedAttributesCount,
+		DroppedMessageEventCount: s.DroppedEventsCount,
+		DroppedLinkCount:         s.DroppedLinksCount,
+	}
+	if err := decodeID(s.TraceID, snapshot.SpanContext.TraceID[:]); err != nil {
+		return nil, fmt.Errorf("span %q has invalid trace ID: %v", s.Name, err)
+	}
+	if err := decodeID(s.SpanID, snapshot.SpanContext.SpanID[:]); err != nil {
+		return nil, fmt.Errorf("span %q has invalid span ID: %v", s.Name, err)
+	}
+	if err := decodeID(s.ParentSpanID, snapshot.ParentSpanID[:]); err != nil {
+		return nil, fmt.Errorf("span %q has invalid parent span ID: %v", s.Name, err)
+	}
+	var err error
+	if snapshot.StartTime, err = unixNanoTime(s.StartTimeUnixNano); err != nil {
+		return nil, fmt.Errorf("span %q has invalid start time: %v", s.Name, err)
+	}
+	if snapshot.EndTime, err = unixNanoTime(s.EndTimeUnixNano); err != nil {
+		return nil, fmt.Errorf("span %q has invalid end time: %v", s.Name, err)
+	}
+	kind, err := enumValue(s.Kind, "SPAN_KIND_")
+	if err != nil {
+		return nil, fmt.Errorf("span %q has invalid kind: %v", s.Name, err)
+	}
+	snapshot.SpanKind = spanKind(kind)
+	code, err := enumValue(s.Status.Code, "STATUS_CODE_")
+	if err != nil {
+		return nil, fmt.Errorf("span %q has invalid status code: %v", s.Name, err)
+	}
+	snapshot.StatusCode = statusCode(code)
+
+	for _, e := range s.Events {
+		t, err := unixNanoTime(e.TimeUnixNano)
+		if err != nil {
+			return nil, fmt.Errorf("span %q has event %q with invalid time: %v", s.Name, e.Name, err)
+		}
+		snapshot.MessageEvents = append(snapshot.MessageEvents, exporttrace.Event{
+			Name:       e.Name,
+			Attributes: convertAttributes(e.Attributes),
+			Time:       t,
+		})
+	}
+	for _, l := range s.Links {
+		var link apitrace.Link
+		if err := decodeID(l.TraceID, link.SpanContext.TraceID[:]); err != nil {
+			return nil, fmt.Errorf("span %q has link with invalid trace ID: %v", s.Name, err)
+		}
+		if err := decodeID(l.SpanID, link.SpanContext.SpanID[:]); err != nil {
+			return nil, fmt.Errorf("span %q has link with invalid span ID: %v", s.Name, err)
+		}
+		link.Attributes = convertAttributes(l.Attributes)
+		snapshot.Links = append(snapshot.Links, link)
+	}
+	return snapshot, nil
+}
+
+// decodeID fills dst from an ID encoded either as hex (the OTLP/JSON
+// convention) or as base64 (the generic protobuf JSON mapping). An empty
+// string leaves dst zeroed.
+func decodeID(s string, dst []byte) error {
+	if len(s) == 0 {
+		return nil
+	}
+	var id []byte
+	var err error
+	if len(s) == 2*len(dst) {
+		id, err = hex.DecodeString(s)
+	} else {
+		id, err = base64.StdEncoding.DecodeString(s)
+	}
+	if err != nil {
+		return err
+	}
+	if len(id) != len(dst) {
+		return fmt.Errorf("expected %d bytes, got %d", len(dst), len(id))
+	}
+	copy(dst, id)
+	return nil
+}
+
+func unixNanoTime(n json.Number) (time.Time, error) {
+	if len(n) == 0 {
+		return time.Time{}, nil
+	}
+	nanos, err := strconv.ParseInt(string(n), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, nanos), nil
+}
+
+// enumValue decodes a protobuf enum encoded either as a number or as its
+// symbolic name, returning the numeric value.
+func enumValue(raw json.RawMessage, prefix string) (int, error) {
+	if len(raw) == 0 {
+		return 0, nil
+	}
+	var n int
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n, nil
+	}
+	var name string
+	if err := json.Unmarshal(raw, &name); err != nil {
+		return 0, err
+	}
+	names := map[string]int{
+		"SPAN_KIND_UNSPECIFIED": 0,
+		"SPAN_KIND_INTERNAL":    1,
+		"SPAN_KIND_SERVER":      2,
+		"SPAN_KIND_CLIENT":      3,
+		"SPAN_KIND_PRODUCER":    4,
+		"SPAN_KIND_CONSUMER":    5,
+		"STATUS_CODE_UNSET":     0,
+		"STATUS_CODE_OK":        1,
+		"STATUS_CODE_ERROR":     2,
+	}
+	if !strings.HasPrefix(name, prefix) {
+		return 0, fmt.Errorf("unknown value %q", name)
+	}
+	n, ok := names[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown value %q", name)
+	}
+	return n, nil
+}
+
+func spanKind(kind int) apitrace.SpanKind {
+	switch kind {
+	case 1:
+		return apitrace.SpanKindInternal
+	case 2:
+		return apitrace.SpanKindServer
+	case 3:
+		return apitrace.SpanKindClient
+	case 4:
+		return apitrace.SpanKindProducer
+	case 5:
+		return apitrace.SpanKindConsumer
+	default:
+		return apitrace.SpanKindUnspecified
+	}
+}
+
+// statusCode maps OTLP status codes onto the SDK's, whose numbering differs.
+func statusCode(code int) codes.Code {
+	switch code {
+	case 1:
+		return codes.Ok
+	case 2:
+		return codes.Error
+	default:
+		return codes.Unset
+	}
+}
+
+func convertAttributes(kvs []otlpKeyValue) []label.KeyValue {
+	if len(kvs) == 0 {
+		return nil
+	}
+	attrs := make([]label.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		v := kv.Value
+		switch {
+		case v.StringValue != nil:
+			attrs = append(attrs, label.String(kv.Key, *v.StringValue))
+		case v.BoolValue != nil:
+			attrs = append(attrs, label.Bool(kv.Key, *v.BoolValue))
+		case v.IntValue != nil:
+			if i, err := v.IntValue.Int64(); err == nil {
+				attrs = append(attrs, label.Int64(kv.Key, i))
+			}
+		case v.DoubleValue != nil:
+			if f, err := v.DoubleValue.Float64(); err == nil {
+				attrs = append(attrs, label.Float64(kv.Key, f))
+			}
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,108 @@
+package otlpjson
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+const otlpDocument = `{
+  "resourceSpans": [{
+    "resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "checkout"}}]},
+    "scopeSpans": [{
+      "spans": [{
+        "traceId": "0102030405060708090a0b0c0d0e0f10",
+        "spanId": "0102030405060708",
+        "parentSpanId": "",
+        "name": "/cart",
+        "kind": "SPAN_KIND_SERVER",
+        "startTimeUnixNano": "1600000000000000000",
+        "endTimeUnixNano": "1600000000500000000",
+        "attributes": [
+          {"key": "http.status_code", "value": {"intValue": "500"}},
+          {"key": "retried", "value": {"boolValue": true}}
+        ],
+        "events": [{"timeUnixNano": "1600000000100000000", "name": "exception"}],
+        "status": {"code": 2, "message": "boom"}
+      }]
+    }]
+  }]
+}`
+
+func TestReadJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	snapshots, err := ReadJSON(strings.NewReader(otlpDocument))
+	assert.Nil(err)
+	assert.Len(snapshots, 1)
+
+	s := snapshots[0]
+	assert.Equal("0102030405060708090a0b0c0d0e0f10", s.SpanContext.TraceID.String())
+	assert.Equal("0102030405060708", s.SpanContext.SpanID.String())
+	assert.False(s.ParentSpanID.IsValid())
+	assert.Equal("/cart", s.Name)
+	assert.Equal(apitrace.SpanKindServer, s.SpanKind)
+	assert.Equal(500*time.Millisecond, s.EndTime.Sub(s.StartTime))
+	assert.Equal(codes.Error, s.StatusCode)
+	assert.Equal("boom", s.StatusMessage)
+	assert.Equal([]label.KeyValue{
+		label.Int64("http.status_code", 500),
+		label.Bool("retried", true),
+	}, s.Attributes)
+	assert.Len(s.MessageEvents, 1)
+	assert.Equal("exception", s.MessageEvents[0].Name)
+
+	serviceName, ok := s.Resource.LabelSet().Value("service.name")
+	assert.True(ok)
+	assert.Equal("checkout", serviceName.AsString())
+}
+
+func TestReadJSONLines(t *testing.T) {
+	assert := assert.New(t)
+
+	line := strings.Join(strings.Fields(otlpDocument), "")
+	snapshots, err := ReadJSONLines(strings.NewReader(line + "\n\n" + line + "\n"))
+	assert.Nil(err)
+	assert.Len(snapshots, 2)
+
+	_, err = ReadJSONLines(strings.NewReader(line + "\n{not json}\n"))
+	assert.Error(err)
+	assert.Contains(err.Error(), "line 2")
+}
+
+func TestReadJSONRejectsMalformedIDs(t *testing.T) {
+	doc := strings.Replace(otlpDocument, "0102030405060708090a0b0c0d0e0f10", "0102", 1)
+	_, err := ReadJSON(strings.NewReader(doc))
+	assert.Error(t, err)
+}
+
+func TestReadJSONSchemaURL(t *testing.T) {
+	assert := assert.New(t)
+
+	doc := `{"resourceSpans": [{
+	  "schemaUrl": "https://opentelemetry.io/schemas/1.9.0",
+	  "scopeSpans": [
+	    {"spans": [{"name": "a"}]},
+	    {"schemaUrl": "https://opentelemetry.io/schemas/1.21.0", "spans": [{"name": "b"}]}
+	  ]
+	}]}`
+	snapshots, err := ReadJSON(strings.NewReader(doc))
+	assert.Nil(err)
+	assert.Len(snapshots, 2)
+	for i, want := range []string{"https://opentelemetry.io/schemas/1.9.0", "https://opentelemetry.io/schemas/1.21.0"} {
+		url, ok := snapshots[i].Resource.LabelSet().Value(schemaURLKey)
+		assert.True(ok)
+		assert.Equal(want, url.AsString())
+	}
+
+	snapshots, err = ReadJSON(strings.NewReader(otlpDocument))
+	assert.Nil(err)
+	_, ok := snapshots[0].Resource.LabelSet().Value(schemaURLKey)
+	assert.False(ok)
+}